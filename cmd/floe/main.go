@@ -83,7 +83,15 @@ func start(sc srvConf, conf []byte, addr chan string) error {
 	}
 	// TODO - implement other stores e.g. s3
 
-	q := &event.Queue{}
+	compaction, err := event.FlowCompaction(c.Flows)
+	if err != nil {
+		return err
+	}
+	rules, err := event.ConfigRules(c.Rules)
+	if err != nil {
+		return err
+	}
+	q := event.NewQueue(compaction, event.WithRedaction(event.ConfigMeta{Config: c}), event.WithRules(rules...))
 	hub := hub.New(sc.HostName, sc.Tags, sc.AdminToken, c, s, q)
 	server.AdminToken = sc.AdminToken
 
@@ -14,6 +14,8 @@ type Config struct {
 	Common commonConfig
 	// the list of flow configurations
 	Flows []*Flow
+	// Rules change events as they are published
+	Rules []Rule
 }
 
 // Defaults ensures some sensible defaults have been set up
@@ -62,7 +64,10 @@ type FoundFlow struct {
 	*Flow
 }
 
-// FindFlowsByTriggers finds all flows where its subs match the given params
+// FindFlowsByTriggers finds all flows where its subs match the given params.
+// A flow is only ever found once, if more than one of its triggers match then the
+// first matching trigger in config order is used, so a single event starts at most
+// one run per flow.
 func (c *Config) FindFlowsByTriggers(triggerType string, flow FlowRef, opts nt.Opts) map[FlowRef]FoundFlow {
 	res := map[FlowRef]FoundFlow{}
 	for _, f := range c.Flows {
@@ -33,6 +33,16 @@ func TestZeroNID(t *testing.T) {
 			shouldErr: true,
 			help:      "no space allowed in id",
 		},
+		{
+			idIn:      "a:b",
+			shouldErr: true,
+			help:      "only tag characters allowed in id",
+		},
+		{
+			nameIn:    "build (all)",
+			shouldErr: true,
+			help:      "only tag characters allowed in id from name",
+		},
 		{
 			idIn:      "a-b",
 			idOut:     "a-b",
@@ -40,6 +50,13 @@ func TestZeroNID(t *testing.T) {
 			shouldErr: false,
 			help:      "name from good id",
 		},
+		{
+			idIn:      "build_all",
+			idOut:     "build_all",
+			nameOut:   "build_all",
+			shouldErr: false,
+			help:      "underscores allowed in id",
+		},
 		{
 			nameIn:    "a b.2",
 			nameOut:   "a b.2",
@@ -52,6 +52,8 @@ type Flow struct {
 	HostTags     []string `yaml:"host-tags"`     // tags that must match the tags on the host
 	ResourceTags []string `yaml:"resource-tags"` // tags that if any flow is running with any matching tags then don't launch
 	Env          []string // key=value environment variables with
+	History      string   // which events of a run are retained: all (the default), milestones, or last-N
+	Timeout      int      // seconds a run can take before it is failed, 0 for no limit
 
 	// Triggers are the node types that define how a run is triggered for this flow.
 	Triggers []*node
@@ -217,6 +219,16 @@ func (f *Flow) matchTriggers(eType string, opts *nt.Opts) []*node {
 	return res
 }
 
+// MatchTriggers returns the refs of the triggers of f, in config order, that an event of
+// triggerType with opts would fire.
+func (f *Flow) MatchTriggers(triggerType string, opts nt.Opts) []NodeRef {
+	var refs []NodeRef
+	for _, n := range f.matchTriggers(triggerType, &opts) {
+		refs = append(refs, n.NodeRef())
+	}
+	return refs
+}
+
 // methods that implement nid so the flow can be zeroNid'd
 func (f *Flow) setName(n string) {
 	f.Name = n
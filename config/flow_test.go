@@ -64,6 +64,12 @@ func TestMatchTag(t *testing.T) {
 	if len(matches) != 1 {
 		t.Error("did not find merge node")
 	}
+
+	// tags are not case sensitive
+	matches = flow.MatchTag("Trigger.GOOD")
+	if len(matches) != 1 {
+		t.Error("did not find task node ignoring case")
+	}
 }
 
 func TestGetURLType(t *testing.T) {
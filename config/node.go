@@ -14,6 +14,8 @@ const (
 	SubTagGood = "good"
 	// SubTagBad the tag to be used on events who's result is bad
 	SubTagBad = "bad"
+	// SubTagError the tag to be used on events of nodes that could not be executed
+	SubTagError = "error"
 )
 
 // NodeClass the type def for the classes a Node can be
@@ -50,11 +52,14 @@ type node struct {
 	IgnoreFail bool  `yaml:"ignore-fail"` // only ever send the good event cant be used in conjunction with UseStatus
 	// use status if we don't send good or bad but the actual status code as an event
 	// TODO - consider if mapping status codes to good and bad is all the complexity we need
-	UseStatus bool    `yaml:"use-status"`
-	Opts      nt.Opts // static config options
+	UseStatus bool `yaml:"use-status"`
+	// OutcomeTag is a template for the tags of the events the node ends with, instead of
+	// class.id.outcome, see OutcomeTag
+	OutcomeTag string  `yaml:"outcome-tag"`
+	Opts       nt.Opts // static config options
 }
 
-func (t *node) Execute(ws *nt.Workspace, opts nt.Opts, output chan string) (int, nt.Opts, error) {
+func (t *node) Execute(ws *nt.Workspace, opts nt.Opts, output chan nt.Output) (int, nt.Opts, error) {
 	n := nt.GetNodeType(t.Type)
 	if n == nil {
 		return 255, nil, fmt.Errorf("no node type found: %s", t.Type)
@@ -115,8 +120,28 @@ func (t *node) Waits() int {
 	return len(t.Wait)
 }
 
+// GetTag returns the tag of the event the node issues for the sub tag, in lower case as
+// all tags are, using the outcome tag template of the node if it has one
 func (t *node) GetTag(subTag string) string {
-	return fmt.Sprintf("%s.%s.%s", t.Class, t.Ref.ID, subTag)
+	return OutcomeTag(t.OutcomeTag, t.Ref, subTag)
+}
+
+// OutcomeTag returns the tag of the event a node ends with for the outcome, such as good,
+// bad, error or an exit status. A non empty tmpl is a text/template given the class, id and
+// outcome, for example "deploy.prod.{{.outcome}}". Without a template, or if it fails, the
+// tag is class.id.outcome. Tags are always lower case.
+func OutcomeTag(tmpl string, ref NodeRef, outcome string) string {
+	if tmpl != "" {
+		tag, err := outcomeOpts(ref, outcome).Resolve(tmpl)
+		if err == nil && tag != "" {
+			return strings.ToLower(tag)
+		}
+	}
+	return strings.ToLower(fmt.Sprintf("%s.%s.%s", ref.Class, ref.ID, outcome))
+}
+
+func outcomeOpts(ref NodeRef, outcome string) nt.Opts {
+	return nt.Opts{"class": string(ref.Class), "id": ref.ID, "outcome": outcome}
 }
 
 func (t *node) matchedTriggers(eType string, opts *nt.Opts) bool {
@@ -137,13 +162,13 @@ func (t *node) matchedTriggers(eType string, opts *nt.Opts) bool {
 }
 
 func (t *node) matched(tag string) bool {
-	// match on the Listen
-	if t.Listen != "" && t.Listen == tag {
+	// match on the Listen, tags are not case sensitive
+	if t.Listen != "" && strings.EqualFold(t.Listen, tag) {
 		return true
 	}
 	// or if any tags in the the Wait list match (merge nodes only)
 	for _, wt := range t.Wait {
-		if wt == tag {
+		if strings.EqualFold(wt, tag) {
 			return true
 		}
 	}
@@ -177,6 +202,12 @@ func (t *node) zero(defaultClass NodeClass, flow FlowRef) error {
 		ID:    t.ID,
 	}
 
+	if t.OutcomeTag != "" {
+		if _, err := outcomeOpts(t.Ref, SubTagGood).Resolve(t.OutcomeTag); err != nil {
+			return fmt.Errorf("bad outcome-tag - %v", err)
+		}
+	}
+
 	// node specific checks
 	switch t.Class {
 	case NcTask:
@@ -222,8 +253,8 @@ func zeroNID(n nid) error {
 	if id == "" {
 		id = idFromName(name)
 	}
-	if strings.ContainsAny(id, " .") {
-		return errors.New("a specified id can not contain spaces or full stops")
+	if !validID(id) {
+		return errors.New("an id can only have letters, digits, dashes and underscores")
 	}
 	if name == "" {
 		name = nameFromID(id)
@@ -234,6 +265,19 @@ func zeroNID(n nid) error {
 	return nil
 }
 
+// validID returns true if id only has the characters valid in an event tag, other than the
+// dots that separate the parts of a tag
+func validID(id string) bool {
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // trim trailing spaces and dots and hyphens
 func trimNIDs(s string) string {
 	return strings.Trim(s, " .-")
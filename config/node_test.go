@@ -7,12 +7,12 @@ import (
 )
 
 func TestNodeExec(t *testing.T) {
-	output := make(chan string)
+	output := make(chan nt.Output)
 	captured := make(chan bool)
 	cl := 0
 	go func() {
 		for l := range output {
-			println(l)
+			println(l.Line)
 			cl++
 		}
 		captured <- true
@@ -37,3 +37,37 @@ func TestNodeExec(t *testing.T) {
 	close(output)
 	<-captured
 }
+
+func TestOutcomeTag(t *testing.T) {
+	n := &node{
+		Name:       "Deploy",
+		OutcomeTag: `deploy.prod.{{if eq .outcome "good"}}success{{else}}failure{{end}}`,
+	}
+	if err := n.zero(NcTask, FlowRef{ID: "flow", Ver: 1}); err != nil {
+		t.Fatal(err)
+	}
+	fix := []struct {
+		status int
+		tag    string
+	}{
+		{0, "deploy.prod.success"},
+		{1, "deploy.prod.failure"},
+	}
+	for i, f := range fix {
+		sub, _ := n.Status(f.status)
+		if tag := n.GetTag(sub); tag != f.tag {
+			t.Errorf("%d - wanted %s got %s", i, f.tag, tag)
+		}
+	}
+
+	// without a template the default tag is used
+	n.OutcomeTag = ""
+	if tag := n.GetTag(SubTagGood); tag != "task.deploy.good" {
+		t.Error("bad default tag", tag)
+	}
+
+	bad := &node{Name: "Deploy", OutcomeTag: "{{.outcome"}
+	if err := bad.zero(NcTask, FlowRef{ID: "flow", Ver: 1}); err == nil {
+		t.Error("a bad outcome-tag template should fail to load")
+	}
+}
@@ -30,7 +30,7 @@ type field struct {
 // Execute on data nodes fill in the opts, validate the form, and decide if the node can be considered
 // good or bad.
 // returns status 0 = form requirements met, 1 = an error (error will be set), 2 = needs more data
-func (d data) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, error) {
+func (d data) Execute(ws *Workspace, in Opts, output chan Output) (int, Opts, error) {
 	do := dataOpts{}
 
 	err := mapstructure.Decode(in, &do)
@@ -51,7 +51,7 @@ func (e exec) cmdAndArgs() (cmd string, args []string) {
 	return cmd, args
 }
 
-func (e exec) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, error) {
+func (e exec) Execute(ws *Workspace, in Opts, output chan Output) (int, Opts, error) {
 	err := decode(in, &e)
 	if err != nil {
 		return 255, nil, err
@@ -80,25 +80,28 @@ func (e exec) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, er
 	return status, Opts{}, nil
 }
 
-func doRun(dir string, env []string, output chan string, cmd string, args ...string) int {
+func doRun(dir string, env []string, output chan Output, cmd string, args ...string) int {
 	stop := make(chan bool)
-	out := make(chan string)
+	out, errOut := make(chan string), make(chan string)
 
-	output <- "in dir: " + dir + "\n"
-	go func() {
-		for o := range out {
-			output <- o
+	output <- Output{Line: "in dir: " + dir + "\n"}
+	forward := func(lines chan string, stream string) {
+		for l := range lines {
+			output <- Output{Stream: stream, Line: l}
 		}
 		stop <- true
-	}()
+	}
+	go forward(out, "")
+	go forward(errOut, StreamStderr)
 
-	status := exe.Run(log.Log{}, out, env, dir, cmd, args...)
+	status := exe.RunStreams(log.Log{}, out, errOut, env, dir, cmd, args...)
 
 	// wait for output to complete
 	<-stop
+	<-stop
 
 	if status != 0 {
-		output <- fmt.Sprintf("\nexited with status: %d", status)
+		output <- Output{Line: fmt.Sprintf("\nexited with status: %d", status)}
 	}
 
 	return status
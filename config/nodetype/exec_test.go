@@ -8,10 +8,10 @@ import (
 
 func TestExec(t *testing.T) {
 	e := exec{}
-	op := make(chan string)
+	op := make(chan Output)
 	go func() {
 		for l := range op {
-			println(l)
+			println(l.Line)
 		}
 	}()
 
@@ -59,12 +59,12 @@ func TestEnvVars(t *testing.T) {
 }
 
 func testNode(t *testing.T, msg string, nt NodeType, opts Opts, expected []string) bool {
-	op := make(chan string)
+	op := make(chan Output)
 	var out []string
 	captured := make(chan bool)
 	go func() {
 		for l := range op {
-			out = append(out, l)
+			out = append(out, l.Line)
 		}
 		captured <- true
 	}()
@@ -29,7 +29,7 @@ func (g fetch) Match(ol, or Opts) bool {
 	return true
 }
 
-func (g fetch) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, error) {
+func (g fetch) Execute(ws *Workspace, in Opts, output chan Output) (int, Opts, error) {
 
 	fop := fetchOpts{}
 	err := decode(in, &fop)
@@ -41,13 +41,13 @@ func (g fetch) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, e
 		return 255, nil, fmt.Errorf("problem getting fetch url option")
 	}
 	if fop.Checksum == "" {
-		output <- "(N.B. fetch without a checksum can not be trusted)"
+		output <- Output{Line: "(N.B. fetch without a checksum can not be trusted)"}
 	}
 
 	client := grab.NewClient()
 	req, err := grab.NewRequest(ws.FetchCache, fop.URL)
 	if err != nil {
-		output <- fmt.Sprintf("Error setting up the download %v", err)
+		output <- Output{Line: fmt.Sprintf("Error setting up the download %v", err)}
 		return 255, nil, err
 	}
 
@@ -60,7 +60,7 @@ func (g fetch) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, e
 		}
 		checksum, err := hex.DecodeString(fop.Checksum)
 		if err != nil {
-			output <- fmt.Sprintf("Error decoding hex checksum: %s", fop.Checksum)
+			output <- Output{Line: fmt.Sprintf("Error decoding hex checksum: %s", fop.Checksum)}
 			return 255, nil, err
 		}
 
@@ -78,9 +78,9 @@ func (g fetch) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, e
 
 	started := time.Now()
 	// start download
-	output <- fmt.Sprintf("Downloading %v...", req.URL())
+	output <- Output{Line: fmt.Sprintf("Downloading %v...", req.URL())}
 	resp := client.Do(req)
-	output <- fmt.Sprintf("  %v", resp.HTTPResponse.Status)
+	output <- Output{Line: fmt.Sprintf("  %v", resp.HTTPResponse.Status)}
 
 	// start UI loop
 	t := time.NewTicker(300 * time.Millisecond)
@@ -90,18 +90,18 @@ Loop:
 	for {
 		select {
 		case <-t.C:
-			output <- fmt.Sprintf("  %v / %v bytes (%.2f%%)", resp.BytesComplete(), resp.Size, 100*resp.Progress())
+			output <- Output{Line: fmt.Sprintf("  %v / %v bytes (%.2f%%)", resp.BytesComplete(), resp.Size, 100*resp.Progress())}
 		case <-resp.Done:
 			break Loop
 		}
 	}
 	// check for errors, emit it and bail
 	if err := resp.Err(); err != nil {
-		output <- fmt.Sprintf("Download failed: %v", err)
+		output <- Output{Line: fmt.Sprintf("Download failed: %v", err)}
 		return 255, nil, err
 	}
-	output <- fmt.Sprintf("  %v / %v bytes (%.2f%%) in %v", resp.BytesComplete(), resp.Size, 100*resp.Progress(), time.Since(started))
-	output <- fmt.Sprintf("Download saved to %v", resp.Filename)
+	output <- Output{Line: fmt.Sprintf("  %v / %v bytes (%.2f%%) in %v", resp.BytesComplete(), resp.Size, 100*resp.Progress(), time.Since(started))}
+	output <- Output{Line: fmt.Sprintf("Download saved to %v", resp.Filename)}
 
 	// if no location was given to link it to then link it to the root of the workspace
 	// this will be used to link to the file in the cache
@@ -120,7 +120,7 @@ Loop:
 	if err != nil {
 		return 255, nil, err
 	}
-	output <- fmt.Sprintf("Download linked to %v", fop.Location)
+	output <- Output{Line: fmt.Sprintf("Download linked to %v", fop.Location)}
 
 	return 0, nil, nil
 }
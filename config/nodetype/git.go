@@ -24,7 +24,7 @@ func (g gitMerge) Match(ol, or Opts) bool {
 	return true
 }
 
-func (g gitMerge) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, error) {
+func (g gitMerge) Execute(ws *Workspace, in Opts, output chan Output) (int, Opts, error) {
 
 	gop := gitOpts{}
 	err := decode(in, &gop)
@@ -42,7 +42,7 @@ func (g gitMerge) Execute(ws *Workspace, in Opts, output chan string) (int, Opts
 		return 255, nil, fmt.Errorf("problem getting from ref option")
 	}
 
-	output <- "git checkout: " + gop.URL + " merge into: " + gop.Branch + " from: " + gop.FromBranch
+	output <- Output{Line: "git checkout: " + gop.URL + " merge into: " + gop.Branch + " from: " + gop.FromBranch}
 
 	log.Debug("GIT merge ", gop.URL, " merge into: ", gop.Branch, " from: ", gop.FromBranch)
 	return 0, nil, nil
@@ -55,7 +55,7 @@ func (g gitCheckout) Match(ol, or Opts) bool {
 	return true
 }
 
-func (g gitCheckout) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, error) {
+func (g gitCheckout) Execute(ws *Workspace, in Opts, output chan Output) (int, Opts, error) {
 	gop := gitOpts{}
 	err := decode(in, &gop)
 	if err != nil {
@@ -72,9 +72,9 @@ func (g gitCheckout) Execute(ws *Workspace, in Opts, output chan string) (int, O
 
 	// for testing
 	if gop.URL == "git@github.com:floeit/floe-test.git" {
-		output <- "in dir: /Users/Dan/.flow/spaces/danmux/ws/h1-12/src/github.com/floeit"
-		output <- "git clone --branch master --depth 1 git@github.com:floeit/floe-test.git"
-		output <- "Cloning into 'floe'..."
+		output <- Output{Line: "in dir: /Users/Dan/.flow/spaces/danmux/ws/h1-12/src/github.com/floeit"}
+		output <- Output{Line: "git clone --branch master --depth 1 git@github.com:floeit/floe-test.git"}
+		output <- Output{Line: "Cloning into 'floe'..."}
 		return 0, nil, nil
 	}
 	var env []string
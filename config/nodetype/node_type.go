@@ -20,7 +20,30 @@ const (
 // THe Execute method must be a pure(ish) function operating on in and returning an out Opts
 type NodeType interface {
 	Match(Opts, Opts) bool
-	Execute(ws *Workspace, in Opts, output chan string) (int, Opts, error)
+	Execute(ws *Workspace, in Opts, output chan Output) (int, Opts, error)
+}
+
+// StreamStderr is the Stream of output lines a command wrote to stderr
+const StreamStderr = "stderr"
+
+// Output is a line of output from executing a node
+type Output struct {
+	Stream string // the stream the line was written to, empty for stdout
+	Line   string
+}
+
+// Sensitive is implemented by node types with opts holding secrets, such as passwords or
+// tokens, that must not be logged or sent to untrusted observers
+type Sensitive interface {
+	SensitiveOpts() []string // the keys of the secret opts
+}
+
+// SensitiveOpts returns the keys of the opts of the node type n that hold secrets
+func SensitiveOpts(n NodeType) []string {
+	if s, ok := n.(Sensitive); ok {
+		return s.SensitiveOpts()
+	}
+	return nil
 }
 
 var nts = map[NType]NodeType{
@@ -1,5 +1,12 @@
 package nodetype
 
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
 // Workspace is anything specific to a workspace for a single run or any locations common between runs
 type Workspace struct {
 	BasePath   string // The root path for this workspace
@@ -114,3 +121,186 @@ func yamlToJSON(in interface{}) interface{} {
 
 	return in
 }
+
+// noValue is what text/template renders for a missing key in a map of interfaces
+const noValue = "<no value>"
+
+// Resolve executes the text/template tmpl with the opts as the data, so that for example
+// "{{.commit.id}}" is replaced by the id in the nested commit map. Any key that is missing
+// is an error.
+func (o Opts) Resolve(tmpl string) (string, error) {
+	return o.resolve(tmpl, "missingkey=error")
+}
+
+// ResolveEmpty is like Resolve but renders any missing keys as an empty string
+func (o Opts) ResolveEmpty(tmpl string) (string, error) {
+	s, err := o.resolve(tmpl, "missingkey=zero")
+	return strings.Replace(s, noValue, "", -1), err
+}
+
+func (o Opts) resolve(tmpl, missing string) (string, error) {
+	t, err := template.New("opts").Option(missing).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	b := &bytes.Buffer{}
+	if err := t.Execute(b, map[string]interface{}(o)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Diff returns the opts in o that were not in prev, those in both with a different value,
+// and those in prev no longer in o. Nested maps are compared key by key, so only the
+// nested keys that differ appear, under their parent key.
+func (o Opts) Diff(prev Opts) (added, changed, removed Opts) {
+	added, changed, removed = Opts{}, Opts{}, Opts{}
+	for k, v := range o {
+		pv, ok := prev[k]
+		if !ok {
+			added[k] = v
+			continue
+		}
+		m, mok := asMap(v)
+		pm, pok := asMap(pv)
+		if mok && pok {
+			a, c, r := m.Diff(pm)
+			addIfAny(added, k, a)
+			addIfAny(changed, k, c)
+			addIfAny(removed, k, r)
+			continue
+		}
+		if !reflect.DeepEqual(v, pv) {
+			changed[k] = v
+		}
+	}
+	for k, v := range prev {
+		if _, ok := o[k]; !ok {
+			removed[k] = v
+		}
+	}
+	return added, changed, removed
+}
+
+func asMap(v interface{}) (Opts, bool) {
+	switch m := v.(type) {
+	case Opts:
+		return m, true
+	case map[string]interface{}:
+		return m, true
+	}
+	return nil, false
+}
+
+func addIfAny(o Opts, k string, sub Opts) {
+	if len(sub) > 0 {
+		o[k] = sub
+	}
+}
+
+// EqualSemantic returns true if o and other hold the same values once decoded by the
+// event codec, so numbers are compared by value whatever their type, as JSON decodes them
+// all as float64, and any map or slice types are compared element by element.
+// Values of other types are only equal if they are of the same comparable type.
+func (o Opts) EqualSemantic(other Opts) bool {
+	if len(o) != len(other) {
+		return false
+	}
+	for k, v := range o {
+		ov, ok := other[k]
+		if !ok || !equalSemantic(v, ov) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalSemantic(a, b interface{}) bool {
+	if fa, ok := asFloat(a); ok {
+		fb, ok := asFloat(b)
+		return ok && fa == fb
+	}
+	if ma, ok := asMap(a); ok {
+		mb, ok := asMap(b)
+		return ok && ma.EqualSemantic(mb)
+	}
+	if sa, ok := asSlice(a); ok {
+		sb, ok := asSlice(b)
+		if !ok || len(sa) != len(sb) {
+			return false
+		}
+		for i := range sa {
+			if !equalSemantic(sa[i], sb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	switch va := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		vb, ok := b.(string)
+		return ok && va == vb
+	case bool:
+		vb, ok := b.(bool)
+		return ok && va == vb
+	}
+	return false
+}
+
+func asFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func asSlice(v interface{}) ([]interface{}, bool) {
+	switch s := v.(type) {
+	case []interface{}:
+		return s, true
+	case []string:
+		is := make([]interface{}, len(s))
+		for i, e := range s {
+			is[i] = e
+		}
+		return is, true
+	case []int:
+		is := make([]interface{}, len(s))
+		for i, e := range s {
+			is[i] = e
+		}
+		return is, true
+	case []float64:
+		is := make([]interface{}, len(s))
+		for i, e := range s {
+			is[i] = e
+		}
+		return is, true
+	}
+	return nil, false
+}
@@ -1,6 +1,7 @@
 package nodetype
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -52,3 +53,125 @@ func TestMergeOpts(t *testing.T) {
 		t.Fatal("no env when it did not exist")
 	}
 }
+
+func TestResolve(t *testing.T) {
+	o := Opts{
+		"branch": "master",
+		"commit": map[string]interface{}{
+			"id":     "abc123",
+			"author": Opts{"name": "jo"},
+		},
+	}
+	fix := []struct {
+		tmpl     string
+		exp      string
+		expEmpty string
+		err      bool
+	}{
+		{tmpl: "no template", exp: "no template", expEmpty: "no template"},
+		{tmpl: "build {{.branch}}", exp: "build master", expEmpty: "build master"},
+		{tmpl: "{{.commit.id}} by {{.commit.author.name}}", exp: "abc123 by jo", expEmpty: "abc123 by jo"},
+		{tmpl: "tag {{.tag}}", expEmpty: "tag ", err: true},
+		{tmpl: "sha {{.commit.sha}}", expEmpty: "sha ", err: true},
+	}
+	for i, f := range fix {
+		s, err := o.Resolve(f.tmpl)
+		if (err != nil) != f.err {
+			t.Errorf("%d - error should be %v got %v", i, f.err, err)
+		}
+		if s != f.exp {
+			t.Errorf("%d - got <%s> wanted <%s>", i, s, f.exp)
+		}
+		s, err = o.ResolveEmpty(f.tmpl)
+		if err != nil {
+			t.Errorf("%d - empty missing keys should not error %v", i, err)
+		}
+		if s != f.expEmpty {
+			t.Errorf("%d - empty got <%s> wanted <%s>", i, s, f.expEmpty)
+		}
+	}
+
+	if _, err := o.Resolve("{{.branch"); err == nil {
+		t.Error("bad template should error")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	prev := Opts{
+		"same":    "a",
+		"changed": 1,
+		"gone":    true,
+		"list":    []interface{}{"x"},
+		"commit": map[string]interface{}{
+			"id":     "abc",
+			"msg":    "fix",
+			"branch": "master",
+		},
+	}
+	cur := Opts{
+		"same":    "a",
+		"changed": 2,
+		"new":     "n",
+		"list":    []interface{}{"x", "y"},
+		"commit": map[string]interface{}{
+			"id":     "def",
+			"msg":    "fix",
+			"author": "jo",
+		},
+	}
+	added, changed, removed := cur.Diff(prev)
+
+	expAdded := Opts{"new": "n", "commit": Opts{"author": "jo"}}
+	expChanged := Opts{"changed": 2, "list": []interface{}{"x", "y"}, "commit": Opts{"id": "def"}}
+	expRemoved := Opts{"gone": true, "commit": Opts{"branch": "master"}}
+	if !reflect.DeepEqual(added, expAdded) {
+		t.Errorf("added wrong %v", added)
+	}
+	if !reflect.DeepEqual(changed, expChanged) {
+		t.Errorf("changed wrong %v", changed)
+	}
+	if !reflect.DeepEqual(removed, expRemoved) {
+		t.Errorf("removed wrong %v", removed)
+	}
+
+	added, changed, removed = cur.Diff(cur)
+	if len(added)+len(changed)+len(removed) != 0 {
+		t.Error("no differences expected", added, changed, removed)
+	}
+}
+
+func TestEqualSemantic(t *testing.T) {
+	fix := []struct {
+		a, b Opts
+		want bool
+	}{
+		{a: Opts{}, b: Opts{}, want: true},
+		{a: Opts{"n": 1}, b: Opts{"n": float64(1)}, want: true},
+		{a: Opts{"n": int64(3)}, b: Opts{"n": uint8(3)}, want: true},
+		{a: Opts{"n": 1}, b: Opts{"n": 1.5}, want: false},
+		{a: Opts{"n": 1}, b: Opts{"n": "1"}, want: false},
+		{a: Opts{"s": "x", "b": true, "z": nil}, b: Opts{"s": "x", "b": true, "z": nil}, want: true},
+		{a: Opts{"b": true}, b: Opts{"b": false}, want: false},
+		{a: Opts{"a": 1}, b: Opts{"b": 1}, want: false},
+		{a: Opts{"a": 1}, b: Opts{"a": 1, "b": 2}, want: false},
+		{a: Opts{"l": []int{1, 2}}, b: Opts{"l": []interface{}{1.0, 2.0}}, want: true},
+		{a: Opts{"l": []string{"a", "b"}}, b: Opts{"l": []interface{}{"a", "b"}}, want: true},
+		{a: Opts{"l": []string{"a", "b"}}, b: Opts{"l": []interface{}{"b", "a"}}, want: false},
+		{a: Opts{"l": []int{1}}, b: Opts{"l": []interface{}{1.0, 2.0}}, want: false},
+		{
+			a:    Opts{"m": Opts{"n": 2, "l": []int{3}}},
+			b:    Opts{"m": map[string]interface{}{"n": 2.0, "l": []interface{}{3.0}}},
+			want: true,
+		},
+		{a: Opts{"m": Opts{"n": 2}}, b: Opts{"m": Opts{"n": 3}}, want: false},
+		{a: Opts{"c": struct{}{}}, b: Opts{"c": struct{}{}}, want: false},
+	}
+	for i, f := range fix {
+		if got := f.a.EqualSemantic(f.b); got != f.want {
+			t.Errorf("%d: %v and %v got %v", i, f.a, f.b, got)
+		}
+		if got := f.b.EqualSemantic(f.a); got != f.want {
+			t.Errorf("%d: reversed %v and %v got %v", i, f.b, f.a, got)
+		}
+	}
+}
@@ -17,7 +17,7 @@ func (d timer) Match(qs, as Opts) bool {
 }
 
 // Execute
-func (d timer) Execute(ws *Workspace, in Opts, output chan string) (int, Opts, error) {
+func (d timer) Execute(ws *Workspace, in Opts, output chan Output) (int, Opts, error) {
 
 	return 0, in, nil
 }
@@ -0,0 +1,15 @@
+package config
+
+// Rule changes the events that match it as they are published, so for example known
+// failures can be downgraded to warnings without code changes
+type Rule struct {
+	Name string
+	// what events match, all the given conditions must pass
+	Tag  string                 // a glob matched against the event tag, such as task.*.error
+	Opts map[string]interface{} // values the opts must have, keys may be paths such as commit.branch
+
+	// what to change
+	Level  string            // the new level, one of debug, info, warn or error
+	Labels map[string]string // labels to add to the run
+	SetTag string            `yaml:"set-tag"` // the new tag
+}
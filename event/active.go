@@ -0,0 +1,47 @@
+package event
+
+import "sort"
+
+// activeRun is a run that has started but not yet ended or gone stale
+type activeRun struct {
+	ref   RunRef
+	start int64 // the ID of the first event of the run, to order the runs
+}
+
+// track keeps the set of active runs up to date with e.
+// track must be called in the lock.
+func (q *Queue) track(e Event) {
+	// the summary follows the end of the run so must not make it active again
+	if !e.RunRef.Adopted() || e.Tag == TagRunSummary {
+		return
+	}
+	k := e.RunRef.key()
+	if e.Tag == TagEndFlow || e.Tag == TagRunStale {
+		delete(q.active, k)
+		return
+	}
+	if _, ok := q.active[k]; ok {
+		return
+	}
+	if q.active == nil {
+		q.active = map[runKey]activeRun{}
+	}
+	q.active[k] = activeRun{ref: e.RunRef, start: e.ID}
+}
+
+// ActiveRuns returns the runs that have published events but have not yet ended or
+// been reported stale, in the order they started.
+func (q *Queue) ActiveRuns() []RunRef {
+	q.RLock()
+	defer q.RUnlock()
+	runs := make([]activeRun, 0, len(q.active))
+	for _, a := range q.active {
+		runs = append(runs, a)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].start < runs[j].start })
+	refs := make([]RunRef, len(runs))
+	for i, a := range runs {
+		refs[i] = a.ref
+	}
+	return refs
+}
@@ -0,0 +1,32 @@
+package event
+
+import "testing"
+
+func TestActiveRuns(t *testing.T) {
+	q := NewQueue()
+	if len(q.ActiveRuns()) != 0 {
+		t.Fatal("new queue should have no active runs")
+	}
+
+	q.Publish(Event{RunRef: testRef(1), Tag: "start"})
+	q.Publish(Event{RunRef: testRef(2), Tag: "start"})
+	q.Publish(Event{RunRef: testRef(1), Tag: "build"})
+	// general events are not runs
+	q.Publish(Event{Tag: "other"})
+
+	active := q.ActiveRuns()
+	if len(active) != 2 || active[0].Run.ID != 1 || active[1].Run.ID != 2 {
+		t.Fatal("wrong active runs", active)
+	}
+
+	q.Publish(Event{RunRef: testRef(1), Tag: TagEndFlow})
+	active = q.ActiveRuns()
+	if len(active) != 1 || active[0].Run.ID != 2 {
+		t.Fatal("ended run should not be active", active)
+	}
+
+	q.Publish(Event{RunRef: testRef(2), Tag: TagRunStale})
+	if active = q.ActiveRuns(); len(active) != 0 {
+		t.Error("stale run should not be active", active)
+	}
+}
@@ -0,0 +1,26 @@
+package event
+
+import "strings"
+
+// WithAlertObserver also sends every error event to o, whatever normal routing does with
+// it, so an observer such as an on-call pager does not need subscriptions of its own.
+// An error event is any with a Level of error, or with no Level any that is not good,
+// other than system events which are never good.
+func WithAlertObserver(o Observer) QueueOption {
+	return func(q *Queue) {
+		q.alert = &registration{
+			o:       o,
+			filters: []filter{{name: "errors only", pass: isError}},
+			q:       q,
+		}
+	}
+}
+
+// isError is the filter matching error events, those at LevelError or above, or with no
+// level those that are not good apart from system events
+func isError(e Event) bool {
+	if e.Level != LevelUnset {
+		return e.Level >= LevelError
+	}
+	return !e.Good && !strings.HasPrefix(e.Tag, sysPrefix)
+}
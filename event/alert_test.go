@@ -0,0 +1,32 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertObserver(t *testing.T) {
+	alerts := make(chanObs, 10)
+	q := NewQueue(WithAlertObserver(alerts))
+	normal := make(chanObs, 10)
+	q.RegisterWith(normal, WithMaxConcurrent(1))
+
+	ref := testRef(1)
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good", Good: true})
+	q.Publish(Event{RunRef: ref, Tag: TagNodeUpdate})
+	q.Publish(Event{RunRef: ref, Tag: "task.test.bad"})
+
+	for _, tag := range []string{"task.build.good", TagNodeUpdate, "task.test.bad"} {
+		if e := next(t, normal); e.Tag != tag {
+			t.Errorf("normal routing got %s wanted %s", e.Tag, tag)
+		}
+	}
+	if e := next(t, alerts); e.Tag != "task.test.bad" {
+		t.Error("wrong alert", e.Tag)
+	}
+	select {
+	case e := <-alerts:
+		t.Error("only error events should alert", e.Tag)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -0,0 +1,75 @@
+package event
+
+import (
+	"fmt"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// ApprovalKey is the Opts key holding the details of a manual approval request
+const ApprovalKey = "approval"
+
+// ApprovalData describes a manual approval a node is waiting on
+type ApprovalData struct {
+	Prompt    string   // the question shown to the approver
+	Options   []string // the answers the approver can choose from
+	Approvers []string // who may approve, empty means anyone
+}
+
+// NewApprovalEvent returns the event asking for the manual approval of node in the run ref.
+// The UI and the executor should both use this and Approval so they agree on the opts.
+func NewApprovalEvent(ref RunRef, node config.NodeRef, prompt string, options []string, approvers ...string) Event {
+	return Event{
+		RunRef:     ref,
+		SourceNode: node,
+		Tag:        NormalizeTag(fmt.Sprintf("%s.%s.approval", node.Class, node.ID)),
+		Opts: nt.Opts{
+			ApprovalKey: nt.Opts{
+				"prompt":    prompt,
+				"options":   append([]string{}, options...),
+				"approvers": append([]string{}, approvers...),
+			},
+		},
+	}
+}
+
+// Approval returns the approval details of the event, if it is an approval event. It
+// copes with the opts having been through json.
+func (e Event) Approval() (ApprovalData, bool) {
+	var m map[string]interface{}
+	switch a := e.Opts[ApprovalKey].(type) {
+	case nt.Opts:
+		m = a
+	case map[string]interface{}:
+		m = a
+	default:
+		return ApprovalData{}, false
+	}
+	prompt, ok := m["prompt"].(string)
+	if !ok {
+		return ApprovalData{}, false
+	}
+	return ApprovalData{
+		Prompt:    prompt,
+		Options:   stringList(m["options"]),
+		Approvers: stringList(m["approvers"]),
+	}, true
+}
+
+// stringList returns v as a string slice, whether it is one or a decoded json list
+func stringList(v interface{}) []string {
+	switch l := v.(type) {
+	case []string:
+		return append([]string{}, l...)
+	case []interface{}:
+		s := make([]string, 0, len(l))
+		for _, i := range l {
+			if str, ok := i.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	}
+	return []string{}
+}
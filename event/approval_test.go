@@ -0,0 +1,43 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestApprovalEvent(t *testing.T) {
+	node := config.NodeRef{Class: "task", ID: "Deploy"}
+	e := NewApprovalEvent(testRef(1), node, "release to prod?", []string{"yes", "no"}, "ops")
+	if e.Tag != "task.deploy.approval" {
+		t.Error("bad tag", e.Tag)
+	}
+	want := ApprovalData{
+		Prompt:    "release to prod?",
+		Options:   []string{"yes", "no"},
+		Approvers: []string{"ops"},
+	}
+
+	b, err := JSONCodec{}.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := JSONCodec{}.Unmarshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range []Event{e, got} {
+		a, ok := ev.Approval()
+		if !ok {
+			t.Fatal("not an approval event", ev.Opts)
+		}
+		if !reflect.DeepEqual(a, want) {
+			t.Errorf("bad approval data: %+v", a)
+		}
+	}
+
+	if _, ok := (Event{Tag: "task.deploy.good"}).Approval(); ok {
+		t.Error("plain event should not be an approval")
+	}
+}
@@ -0,0 +1,21 @@
+package event
+
+// ArtifactRef refers to an artifact stored outside of the event
+type ArtifactRef struct {
+	URI      string // where to fetch the artifact from
+	Size     int64  // in bytes
+	Checksum string // of the content, such as "sha256:<hex>"
+}
+
+// AddArtifact adds a reference to an artifact to the event
+func (e *Event) AddArtifact(a ArtifactRef) {
+	e.Artifacts = append(e.Artifacts, a)
+}
+
+// ArtifactRefs returns a copy of the references to the artifacts of the event
+func (e Event) ArtifactRefs() []ArtifactRef {
+	if len(e.Artifacts) == 0 {
+		return nil
+	}
+	return append([]ArtifactRef(nil), e.Artifacts...)
+}
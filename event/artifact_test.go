@@ -0,0 +1,38 @@
+package event
+
+import "testing"
+
+func TestArtifacts(t *testing.T) {
+	e := Event{Tag: "task.build.good", Good: true}
+	if e.ArtifactRefs() != nil {
+		t.Error("new event should have no artifacts")
+	}
+	bin := ArtifactRef{URI: "file:///builds/h1-3/floe", Size: 12 << 20, Checksum: "sha256:ab12"}
+	logs := ArtifactRef{URI: "s3://builds/h1-3/build.log", Size: 2048, Checksum: "sha256:cd34"}
+	e.AddArtifact(bin)
+	e.AddArtifact(logs)
+
+	// copies do not share the refs
+	c := e.copy()
+	c.Artifacts[0].URI = "changed"
+	refs := e.ArtifactRefs()
+	refs[1].Size = 0
+	if e.Artifacts[0] != bin || e.Artifacts[1] != logs {
+		t.Error("artifacts shared", e.Artifacts)
+	}
+
+	b, err := JSONCodec{}.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := JSONCodec{}.Unmarshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Artifacts) != 2 || got.Artifacts[0] != bin || got.Artifacts[1] != logs {
+		t.Error("artifacts did not round trip", got.Artifacts)
+	}
+	if !got.Equal(e) {
+		t.Error("round tripped event should be equal")
+	}
+}
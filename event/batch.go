@@ -0,0 +1,82 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchNotifier is sent events in batches by a BatchObserver
+type BatchNotifier interface {
+	NotifyBatch(events []Event)
+}
+
+// BatchObserver collects events passing them on in batches, a batch is sent once window
+// has passed since its first event or once it has maxBatch events, whichever is first.
+type BatchObserver struct {
+	sync.Mutex
+	inner    BatchNotifier
+	window   time.Duration
+	maxBatch int
+	clock    Clock
+
+	pending []Event
+	timer   Timer
+	gen     int // identifies the current batch so a late timer does not send the next one
+}
+
+// NewBatchObserver returns a BatchObserver sending batches to inner, timed by clock,
+// which if nil is the real time. A maxBatch of zero means batches are only sent by time.
+func NewBatchObserver(inner BatchNotifier, window time.Duration, maxBatch int, clock Clock) *BatchObserver {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &BatchObserver{
+		inner:    inner,
+		window:   window,
+		maxBatch: maxBatch,
+		clock:    clock,
+	}
+}
+
+// Notify adds e to the current batch
+func (b *BatchObserver) Notify(e Event) {
+	b.Lock()
+	defer b.Unlock()
+	b.pending = append(b.pending, e)
+	if b.maxBatch > 0 && len(b.pending) >= b.maxBatch {
+		b.flush()
+		return
+	}
+	if len(b.pending) == 1 {
+		gen := b.gen
+		b.timer = b.clock.AfterFunc(b.window, func() {
+			b.Lock()
+			defer b.Unlock()
+			if gen == b.gen {
+				b.flush()
+			}
+		})
+	}
+}
+
+// Close sends any events in the current batch
+func (b *BatchObserver) Close() {
+	b.Lock()
+	defer b.Unlock()
+	b.flush()
+}
+
+// flush sends the current batch and starts a new one. flush must be called in the lock.
+func (b *BatchObserver) flush() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.gen++
+	if len(b.pending) == 0 {
+		return
+	}
+	batch := b.pending
+	b.pending = nil
+	b.inner.NotifyBatch(batch)
+}
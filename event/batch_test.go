@@ -0,0 +1,54 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+type batches [][]Event
+
+func (b *batches) NotifyBatch(events []Event) {
+	*b = append(*b, events)
+}
+
+func TestBatchObserver(t *testing.T) {
+	clk := NewFakeClock()
+	got := &batches{}
+	b := NewBatchObserver(got, time.Second, 3, clk)
+
+	// by time
+	b.Notify(Event{ID: 1})
+	clk.Advance(500 * time.Millisecond)
+	b.Notify(Event{ID: 2})
+	if len(*got) != 0 {
+		t.Fatal("batch sent early")
+	}
+	clk.Advance(500 * time.Millisecond)
+	if len(*got) != 1 || len((*got)[0]) != 2 {
+		t.Fatal("window should have sent the batch of two", *got)
+	}
+
+	// by size
+	for id := int64(3); id <= 6; id++ {
+		b.Notify(Event{ID: id})
+	}
+	if len(*got) != 2 || len((*got)[1]) != 3 || (*got)[1][0].ID != 3 {
+		t.Fatal("full batch should be sent at once", *got)
+	}
+	// the timer from the full batch must not send the next batch early
+	clk.Advance(900 * time.Millisecond)
+	if len(*got) != 2 {
+		t.Fatal("old batch timer sent the new batch")
+	}
+	b.Notify(Event{ID: 7})
+
+	// close sends what is left
+	b.Close()
+	if len(*got) != 3 || len((*got)[2]) != 2 || (*got)[2][1].ID != 7 {
+		t.Fatal("close should send the partial batch", *got)
+	}
+	clk.Advance(time.Minute)
+	if len(*got) != 3 {
+		t.Error("nothing left to send after close")
+	}
+}
@@ -0,0 +1,37 @@
+package event
+
+import "sync"
+
+// bookends tracks which runs have had their first event forwarded
+type bookends struct {
+	sync.Mutex
+	started map[runKey]bool
+}
+
+// pass returns true for the first event of each run and the event that ends it
+func (b *bookends) pass(e Event) bool {
+	// the summary follows the end of the run so it is not a new first event
+	if !e.RunRef.Adopted() || e.Tag == TagRunSummary {
+		return false
+	}
+	b.Lock()
+	defer b.Unlock()
+	k := e.RunRef.key()
+	if e.Tag == TagEndFlow || e.Tag == TagRunStale {
+		delete(b.started, k)
+		return true
+	}
+	if b.started[k] {
+		return false
+	}
+	b.started[k] = true
+	return true
+}
+
+// RegisterBookends registers an observer that is only sent the first event of each run,
+// and the event that ends it, either TagEndFlow or TagRunStale. The events are sent
+// serially so the end of a run is never delivered before its start.
+func (q *Queue) RegisterBookends(o Observer) error {
+	b := &bookends{started: map[runKey]bool{}}
+	return q.RegisterWith(o, withRunScope(), WithMaxConcurrent(1), withStatefulFilter("bookends", b.pass))
+}
@@ -0,0 +1,34 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegisterBookends(t *testing.T) {
+	q := NewQueue()
+	c := make(chanObs, 10)
+	if err := q.RegisterBookends(c); err != nil {
+		t.Fatal(err)
+	}
+
+	ref := testRef(1)
+	q.Publish(Event{Tag: "inbound.data"})
+	q.Publish(Event{RunRef: ref, Tag: "trigger.good", Good: true})
+	q.Publish(Event{RunRef: ref, Tag: "sys.node.start"})
+	q.Publish(Event{RunRef: ref, Tag: TagNodeUpdate})
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good", Good: true})
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow, Good: true})
+
+	for _, tag := range []string{"trigger.good", TagEndFlow} {
+		if e := next(t, c); e.Tag != tag {
+			t.Errorf("got %s wanted %s", e.Tag, tag)
+		}
+	}
+	// the run summary is published after the end and must not be forwarded
+	select {
+	case e := <-c:
+		t.Error("only the first and last events should be forwarded", e.Tag)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
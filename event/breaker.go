@@ -0,0 +1,81 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker states
+const (
+	breakerClosed   = iota // events are delivered
+	breakerOpen            // events skip the observer
+	breakerHalfOpen        // a single probe event is being delivered
+)
+
+// breaker is a circuit breaker tripped by consecutive observer failures
+type breaker struct {
+	mu       sync.Mutex
+	state    int
+	failures int // consecutive failures
+	max      int // failures that trip the breaker
+	cooldown time.Duration
+	opened   time.Time
+}
+
+// WithBreaker trips a circuit breaker after failures consecutive NotifyCtx errors from
+// a ContextObserver. Whilst open events skip the observer and are dead lettered. After
+// cooldown the next event is sent as a probe, if it succeeds the breaker closes.
+func WithBreaker(failures int, cooldown time.Duration) RegisterOption {
+	return func(r *registration) {
+		r.breaker = &breaker{
+			max:      failures,
+			cooldown: cooldown,
+		}
+	}
+}
+
+// allow returns true if an event can be delivered
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if now.Sub(b.opened) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// only one probe at a time
+		return false
+	}
+	return true
+}
+
+// done records the result of a delivery, logging any change of state to l
+func (b *breaker) done(err error, now time.Time, l Logger) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		if b.state != breakerClosed {
+			l.Debugf("breaker - observer recovered, closing")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.max {
+		if b.state != breakerOpen {
+			l.Debugf("breaker - observer failing, opening: %v", err)
+		}
+		b.state = breakerOpen
+		b.opened = now
+	}
+}
+
+func (b *breaker) getState() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,90 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flaky is a ContextObserver that fails while failing is set
+type flaky struct {
+	sync.Mutex
+	failing bool
+	calls   int
+}
+
+func (f *flaky) Notify(e Event) {}
+
+func (f *flaky) NotifyCtx(ctx context.Context, e Event) error {
+	f.Lock()
+	defer f.Unlock()
+	f.calls++
+	if f.failing {
+		return errors.New("flaky")
+	}
+	return nil
+}
+
+func (f *flaky) getCalls() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.calls
+}
+
+func TestBreaker(t *testing.T) {
+	clk := NewFakeClock()
+	dead := make(chanObs, 100)
+	q := NewQueue(WithClock(clk), WithDeadLetter(dead))
+	f := &flaky{failing: true}
+	q.RegisterWith(f, WithBreaker(3, time.Minute))
+	r := q.observers[0]
+
+	// deliver synchronously so the order of failures is known
+	send := func(tag string) {
+		r.deliver(Event{Tag: tag})
+	}
+
+	for i := 0; i < 3; i++ {
+		send("fail")
+	}
+	if r.breaker.getState() != breakerOpen {
+		t.Fatal("breaker should have opened")
+	}
+	// open so the observer is skipped
+	send("skipped")
+	if f.getCalls() != 3 {
+		t.Error("open breaker should skip the observer", f.getCalls())
+	}
+	if len(dead) != 4 {
+		t.Fatal("failed and skipped events should be dead lettered", len(dead))
+	}
+	for i := 0; i < 3; i++ {
+		<-dead
+	}
+	if e := <-dead; e.Tag != "skipped" || e.Opts[DeadLetterKey] != "circuit open" {
+		t.Error("bad dead letter", e.Tag, e.Opts)
+	}
+
+	// after the cooldown a failing probe opens it again
+	clk.Advance(time.Minute)
+	send("probe")
+	if f.getCalls() != 4 || r.breaker.getState() != breakerOpen {
+		t.Error("failed probe should reopen", f.getCalls(), r.breaker.getState())
+	}
+
+	// after the next cooldown a good probe closes it
+	f.Lock()
+	f.failing = false
+	f.Unlock()
+	clk.Advance(time.Minute)
+	send("probe")
+	if r.breaker.getState() != breakerClosed {
+		t.Fatal("breaker should have closed on recovery")
+	}
+	send("ok")
+	if f.getCalls() != 6 {
+		t.Error("closed breaker should deliver", f.getCalls())
+	}
+}
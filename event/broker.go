@@ -0,0 +1,151 @@
+package event
+
+import "sync"
+
+const defaultBrokerBuffer = 1000 // payloads held while the broker is unavailable
+
+// BrokerClient is the minimal client needed to talk to an external message broker
+// such as NATS or Kafka. It is injected so the broker can be faked.
+type BrokerClient interface {
+	// Connect (re)establishes the connection to the broker
+	Connect() error
+	// Publish sends data to the subject (or topic)
+	Publish(subject string, data []byte) error
+}
+
+// BrokerObserver is an Observer that forwards events to an external message broker.
+type BrokerObserver interface {
+	Observer
+	// Flush attempts to send any events buffered whilst the broker was unavailable
+	Flush() error
+}
+
+// BrokerOption configures a Broker
+type BrokerOption func(*Broker)
+
+// BrokerBuffer sets the maximum number of events buffered while the broker is down,
+// once full the oldest buffered events are dropped.
+func BrokerBuffer(n int) BrokerOption {
+	return func(b *Broker) {
+		b.max = n
+	}
+}
+
+type brokerMsg struct {
+	subject string
+	data    []byte
+}
+
+// Broker publishes events to a subject derived from the event and flow:
+// floe.<flow>.<tag>, serialised by the codec.
+type Broker struct {
+	sync.Mutex
+
+	client BrokerClient
+	codec  EventCodec
+	allow  map[string]bool     // if not nil only these opts are sent
+	remap  func(string) string // if not nil renames tags on the way out
+	logger Logger              // the logger of the queue it is registered on
+
+	down    bool        // true if the last publish failed
+	max     int         // max buffered messages
+	pending []brokerMsg // messages waiting for the broker to come back
+	dropped int64       // count of buffered messages that had to be dropped
+}
+
+// NewBroker returns a Broker publishing to client using codec.
+func NewBroker(client BrokerClient, codec EventCodec, opts ...BrokerOption) *Broker {
+	b := &Broker{
+		client: client,
+		codec:  codec,
+		max:    defaultBrokerBuffer,
+		logger: defaultLogger,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Subject returns the broker subject for the event e
+func Subject(e Event) string {
+	flow := e.RunRef.FlowRef.ID
+	if flow == "" {
+		flow = "na"
+	}
+	return "floe." + flow + "." + e.Tag
+}
+
+// Notify satisfies Observer, forwarding e to the broker, or buffering it if the broker is unavailable
+func (b *Broker) Notify(e Event) {
+	if b.allow != nil {
+		e = e.AllowOpts(b.allow)
+	}
+	if b.remap != nil {
+		e.Tag = b.remap(e.Tag)
+	}
+	data, err := b.codec.Marshal(e)
+
+	b.Lock()
+	defer b.Unlock()
+	if err != nil {
+		b.logger.Errorf("broker - could not encode event %d: %v", e.ID, err)
+		return
+	}
+
+	b.buffer(brokerMsg{subject: Subject(e), data: data})
+	if err := b.flush(); err != nil {
+		b.logger.Debugf("broker - publish failed, %d events buffered: %v", len(b.pending), err)
+	}
+}
+
+// useLogger satisfies queueLogged, so the broker logs to the queue it is registered on
+func (b *Broker) useLogger(l Logger) {
+	b.Lock()
+	defer b.Unlock()
+	b.logger = l
+}
+
+// Flush attempts to send all buffered events
+func (b *Broker) Flush() error {
+	b.Lock()
+	defer b.Unlock()
+	return b.flush()
+}
+
+// Dropped returns how many events have been dropped due to the buffer overflowing
+func (b *Broker) Dropped() int64 {
+	b.Lock()
+	defer b.Unlock()
+	return b.dropped
+}
+
+func (b *Broker) buffer(m brokerMsg) {
+	if b.max > 0 && len(b.pending) >= b.max {
+		// drop the oldest
+		copy(b.pending, b.pending[1:])
+		b.pending = b.pending[:len(b.pending)-1]
+		b.dropped++
+	}
+	b.pending = append(b.pending, m)
+}
+
+// flush must be called in the lock
+func (b *Broker) flush() error {
+	if b.down {
+		if err := b.client.Connect(); err != nil {
+			return err
+		}
+		b.down = false
+	}
+	for len(b.pending) > 0 {
+		m := b.pending[0]
+		if err := b.client.Publish(m.subject, m.data); err != nil {
+			b.down = true
+			return err
+		}
+		b.pending[0] = brokerMsg{}
+		b.pending = b.pending[1:]
+	}
+	return nil
+}
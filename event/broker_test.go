@@ -0,0 +1,93 @@
+package event
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+type fakeBroker struct {
+	down     bool
+	connects int
+	subjects []string
+	payloads [][]byte
+}
+
+func (f *fakeBroker) Connect() error {
+	f.connects++
+	if f.down {
+		return errors.New("still down")
+	}
+	return nil
+}
+
+func (f *fakeBroker) Publish(subject string, data []byte) error {
+	if f.down {
+		return errors.New("broker down")
+	}
+	f.subjects = append(f.subjects, subject)
+	f.payloads = append(f.payloads, data)
+	return nil
+}
+
+func TestBroker(t *testing.T) {
+	fb := &fakeBroker{}
+	b := NewBroker(fb, JSONCodec{}, BrokerBuffer(2))
+
+	e := Event{
+		RunRef: RunRef{
+			FlowRef: config.FlowRef{ID: "build", Ver: 1},
+		},
+		Tag: "task.checkout.good",
+		ID:  3,
+	}
+	b.Notify(e)
+	if len(fb.subjects) != 1 || fb.subjects[0] != "floe.build.task.checkout.good" {
+		t.Fatal("bad subjects", fb.subjects)
+	}
+	got, err := JSONCodec{}.Unmarshal(fb.payloads[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 3 || got.Tag != e.Tag || !got.RunRef.Equal(e.RunRef) {
+		t.Error("payload did not decode to the event", got)
+	}
+
+	// general events have no flow
+	b.Notify(Event{Tag: "inbound.push"})
+	if fb.subjects[1] != "floe.na.inbound.push" {
+		t.Error("bad general subject", fb.subjects[1])
+	}
+
+	// broker goes down - events are buffered and the oldest dropped
+	fb.down = true
+	for i := int64(10); i < 13; i++ {
+		e.ID = i
+		b.Notify(e)
+	}
+	if len(fb.subjects) != 2 {
+		t.Error("should not have published while down", len(fb.subjects))
+	}
+	if b.Dropped() != 1 {
+		t.Error("should have dropped one event", b.Dropped())
+	}
+
+	// and back up again
+	fb.down = false
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if fb.connects == 0 {
+		t.Error("broker should have reconnected")
+	}
+	if len(fb.subjects) != 4 {
+		t.Fatal("buffered events not sent", len(fb.subjects))
+	}
+	for i, id := range []int64{11, 12} {
+		got, _ := JSONCodec{}.Unmarshal(fb.payloads[i+2])
+		if got.ID != id {
+			t.Errorf("%d - wrong buffered event sent %d", i, got.ID)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package event
+
+import "sync"
+
+// BufferGroup is a budget of buffered events shared by a set of observers, such as all
+// the observers of one tenant, so that together they can not buffer more than the budget
+// however the events are spread between them.
+type BufferGroup struct {
+	sync.Mutex
+	max      int
+	buffered int
+}
+
+// NewBufferGroup returns a group that allows its observers to buffer at most max events
+// between them.
+func NewBufferGroup(max int) *BufferGroup {
+	return &BufferGroup{max: max}
+}
+
+// WithBufferGroup counts the events buffered for the observer against the budget of g.
+// Events that would exceed the budget are sent to the dead letter observer instead.
+// The observer is notified serially unless WithMaxConcurrent is also given.
+func WithBufferGroup(g *BufferGroup) RegisterOption {
+	return func(r *registration) {
+		r.budget = g
+	}
+}
+
+// Buffered returns how many events the observers of the group currently have buffered
+func (g *BufferGroup) Buffered() int {
+	g.Lock()
+	defer g.Unlock()
+	return g.buffered
+}
+
+// take reserves room for one event, returning false if the budget is used up
+func (g *BufferGroup) take() bool {
+	g.Lock()
+	defer g.Unlock()
+	if g.buffered >= g.max {
+		return false
+	}
+	g.buffered++
+	return true
+}
+
+// release returns the room of an event no longer buffered
+func (g *BufferGroup) release() {
+	g.Lock()
+	defer g.Unlock()
+	g.buffered--
+}
@@ -0,0 +1,67 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBufferGroup(t *testing.T) {
+	var mu sync.Mutex
+	dead := 0
+	q := NewQueue(WithDeadLetter(&listener{what: func(e Event) {
+		mu.Lock()
+		dead++
+		mu.Unlock()
+	}}))
+	g := NewBufferGroup(3)
+	o1 := &gateObs{release: make(chan struct{}), got: make(chan Event, 10)}
+	o2 := &gateObs{release: make(chan struct{}), got: make(chan Event, 10)}
+	q.RegisterWith(o1, WithBufferGroup(g))
+	q.RegisterWith(o2, WithBufferGroup(g))
+
+	// both observers take the blocking event so it is no longer buffered
+	q.Publish(Event{Tag: "blocking"})
+	time.Sleep(10 * time.Millisecond)
+	if n := g.Buffered(); n != 0 {
+		t.Fatal("delivered events should not be buffered", n)
+	}
+
+	// each event is buffered for both observers, so only three of the six fit
+	for _, tag := range []string{"e1", "e2", "e3"} {
+		q.Publish(Event{Tag: tag})
+	}
+	if n := g.Buffered(); n != 3 {
+		t.Error("group should be full", n)
+	}
+	mu.Lock()
+	if dead != 3 {
+		t.Error("events over the budget should be dead lettered", dead)
+	}
+	mu.Unlock()
+
+	close(o1.release)
+	close(o2.release)
+	expect := func(o *gateObs, tags ...string) {
+		for _, tag := range tags {
+			select {
+			case e := <-o.got:
+				if e.Tag != tag {
+					t.Errorf("got %s wanted %s", e.Tag, tag)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for", tag)
+			}
+		}
+	}
+	expect(o1, "blocking", "e1", "e2")
+	expect(o2, "blocking", "e1")
+
+	// with the buffers drained there is room again
+	q.Publish(Event{Tag: "e4"})
+	expect(o1, "e4")
+	expect(o2, "e4")
+	if n := g.Buffered(); n != 0 {
+		t.Error("drained group should have nothing buffered", n)
+	}
+}
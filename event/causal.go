@@ -0,0 +1,98 @@
+package event
+
+import (
+	"sort"
+	"time"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// SentTimeKey is the Opts key holding the time an event was sent with by another host
+// when it was before the last event of its run on this host, and so was clamped
+const SentTimeKey = "sent_time"
+
+// stampCausal sets the Lamport timestamp for events within an adopted run. Each event
+// advances the run clock beyond both the local clock and any causal time the event
+// already carries, so events received from other hosts are ordered after everything
+// that happened before them on the sending host.
+// stampCausal must be called in the lock.
+func (q *Queue) stampCausal(e *Event) {
+	if !e.RunRef.Adopted() {
+		return
+	}
+	if q.causal == nil {
+		q.causal = map[runKey]int64{}
+	}
+	k := e.RunRef.key()
+	c := q.causal[k]
+	if e.Causal > c {
+		c = e.Causal
+	}
+	c++
+	e.Causal = c
+	if e.Tag == TagEndFlow {
+		delete(q.causal, k)
+		return
+	}
+	q.causal[k] = c
+}
+
+// Receive publishes an event forwarded from another host, merging its causal time with
+// this hosts clock for the run. Unlike Publish the event keeps the time it was sent
+// with, but as the clocks of hosts may be skewed, never earlier than the last event of
+// its run on this host, so the times of a run only go forward.
+func (q *Queue) Receive(e Event) {
+	if q.parent != nil {
+		q.reparent(e)
+		return
+	}
+	q.Lock()
+	q.init()
+	if q.hold(e) {
+		q.Unlock()
+		return
+	}
+	d := q.stamp(e, true)
+	q.Unlock()
+	d.dispatch(q)
+}
+
+// stampReceived sets the time of the received event e, clamping any time before the last
+// event of its run, with the sent time kept in the SentTimeKey opt.
+// stampReceived must be called in the lock.
+func (q *Queue) stampReceived(e *Event) {
+	if e.Time.IsZero() {
+		e.Time = q.now()
+		return
+	}
+	if !e.RunRef.Adopted() {
+		return
+	}
+	h, ok := q.history[e.RunRef.key()]
+	if !ok || !e.Time.Before(h.last) {
+		return
+	}
+	opts := make(nt.Opts, len(e.Opts)+1)
+	for k, v := range e.Opts {
+		opts[k] = v
+	}
+	opts[SentTimeKey] = e.Time.UTC().Format(time.RFC3339Nano)
+	e.Opts = opts
+	e.Time = h.last
+}
+
+// SortCausal orders the events of a run, possibly merged from several hosts, into
+// happened-before order. Concurrent events with the same causal time are ordered by
+// executing host then ID to make the order stable.
+func SortCausal(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.Causal != b.Causal {
+			return a.Causal < b.Causal
+		}
+		if a.RunRef.ExecHost != b.RunRef.ExecHost {
+			return a.RunRef.ExecHost < b.RunRef.ExecHost
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,123 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// recorder keeps every event it is notified of
+type recorder struct {
+	sync.Mutex
+	wg     sync.WaitGroup
+	events []Event
+}
+
+func (r *recorder) Notify(e Event) {
+	r.Lock()
+	r.events = append(r.events, e)
+	r.Unlock()
+	r.wg.Done()
+}
+
+// publish publishes e on q and waits for the recorder to have seen it
+func (r *recorder) publish(q *Queue, e Event) Event {
+	return r.waitFor(func() { q.Publish(e) })
+}
+
+// receive forwards e to q and waits for the recorder to have seen it
+func (r *recorder) receive(q *Queue, e Event) Event {
+	return r.waitFor(func() { q.Receive(e) })
+}
+
+func (r *recorder) waitFor(f func()) Event {
+	r.wg.Add(1)
+	f()
+	r.wg.Wait()
+	r.Lock()
+	defer r.Unlock()
+	return r.events[len(r.events)-1]
+}
+
+func TestCausalOrder(t *testing.T) {
+	ref := RunRef{
+		FlowRef: config.FlowRef{ID: "build", Ver: 1},
+		Run:     HostedIDRef{HostID: "a", ID: 1},
+	}
+	onA, onB := ref, ref
+	onA.ExecHost = "a"
+	onB.ExecHost = "b"
+
+	qa, qb := NewQueue(), NewQueue()
+	ra, rb := &recorder{}, &recorder{}
+	qa.Register(ra)
+	qb.Register(rb)
+
+	// host b is busy with other events so its IDs are well ahead of a's
+	for i := 0; i < 10; i++ {
+		rb.publish(qb, Event{Tag: "other"})
+	}
+
+	// a starts the run and forwards to b
+	e1 := ra.publish(qa, Event{RunRef: onA, Tag: "trigger.good"})
+	e2 := ra.publish(qa, Event{RunRef: onA, Tag: "sys.state"})
+	rb.receive(qb, e1)
+	rb.receive(qb, e2)
+	// b executes a node and forwards its result to a
+	e3 := rb.publish(qb, Event{RunRef: onB, Tag: "task.build.good"})
+	ra.receive(qa, e3)
+	// a reacts
+	e4 := ra.publish(qa, Event{RunRef: onA, Tag: "task.test.good"})
+
+	if e3.ID < e4.ID {
+		t.Fatal("test needs host IDs that do not reflect the real order")
+	}
+
+	// the original events from each host, interleaved badly
+	merged := []Event{e4, e3, e2, e1}
+	SortCausal(merged)
+	for i, tag := range []string{"trigger.good", "sys.state", "task.build.good", "task.test.good"} {
+		if merged[i].Tag != tag {
+			t.Errorf("%d - wrong order wanted %s got %s (causal %d)", i, tag, merged[i].Tag, merged[i].Causal)
+		}
+	}
+}
+
+func TestReceiveClampsSkew(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk))
+	r := &recorder{}
+	q.Register(r)
+	ref := testRef(1)
+
+	local := r.publish(q, Event{RunRef: ref, Tag: "trigger.good"})
+
+	// the sending host is a minute behind
+	sent := local.Time.Add(-time.Minute)
+	e := r.receive(q, Event{RunRef: ref, Tag: "task.build.good", Time: sent, Opts: nt.Opts{"a": 1}})
+	if !e.Time.Equal(local.Time) {
+		t.Error("a received event should not be before the last of its run", e.Time, local.Time)
+	}
+	if e.Opts[SentTimeKey] != sent.UTC().Format(time.RFC3339Nano) || e.Opts["a"] != 1 {
+		t.Error("the sent time should be kept in the opts", e.Opts)
+	}
+
+	// a sending host that is ahead keeps its time
+	ahead := local.Time.Add(time.Minute)
+	e = r.receive(q, Event{RunRef: ref, Tag: "task.test.good", Time: ahead})
+	if !e.Time.Equal(ahead) {
+		t.Error("a later time should be kept", e.Time)
+	}
+	if _, ok := e.Opts[SentTimeKey]; ok {
+		t.Error("an event not clamped should not have a sent time", e.Opts)
+	}
+
+	// the first event of a run on this host has nothing to be clamped to
+	e = r.receive(q, Event{RunRef: testRef(2), Tag: "task.build.good", Time: sent})
+	if !e.Time.Equal(sent) {
+		t.Error("the first event of a run should keep its time", e.Time)
+	}
+}
@@ -0,0 +1,71 @@
+package event
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// WithHashChain makes the queue chain each persisted event to the one before it in its
+// run, by setting PrevHash to the Hash of the previous event and Hash to a hash of PrevHash
+// and the event. Any change to, or removal of, an event archived by ExportRun can then be
+// detected with VerifyChain. Events outside of runs are chained together, and events the
+// persist filter drops, such as node updates, are not chained, see WithPersistFilter.
+func WithHashChain() QueueOption {
+	return func(q *Queue) {
+		q.chain = true
+	}
+}
+
+// chainHash returns the hash of the event along with the previous hash in the chain,
+// ignoring any Hash already set on e.
+func chainHash(e Event) (string, error) {
+	e.Hash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(e.PrevHash))
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// link sets the hashes on e chaining it to the previous persisted event of its run.
+// link must be called in the lock once all other fields are set.
+func (q *Queue) link(e *Event) {
+	if !q.chain || !q.persists(*e) {
+		return
+	}
+	head := &q.chainHead
+	if e.RunRef.Adopted() {
+		head = &q.runHistoryOf(*e).chainHead
+	}
+	e.PrevHash = *head
+	h, err := chainHash(*e)
+	if err != nil {
+		// an unhashable event breaks the chain, which VerifyChain will report
+		q.logger.Errorf("<%s-ev:%d> - can not hash event: %v", e.RunRef, e.ID, err)
+	}
+	e.Hash = h
+	*head = h
+}
+
+// VerifyChain checks that events, which must be a contiguous part of a hash chain in
+// ID order, such as the export of a run, have not been changed and that none are missing.
+func VerifyChain(events []Event) error {
+	for i, e := range events {
+		if i > 0 && e.PrevHash != events[i-1].Hash {
+			return fmt.Errorf("event %d does not follow event %d in the chain", e.ID, events[i-1].ID)
+		}
+		h, err := chainHash(e)
+		if err != nil {
+			return err
+		}
+		if h != e.Hash {
+			return fmt.Errorf("event %d has been changed", e.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestHashChain(t *testing.T) {
+	q := NewQueue(WithHashChain())
+	ref := testRef(1)
+	q.Publish(Event{Tag: "inbound.push", Opts: nt.Opts{"ref": "master"}})
+	q.Publish(Event{RunRef: ref, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good", Good: true})
+	q.Publish(Event{RunRef: ref, Tag: "task.test.bad"})
+
+	if err := VerifyChain(q.Tail(10)[:1]); err != nil {
+		t.Fatal("general chain should verify", err)
+	}
+	events := q.History(ref)
+	if len(events) != 3 {
+		t.Fatal("wrong number of events", len(events))
+	}
+	if err := VerifyChain(events); err != nil {
+		t.Fatal("untouched chain should verify", err)
+	}
+
+	// the chain survives being encoded for storage
+	var stored []Event
+	for _, e := range events {
+		b, err := JSONCodec{}.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		se, err := JSONCodec{}.Unmarshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored = append(stored, se)
+	}
+	if err := VerifyChain(stored); err != nil {
+		t.Fatal("decoded chain should verify", err)
+	}
+
+	tampered := q.History(ref)
+	tampered[1].Good = false
+	if err := VerifyChain(tampered); err == nil {
+		t.Error("changed event not detected")
+	}
+	tampered = q.History(ref)
+	tampered[0].Opts["extra"] = "x"
+	if err := VerifyChain(tampered); err == nil {
+		t.Error("changed opts not detected")
+	}
+	removed := q.History(ref)
+	removed = append(removed[:1], removed[2:]...)
+	if err := VerifyChain(removed); err == nil {
+		t.Error("removed event not detected")
+	}
+}
+
+func TestHashChainInterleavedExport(t *testing.T) {
+	q := NewQueue(WithHashChain())
+	a, b := testRef(1), testRef(2)
+	for _, tag := range []string{"trigger.good", "task.build.good", TagEndFlow} {
+		q.Publish(Event{RunRef: a, Tag: tag})
+		q.Publish(Event{RunRef: b, Tag: TagNodeUpdate, Opts: nt.Opts{"line": "building"}})
+		q.Publish(Event{RunRef: b, Tag: tag})
+		q.Publish(Event{Tag: "inbound.push"})
+	}
+
+	for _, ref := range []RunRef{a, b} {
+		buf := &bytes.Buffer{}
+		if err := q.ExportRun(ref, buf, JSONCodec{}); err != nil {
+			t.Fatal(err)
+		}
+		var exported []Event
+		sc := bufio.NewScanner(buf)
+		for sc.Scan() {
+			e, err := JSONCodec{}.Unmarshal(sc.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			exported = append(exported, e)
+		}
+		// the three published and the run summary
+		if len(exported) != 4 {
+			t.Fatalf("<%s> exported %d events", ref, len(exported))
+		}
+		if err := VerifyChain(exported); err != nil {
+			t.Errorf("<%s> export should verify: %v", ref, err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package event
+
+import "time"
+
+// Clock is the source of time for the queue, it can be replaced to control time in tests.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after duration d
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call scheduled by a Clock
+type Timer interface {
+	// Stop prevents the timer firing, returning false if it has already fired or been stopped
+	Stop() bool
+}
+
+// realClock is the default Clock using the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
@@ -0,0 +1,74 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CodecVersion is the version of the envelope layout written by JSONCodec
+const CodecVersion = 2
+
+// EventCodec converts events to and from a byte representation, for forwarding events
+// off this host or persisting them.
+type EventCodec interface {
+	Marshal(e Event) ([]byte, error)
+	Unmarshal(b []byte) (Event, error)
+}
+
+// envelope wraps the serialised event with the layout version that wrote it
+type envelope struct {
+	Version int
+	Event   json.RawMessage
+}
+
+// decoders decode the event from an envelope by version, each upgrading from older
+// layouts to the current Event
+var decoders = map[int]func(json.RawMessage) (Event, error){
+	1: decodeV1,
+	2: decodeV2,
+}
+
+// JSONCodec is the default EventCodec encoding events as json in a versioned envelope
+type JSONCodec struct{}
+
+// Marshal encodes e as json
+func (JSONCodec) Marshal(e Event) ([]byte, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{
+		Version: CodecVersion,
+		Event:   b,
+	})
+}
+
+// Unmarshal decodes the json in b to an Event, upgrading any older layouts
+func (JSONCodec) Unmarshal(b []byte) (Event, error) {
+	env := envelope{}
+	if err := json.Unmarshal(b, &env); err != nil {
+		return Event{}, err
+	}
+	// version 1 had no envelope, the event fields are at the top level
+	if env.Version == 0 {
+		env.Version = 1
+		env.Event = b
+	}
+	dec, ok := decoders[env.Version]
+	if !ok {
+		return Event{}, fmt.Errorf("unsupported event codec version %d", env.Version)
+	}
+	return dec(env.Event)
+}
+
+// decodeV1 decodes events written before the envelope existed, the only difference
+// being the lack of envelope.
+func decodeV1(b json.RawMessage) (Event, error) {
+	return decodeV2(b)
+}
+
+func decodeV2(b json.RawMessage) (Event, error) {
+	e := Event{}
+	err := json.Unmarshal(b, &e)
+	return e, err
+}
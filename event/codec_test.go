@@ -0,0 +1,71 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	e := Event{
+		RunRef: RunRef{
+			FlowRef:  config.FlowRef{ID: "build", Ver: 2},
+			Run:      HostedIDRef{HostID: "h1", ID: 4},
+			ExecHost: "h2",
+		},
+		SourceNode: config.NodeRef{Class: "task", ID: "checkout"},
+		Tag:        "task.checkout.good",
+		Good:       true,
+		ID:         9,
+		Opts: nt.Opts{
+			"branch": "master",
+			"exit":   2,
+			"env":    []string{"A=1"},
+			"stats":  nt.Opts{"took": int64(40), "files": []int{3, 4}},
+		},
+	}
+	b, err := JSONCodec{}.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b[:11]) != `{"Version":` {
+		t.Error("no version in the envelope", string(b))
+	}
+	got, err := JSONCodec{}.Unmarshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.RunRef.Equal(e.RunRef) || got.RunRef.ExecHost != "h2" || got.SourceNode != e.SourceNode ||
+		got.Tag != e.Tag || got.Good != e.Good || got.ID != e.ID {
+		t.Error("round trip failed", got)
+	}
+	if !got.Opts.EqualSemantic(e.Opts) {
+		t.Error("round trip changed the opts", got.Opts)
+	}
+}
+
+func TestCodecV1(t *testing.T) {
+	// as written by older hubs, before the envelope
+	v1 := []byte(`{"RunRef":{"FlowRef":{"ID":"build","Ver":1},"Run":{"HostID":"h1","ID":3},"ExecHost":"h1"},
+		"SourceNode":{"Class":"task","ID":"test"},"Tag":"task.test.bad","Good":false,"ID":12,"Opts":{"exit":1}}`)
+
+	e, err := JSONCodec{}.Unmarshal(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.RunRef.Run.String() != "h1-3" || e.RunRef.FlowRef.ID != "build" {
+		t.Error("bad run ref", e.RunRef)
+	}
+	if e.SourceNode.ID != "test" || e.Tag != "task.test.bad" || e.Good || e.ID != 12 {
+		t.Error("bad event fields", e)
+	}
+	if e.Opts["exit"] != float64(1) {
+		t.Error("bad opts", e.Opts)
+	}
+
+	_, err = JSONCodec{}.Unmarshal([]byte(`{"Version":99,"Event":{}}`))
+	if err == nil {
+		t.Error("unknown version should error")
+	}
+}
@@ -0,0 +1,102 @@
+package event
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/floeit/floe/config"
+)
+
+// Compaction decides which events of a run are retained in its History as they are
+// recorded, so each flow can keep as much history as it needs. Runs resumed with
+// SubscribeRun are only sent the retained events.
+type Compaction interface {
+	// Keep returns true if e should be added to the history
+	Keep(e Event) bool
+	// Max is the most events retained for a run, older events are evicted once it is
+	// reached. Zero means the limit set on the queue by WithRunHistory.
+	Max() int
+}
+
+// KeepAll is the default Compaction retaining every event up to the queue limit
+type KeepAll struct{}
+
+// Keep satisfies Compaction
+func (KeepAll) Keep(e Event) bool { return true }
+
+// Max satisfies Compaction
+func (KeepAll) Max() int { return 0 }
+
+// Milestones is the Compaction retaining all but the noisy node update events
+type Milestones struct{}
+
+// Keep satisfies Compaction
+func (Milestones) Keep(e Event) bool { return persistMilestones(e) }
+
+// Max satisfies Compaction
+func (Milestones) Max() int { return 0 }
+
+// LastN is the Compaction retaining only the most recent N events of a run. The trigger
+// event is kept apart from the history, so the run can still be re-run once it is evicted.
+type LastN int
+
+// Keep satisfies Compaction
+func (LastN) Keep(e Event) bool { return true }
+
+// Max satisfies Compaction
+func (n LastN) Max() int { return int(n) }
+
+// ParseCompaction returns the built in Compaction named by s as given in the history
+// setting of a flow, one of "all", "milestones" or "last-N". Empty is "all".
+func ParseCompaction(s string) (Compaction, error) {
+	switch s {
+	case "", "all":
+		return KeepAll{}, nil
+	case "milestones":
+		return Milestones{}, nil
+	}
+	if strings.HasPrefix(s, "last-") {
+		n, err := strconv.Atoi(s[len("last-"):])
+		if err == nil && n > 0 {
+			return LastN(n), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown history compaction '%s'", s)
+}
+
+// WithCompaction sets the Compaction of the history of runs of the flow
+func WithCompaction(flow config.FlowRef, c Compaction) QueueOption {
+	return func(q *Queue) {
+		if q.compaction == nil {
+			q.compaction = map[config.FlowRef]Compaction{}
+		}
+		q.compaction[flow] = c
+	}
+}
+
+// FlowCompaction returns the option setting the Compaction of each of the flows
+// from their history setting
+func FlowCompaction(flows []*config.Flow) (QueueOption, error) {
+	cs := map[config.FlowRef]Compaction{}
+	for _, f := range flows {
+		c, err := ParseCompaction(f.History)
+		if err != nil {
+			return nil, fmt.Errorf("flow %s: %v", f.ID, err)
+		}
+		cs[config.FlowRef{ID: f.ID, Ver: f.Ver}] = c
+	}
+	return func(q *Queue) {
+		for ref, c := range cs {
+			WithCompaction(ref, c)(q)
+		}
+	}, nil
+}
+
+// compactionOf returns the Compaction for runs of the flow. It must be called in the lock.
+func (q *Queue) compactionOf(flow config.FlowRef) Compaction {
+	if c, ok := q.compaction[flow]; ok {
+		return c
+	}
+	return KeepAll{}
+}
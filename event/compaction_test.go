@@ -0,0 +1,67 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestCompaction(t *testing.T) {
+	stream := []string{
+		"trigger.good",
+		TagNodeUpdate,
+		TagNodeUpdate,
+		"task.build.good",
+		TagNodeUpdate,
+		"task.test.good",
+		TagEndFlow,
+	}
+	// the queue adds the summary once the run ends
+	all := append(append([]string{}, stream...), TagRunSummary)
+	fix := []struct {
+		policy string
+		want   []string
+	}{
+		{"", all},
+		{"all", all},
+		{"milestones", []string{"trigger.good", "task.build.good", "task.test.good", TagEndFlow, TagRunSummary}},
+		{"last-3", []string{"task.test.good", TagEndFlow, TagRunSummary}},
+	}
+	for _, f := range fix {
+		flow := config.Flow{ID: "build", Ver: 1, History: f.policy}
+		opt, err := FlowCompaction([]*config.Flow{&flow})
+		if err != nil {
+			t.Fatal(f.policy, err)
+		}
+		q := NewQueue(opt)
+		ref := testRef(1)
+		for _, tag := range stream {
+			q.Publish(Event{RunRef: ref, Tag: tag})
+		}
+		// other flows keep everything
+		other := RunRef{FlowRef: config.FlowRef{ID: "deploy", Ver: 1}, Run: HostedIDRef{HostID: "h1", ID: 2}}
+		for _, tag := range stream {
+			q.Publish(Event{RunRef: other, Tag: tag})
+		}
+
+		got := q.History(ref)
+		if len(got) != len(f.want) {
+			t.Errorf("%s: wrong history length %d", f.policy, len(got))
+			continue
+		}
+		for i, e := range got {
+			if e.Tag != f.want[i] {
+				t.Errorf("%s: %d wanted %s got %s", f.policy, i, f.want[i], e.Tag)
+			}
+		}
+		if len(q.History(other)) != len(all) {
+			t.Errorf("%s: policy applied to the wrong flow", f.policy)
+		}
+	}
+
+	for _, bad := range []string{"some", "last-", "last-0", "last-x"} {
+		if _, err := ParseCompaction(bad); err == nil {
+			t.Error("expected an error for", bad)
+		}
+	}
+}
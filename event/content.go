@@ -0,0 +1,40 @@
+package event
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// content is what ContentHash hashes, json sorts the opts keys so the hash is stable
+type content struct {
+	Tag        string
+	SourceNode config.NodeRef
+	Opts       nt.Opts
+}
+
+// ContentHash returns a hash of what the event says - its tag, source node and opts less
+// any RunScopedOpts - ignoring its run, ID, time and hosts, so events with the same content
+// hash the same in any run. A node that is a pure function of its inputs can key a cache
+// of its results on it. It is empty if the opts can not be hashed, which must not be cached.
+func (e Event) ContentHash() string {
+	c := content{Tag: e.Tag, SourceNode: e.SourceNode}
+	if len(e.Opts) > 0 {
+		c.Opts = make(nt.Opts, len(e.Opts))
+		for k, v := range e.Opts {
+			c.Opts[k] = v
+		}
+		for _, k := range RunScopedOpts {
+			delete(c.Opts, k)
+		}
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
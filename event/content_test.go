@@ -0,0 +1,55 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestContentHash(t *testing.T) {
+	build := config.NodeRef{Class: "task", ID: "build"}
+	a := Event{
+		RunRef:     testRef(1),
+		SourceNode: build,
+		Tag:        "task.build.good",
+		ID:         1,
+		Time:       time.Date(2017, 11, 1, 12, 0, 0, 0, time.UTC),
+		Opts:       nt.Opts{"sha": "abc123", "env": []string{"A=1"}, "nested": nt.Opts{"x": 1, "y": 2}},
+	}
+	b := a.copy()
+	b.RunRef = testRef(2)
+	b.RunRef.ExecHost = "h2"
+	b.ID = 9
+	b.ExecID = 3
+	b.Time = a.Time.Add(time.Hour)
+	b.Opts = nt.Opts{"nested": nt.Opts{"y": 2, "x": 1}, "env": []string{"A=1"}, "sha": "abc123"}
+	b.SetCorrelation("c-1")
+
+	ha := a.ContentHash()
+	if ha == "" || ha != b.ContentHash() {
+		t.Error("the same content should hash the same", ha, b.ContentHash())
+	}
+
+	fix := []struct {
+		name   string
+		change func(e *Event)
+	}{
+		{"tag", func(e *Event) { e.Tag = "task.build.bad" }},
+		{"source", func(e *Event) { e.SourceNode.ID = "test" }},
+		{"opt value", func(e *Event) { e.Opts = nt.Opts{"sha": "def456"} }},
+		{"nested opt", func(e *Event) { e.Opts = deepOpts(a.Opts, nil); e.Opts["nested"].(nt.Opts)["x"] = 7 }},
+	}
+	for _, f := range fix {
+		c := a.copy()
+		f.change(&c)
+		if c.ContentHash() == ha {
+			t.Error("changing the", f.name, "should change the hash")
+		}
+	}
+
+	if (Event{Opts: nt.Opts{"ch": make(chan int)}}).ContentHash() != "" {
+		t.Error("unhashable opts should give no hash")
+	}
+}
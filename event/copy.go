@@ -0,0 +1,39 @@
+package event
+
+// CopyPolicy decides if an observer is sent its own copy of each event
+type CopyPolicy int
+
+const (
+	copyQueue CopyPolicy = iota // observers follow the queue policy
+
+	// CopyAlways sends each observer its own copy of the event, so observers can never
+	// affect each other. This is the default.
+	CopyAlways
+	// CopyShared sends observers one copy of the event between them, which they must
+	// treat as read only. Only use for trusted observers in the same process.
+	CopyShared
+)
+
+// WithCopyPolicy sets the default copy policy for observers on the queue
+func WithCopyPolicy(p CopyPolicy) QueueOption {
+	return func(q *Queue) {
+		q.copyPolicy = p
+	}
+}
+
+// WithObserverCopy overrides the queue copy policy for this observer, for example to
+// make sure observers provided by plugins are given their own copies.
+func WithObserverCopy(p CopyPolicy) RegisterOption {
+	return func(r *registration) {
+		r.copyPolicy = p
+	}
+}
+
+// shares returns true if the registration should be sent the shared copy of events
+// when the queue policy is p
+func (r *registration) shares(p CopyPolicy) bool {
+	if r.copyPolicy != copyQueue {
+		p = r.copyPolicy
+	}
+	return p == CopyShared
+}
@@ -0,0 +1,77 @@
+package event
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// optsObs keeps the opts map of the last event
+type optsObs struct {
+	wg   *sync.WaitGroup
+	opts nt.Opts
+}
+
+func (o *optsObs) Notify(e Event) {
+	o.opts = e.Opts
+	o.wg.Done()
+}
+
+func TestCopyPolicy(t *testing.T) {
+	q := NewQueue(WithCopyPolicy(CopyShared))
+	wg := &sync.WaitGroup{}
+	trustedA, trustedB := &optsObs{wg: wg}, &optsObs{wg: wg}
+	plugin := &optsObs{wg: wg}
+	q.Register(trustedA)
+	q.Register(trustedB)
+	q.RegisterWith(plugin, WithObserverCopy(CopyAlways))
+
+	wg.Add(3)
+	published := nt.Opts{"a": 1}
+	q.Publish(Event{Tag: "a", Opts: published})
+	wg.Wait()
+
+	ptr := func(o nt.Opts) uintptr {
+		return reflect.ValueOf(o).Pointer()
+	}
+	if ptr(trustedA.opts) != ptr(trustedB.opts) {
+		t.Error("trusted observers should share the event")
+	}
+	if ptr(trustedA.opts) == ptr(published) {
+		t.Error("even shared events should not share the publishers opts")
+	}
+	if ptr(plugin.opts) == ptr(trustedA.opts) {
+		t.Error("untrusted observer should have its own copy")
+	}
+	if plugin.opts["a"] != 1 {
+		t.Error("copy is missing the opts")
+	}
+}
+
+func benchmarkPublish(b *testing.B, p CopyPolicy) {
+	q := NewQueue(WithCopyPolicy(p), WithTailSize(1))
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		q.Register(&listener{what: func(Event) { wg.Done() }})
+	}
+	opts := nt.Opts{}
+	for i := 0; i < 20; i++ {
+		opts[string(rune('a'+i))] = i
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wg.Add(10)
+		q.Publish(Event{Tag: "a", Opts: opts})
+		wg.Wait()
+	}
+}
+
+func BenchmarkPublishCopyAlways(b *testing.B) {
+	benchmarkPublish(b, CopyAlways)
+}
+
+func BenchmarkPublishCopyShared(b *testing.B) {
+	benchmarkPublish(b, CopyShared)
+}
@@ -0,0 +1,25 @@
+package event
+
+import nt "github.com/floeit/floe/config/nodetype"
+
+// CorrelationKey is the Opts key of the correlation ID of an event
+const CorrelationKey = "correlation_id"
+
+// Correlation returns the correlation ID of the event, if it has one. A correlation ID is
+// set on the event that splits a flow into parallel branches and is carried on through the
+// events of the branches, so that the merge node they rejoin at can group them.
+func (e Event) Correlation() (string, bool) {
+	id, ok := e.Opts[CorrelationKey].(string)
+	if !ok || id == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// SetCorrelation sets the correlation ID of the event
+func (e *Event) SetCorrelation(id string) {
+	if e.Opts == nil {
+		e.Opts = nt.Opts{}
+	}
+	e.Opts[CorrelationKey] = id
+}
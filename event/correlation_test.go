@@ -0,0 +1,22 @@
+package event
+
+import (
+	"testing"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestCorrelation(t *testing.T) {
+	e := Event{}
+	if _, ok := e.Correlation(); ok {
+		t.Error("event without opts should have no correlation")
+	}
+	e.SetCorrelation("split-1")
+	if id, ok := e.Correlation(); !ok || id != "split-1" {
+		t.Error("wrong correlation", id, ok)
+	}
+	e = Event{Opts: nt.Opts{CorrelationKey: 12}}
+	if _, ok := e.Correlation(); ok {
+		t.Error("only string correlations are valid")
+	}
+}
@@ -0,0 +1,46 @@
+package event
+
+// Count returns how many of the events currently retained by the queue match, those in
+// the history of each run along with the general and trigger events still in the tail.
+// It is for diagnostics, match is called in the queue read lock so must be quick, must
+// not use the queue and must not change the events.
+func (q *Queue) Count(match func(Event) bool) int {
+	q.RLock()
+	defer q.RUnlock()
+	n := 0
+	for _, h := range q.history {
+		for _, e := range h.events {
+			if match(e) {
+				n++
+			}
+		}
+	}
+	// adopted events in the tail are already counted in their history
+	if q.tail != nil {
+		for _, e := range q.tail.last(len(q.tail.events)) {
+			if !e.RunRef.Adopted() && match(e) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// MatchFlow returns a match function for the events of any version of the flow
+func MatchFlow(id string) func(Event) bool {
+	return func(e Event) bool {
+		return e.RunRef.FlowRef.ID == id
+	}
+}
+
+// MatchAll returns a match function for events that match all of matches
+func MatchAll(matches ...func(Event) bool) func(Event) bool {
+	return func(e Event) bool {
+		for _, m := range matches {
+			if !m(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
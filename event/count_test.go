@@ -0,0 +1,39 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestCount(t *testing.T) {
+	q := NewQueue()
+	build := testRef(1)
+	deploy := RunRef{FlowRef: config.FlowRef{ID: "deploy", Ver: 1}, Run: HostedIDRef{HostID: "h1", ID: 2}}
+
+	q.Publish(NewTriggerEvent(build.FlowRef, "push", nil))
+	q.Publish(Event{RunRef: build, Tag: "task.build.good", Good: true})
+	q.Publish(Event{RunRef: build, Tag: "task.test.error"})
+	q.Publish(Event{RunRef: build, Tag: "task.lint.error"})
+	q.Publish(Event{RunRef: deploy, Tag: "task.push.error"})
+	q.Publish(Event{Tag: "sys.state", Good: true})
+
+	isErr := func(e Event) bool { return !e.Good }
+	fix := []struct {
+		name  string
+		match func(Event) bool
+		want  int
+	}{
+		{"all", func(Event) bool { return true }, 6},
+		{"errors", isErr, 4},
+		{"build errors", MatchAll(MatchFlow("build"), isErr), 3},
+		{"deploy errors", MatchAll(MatchFlow("deploy"), isErr), 1},
+		{"tag", MatchTag("task.build.good"), 1},
+		{"none", MatchFlow("other"), 0},
+	}
+	for _, f := range fix {
+		if n := q.Count(f.match); n != f.want {
+			t.Errorf("%s: wanted %d got %d", f.name, f.want, n)
+		}
+	}
+}
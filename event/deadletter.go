@@ -0,0 +1,28 @@
+package event
+
+// DeadLetterKey is the Opts key holding why a dead lettered event was not delivered
+const DeadLetterKey = "dead_letter"
+
+// WithDeadLetter sets the observer that is sent any events that could not be delivered
+// to their observer. Without one such events are dropped.
+func WithDeadLetter(o Observer) QueueOption {
+	return func(q *Queue) {
+		q.deadLetters = o
+	}
+}
+
+// deadLetter reports e as dropped, and sends it to the dead letter observer, if there is
+// one, annotated with the reason.
+func (q *Queue) deadLetter(e Event, drop DropReason, reason string) {
+	q.Lock()
+	q.metrics.deadLetters++
+	dl := q.deadLetters
+	q.Unlock()
+	q.reportDrop(e, drop, reason)
+	if dl == nil {
+		return
+	}
+	e = e.copy()
+	e.Opts[DeadLetterKey] = reason
+	dl.Notify(e)
+}
@@ -0,0 +1,43 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDeadlineExceeded is the dead letter reason for events a ContextObserver took longer
+// than its deadline to handle
+var ErrDeadlineExceeded = errors.New("observer deadline exceeded")
+
+// WithDeadline limits how long a ContextObserver can take handling each event. Once d
+// has passed the context given to NotifyCtx is cancelled, the event is dead lettered, and
+// counts as a failure to any breaker, and delivery moves on without waiting for it.
+func WithDeadline(d time.Duration) RegisterOption {
+	return func(r *registration) {
+		r.deadline = d
+	}
+}
+
+// notifyCtx calls NotifyCtx on co, returning ErrDeadlineExceeded if it does not return
+// within the deadline of the registration
+func (r *registration) notifyCtx(co ContextObserver, e Event) error {
+	if r.deadline <= 0 {
+		return co.NotifyCtx(context.Background(), e)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	timer := r.q.getClock().AfterFunc(r.deadline, cancel)
+	defer timer.Stop()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- co.NotifyCtx(ctx, e)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ErrDeadlineExceeded
+	}
+}
@@ -0,0 +1,58 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// sleeper handles events until its context is done, telling cancelled when it is
+type sleeper struct {
+	cancelled chan struct{}
+	quick     chan struct{}
+}
+
+func (s *sleeper) Notify(e Event) {}
+
+func (s *sleeper) NotifyCtx(ctx context.Context, e Event) error {
+	if e.Tag == "quick" {
+		close(s.quick)
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		close(s.cancelled)
+		return ctx.Err()
+	case <-time.After(10 * time.Second):
+		return nil
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	dead := make(chanObs, 10)
+	q := NewQueue(WithDeadLetter(dead))
+	s := &sleeper{cancelled: make(chan struct{}), quick: make(chan struct{})}
+	q.RegisterWith(s, WithDeadline(20*time.Millisecond), WithMaxConcurrent(1))
+
+	q.Publish(Event{Tag: "slow"})
+	q.Publish(Event{Tag: "quick"})
+
+	d := next(t, dead)
+	if d.Tag != "slow" || d.Opts[DeadLetterKey] != ErrDeadlineExceeded.Error() {
+		t.Error("slow event should be dead lettered", d)
+	}
+	select {
+	case <-s.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("context of the slow event was not cancelled")
+	}
+	// the worker moves on to the quick event
+	select {
+	case <-s.quick:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not move on from the slow event")
+	}
+	if len(dead) != 0 {
+		t.Error("quick event should not be dead lettered")
+	}
+}
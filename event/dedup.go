@@ -0,0 +1,59 @@
+package event
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Equal returns true if e and f are functionally the same event - the same run, source,
+// tag, goodness, attempt, outcome, level, priority, opts and artifacts. The ID, Causal,
+// ExecID, Time and hashes that differ for every publish are ignored.
+func (e Event) Equal(f Event) bool {
+	if !e.RunRef.Equal(f.RunRef) || e.RunRef.ExecHost != f.RunRef.ExecHost {
+		return false
+	}
+	if e.SourceNode != f.SourceNode || e.Tag != f.Tag || e.Good != f.Good {
+		return false
+	}
+	if e.Attempt != f.Attempt || e.Outcome != f.Outcome || e.Level != f.Level || e.Priority != f.Priority {
+		return false
+	}
+	if len(e.Artifacts) != len(f.Artifacts) {
+		return false
+	}
+	for i, a := range e.Artifacts {
+		if a != f.Artifacts[i] {
+			return false
+		}
+	}
+	if len(e.Opts) == 0 && len(f.Opts) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(e.Opts, f.Opts)
+}
+
+// dedup remembers the last event sent to an observer
+type dedup struct {
+	sync.Mutex
+	last *Event
+}
+
+// WithoutDuplicates suppresses any event that is Equal to the event sent to the observer
+// immediately before it. Only consecutive duplicates are dropped, any different event in
+// between means the next is sent.
+func WithoutDuplicates() RegisterOption {
+	return func(r *registration) {
+		r.dedup = &dedup{}
+	}
+}
+
+// repeat returns true if e is the same as the last event, remembering e otherwise
+func (d *dedup) repeat(e Event) bool {
+	d.Lock()
+	defer d.Unlock()
+	if d.last != nil && d.last.Equal(e) {
+		return true
+	}
+	d.last = &e
+	return false
+}
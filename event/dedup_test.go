@@ -0,0 +1,71 @@
+package event
+
+import (
+	"testing"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestEventEqual(t *testing.T) {
+	a := Event{RunRef: testRef(1), Tag: "task.build.good", Good: true, Opts: nt.Opts{"a": []string{"x"}}}
+	b := a
+	b.ID, b.Causal, b.ExecID = 10, 3, 2
+	if !a.Equal(b) {
+		t.Error("events differing by ID only should be equal")
+	}
+	b.Opts = nt.Opts{"a": []string{"y"}}
+	if a.Equal(b) {
+		t.Error("events with different opts should not be equal")
+	}
+	for i, change := range []func(*Event){
+		func(e *Event) { e.Attempt = 2 },
+		func(e *Event) { e.Outcome = "good" },
+		func(e *Event) { e.Level = LevelWarn },
+		func(e *Event) { e.Priority = 5 },
+	} {
+		c := a
+		change(&c)
+		if a.Equal(c) {
+			t.Errorf("change %d should make the events differ", i)
+		}
+	}
+	if !(Event{Tag: "a"}).Equal(Event{Tag: "a", Opts: nt.Opts{}}) {
+		t.Error("nil and empty opts should be equal")
+	}
+}
+
+func TestWithoutDuplicates(t *testing.T) {
+	q := NewQueue()
+	r := &recorder{}
+	q.RegisterWith(r, WithoutDuplicates())
+	all := &recorder{}
+	q.Register(all)
+
+	fix := []struct {
+		tag  string
+		sent bool
+	}{
+		{tag: "a", sent: true},
+		{tag: "a"},
+		{tag: "a"},
+		{tag: "b", sent: true},
+		{tag: "a", sent: true}, // not consecutive with the first a's
+		{tag: "a"},
+	}
+	for i, f := range fix {
+		r.Lock()
+		before := len(r.events)
+		r.Unlock()
+		if f.sent {
+			r.wg.Add(1)
+		}
+		all.publish(q, Event{Tag: f.tag, Opts: nt.Opts{"n": 1}})
+		r.wg.Wait()
+		r.Lock()
+		got := len(r.events) - before
+		r.Unlock()
+		if f.sent != (got == 1) {
+			t.Errorf("%d - %s sent should be %v", i, f.tag, f.sent)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// Delayed is the handle to an event scheduled to be published in the future.
+type Delayed struct {
+	mu       sync.Mutex
+	timer    Timer
+	canceled bool
+}
+
+// Cancel stops the event being published, it returns false if the event has already been
+// published or canceled.
+func (d *Delayed) Cancel() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.canceled {
+		return false
+	}
+	d.canceled = true
+	return d.timer.Stop()
+}
+
+// PublishAfter publishes e after duration d has elapsed on the queues clock. The event
+// is assigned its ID when it is actually published. The returned handle can cancel
+// the publish. Events for an adopted run are canceled automatically if the run ends
+// before they are published.
+func (q *Queue) PublishAfter(d time.Duration, e Event) *Delayed {
+	dl := &Delayed{}
+	clock := q.getClock()
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	dl.timer = clock.AfterFunc(d, func() {
+		dl.mu.Lock()
+		canceled := dl.canceled
+		dl.canceled = true // can no longer be canceled
+		dl.mu.Unlock()
+		if canceled {
+			return
+		}
+		q.forgetDelayed(e.RunRef, dl)
+		q.Publish(e)
+	})
+	q.trackDelayed(e.RunRef, dl)
+	return dl
+}
+
+// trackDelayed records dl as pending for the run so it can be canceled if the run ends
+func (q *Queue) trackDelayed(ref RunRef, dl *Delayed) {
+	if !ref.Adopted() {
+		return
+	}
+	q.Lock()
+	defer q.Unlock()
+	if q.delayed == nil {
+		q.delayed = map[runKey]map[*Delayed]bool{}
+	}
+	k := ref.key()
+	if q.delayed[k] == nil {
+		q.delayed[k] = map[*Delayed]bool{}
+	}
+	q.delayed[k][dl] = true
+}
+
+func (q *Queue) forgetDelayed(ref RunRef, dl *Delayed) {
+	if !ref.Adopted() {
+		return
+	}
+	q.Lock()
+	defer q.Unlock()
+	k := ref.key()
+	delete(q.delayed[k], dl)
+	if len(q.delayed[k]) == 0 {
+		delete(q.delayed, k)
+	}
+}
+
+// cancelDelayed cancels all pending delayed events for the run of the ending event e.
+// cancelDelayed must be called in the lock.
+func (q *Queue) cancelDelayed(e Event) {
+	if e.Tag != TagEndFlow || !e.RunRef.Adopted() {
+		return
+	}
+	k := e.RunRef.key()
+	for dl := range q.delayed[k] {
+		dl.Cancel()
+	}
+	delete(q.delayed, k)
+}
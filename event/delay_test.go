@@ -0,0 +1,86 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishAfter(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk))
+
+	got := make(chan Event, 2)
+	q.Register(&listener{what: func(e Event) {
+		got <- e
+	}})
+
+	q.PublishAfter(5*time.Minute, Event{Tag: "later"})
+	canceled := q.PublishAfter(time.Minute, Event{Tag: "never"})
+
+	// an event published now takes the first ID
+	q.Publish(Event{Tag: "now"})
+	if e := <-got; e.Tag != "now" || e.ID != 1 {
+		t.Fatal("wrong first event", e.Tag, e.ID)
+	}
+
+	if !canceled.Cancel() {
+		t.Error("should have canceled")
+	}
+	if canceled.Cancel() {
+		t.Error("second cancel should fail")
+	}
+
+	clk.Advance(4 * time.Minute)
+	select {
+	case e := <-got:
+		t.Fatal("event fired early", e.Tag)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clk.Advance(time.Minute)
+	select {
+	case e := <-got:
+		if e.Tag != "later" {
+			t.Error("wrong delayed event", e.Tag)
+		}
+		if e.ID != 2 {
+			t.Error("delayed event should get its ID when published", e.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("delayed event did not fire")
+	}
+}
+
+func TestPublishAfterCanceledByEnd(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk))
+	ref := testRef(1)
+
+	got := make(chan Event, 10)
+	q.Register(&listener{what: func(e Event) {
+		got <- e
+	}})
+
+	dl := q.PublishAfter(time.Minute, Event{RunRef: ref, Tag: "timeout"})
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow})
+
+	if dl.Cancel() {
+		t.Error("ending the run should already have canceled the delayed event")
+	}
+	if len(q.delayed) != 0 {
+		t.Error("pending delayed events not forgotten", len(q.delayed))
+	}
+
+	clk.Advance(2 * time.Minute)
+	timeout := time.After(50 * time.Millisecond)
+	for {
+		select {
+		case e := <-got:
+			if e.Tag == "timeout" {
+				t.Fatal("delayed event fired after the run ended")
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
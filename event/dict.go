@@ -0,0 +1,115 @@
+package event
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// DictEncoder encodes events for a single connection, such as a websocket to a slow
+// client, sending each of the often repeated strings - the ids of flows, hosts and nodes,
+// and tags - only once. The first event to use a string adds it to the dictionary, in
+// the d list of the frame, and every event refers to strings by their index in the
+// dictionary, starting at 1, with 0 for the empty string. The client must decode every
+// frame in order to keep its dictionary in step.
+type DictEncoder struct {
+	mu   sync.Mutex
+	dict map[string]int
+}
+
+// NewDictEncoder returns an encoder with an empty dictionary, so there must be one for
+// each connection
+func NewDictEncoder() *DictEncoder {
+	return &DictEncoder{dict: map[string]int{}}
+}
+
+// dictFrame is an event encoded by a DictEncoder, the ints are dictionary indexes
+type dictFrame struct {
+	Dict []string `json:"d,omitempty"` // strings added to the dictionary, in index order
+
+	Flow      int   `json:"f,omitempty"`
+	Ver       int   `json:"v,omitempty"`
+	RunHost   int   `json:"h,omitempty"`
+	Run       int64 `json:"r,omitempty"`
+	ExecHost  int   `json:"x,omitempty"`
+	Trigger   int   `json:"tr,omitempty"`
+	NodeClass int   `json:"c,omitempty"`
+	Node      int   `json:"n,omitempty"`
+	Tag       int   `json:"t,omitempty"`
+
+	Good     bool      `json:"g,omitempty"`
+	ID       int64     `json:"i,omitempty"`
+	Causal   int64     `json:"k,omitempty"`
+	Time     time.Time `json:"tm"`
+	ExecID   int64     `json:"e,omitempty"`
+	Attempt  int       `json:"at,omitempty"`
+	Outcome  int       `json:"oc,omitempty"`
+	Level    Level     `json:"lv,omitempty"`
+	Priority int       `json:"p,omitempty"`
+	Opts     nt.Opts   `json:"o,omitempty"`
+
+	Labels    map[string]string `json:"l,omitempty"`
+	Parent    *RunRef           `json:"pa,omitempty"`
+	Artifacts []ArtifactRef     `json:"a,omitempty"`
+	PrevHash  string            `json:"ph,omitempty"`
+	Hash      string            `json:"hs,omitempty"`
+}
+
+// Encode returns the frame for e, adding any new strings to the dictionary
+func (d *DictEncoder) Encode(e Event) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	f := dictFrame{
+		Ver:       e.RunRef.FlowRef.Ver,
+		Run:       e.RunRef.Run.ID,
+		Good:      e.Good,
+		ID:        e.ID,
+		Causal:    e.Causal,
+		Time:      e.Time,
+		ExecID:    e.ExecID,
+		Attempt:   e.Attempt,
+		Level:     e.Level,
+		Priority:  e.Priority,
+		Opts:      e.Opts,
+		Labels:    e.RunRef.Labels,
+		Parent:    e.RunRef.Parent,
+		Artifacts: e.Artifacts,
+		PrevHash:  e.PrevHash,
+		Hash:      e.Hash,
+	}
+	f.Flow = d.index(&f, e.RunRef.FlowRef.ID)
+	f.RunHost = d.index(&f, e.RunRef.Run.HostID)
+	f.ExecHost = d.index(&f, e.RunRef.ExecHost)
+	f.Trigger = d.index(&f, e.RunRef.Trigger)
+	f.NodeClass = d.index(&f, string(e.SourceNode.Class))
+	f.Node = d.index(&f, e.SourceNode.ID)
+	f.Tag = d.index(&f, e.Tag)
+	f.Outcome = d.index(&f, e.Outcome)
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		// the strings were never sent so must not be in the dictionary
+		for _, s := range f.Dict {
+			delete(d.dict, s)
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// index returns the dictionary index of s, adding it to the dictionary and the frame f
+// if it is new. index must be called in the lock.
+func (d *DictEncoder) index(f *dictFrame, s string) int {
+	if s == "" {
+		return 0
+	}
+	if i, ok := d.dict[s]; ok {
+		return i
+	}
+	i := len(d.dict) + 1
+	d.dict[s] = i
+	f.Dict = append(f.Dict, s)
+	return i
+}
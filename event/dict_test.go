@@ -0,0 +1,103 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// dictDecoder is what a client does to decode the frames of a DictEncoder
+type dictDecoder struct {
+	dict []string
+}
+
+func (d *dictDecoder) decode(b []byte) (Event, error) {
+	f := dictFrame{}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return Event{}, err
+	}
+	d.dict = append(d.dict, f.Dict...)
+	s := func(i int) string {
+		if i == 0 || i > len(d.dict) {
+			return ""
+		}
+		return d.dict[i-1]
+	}
+	return Event{
+		RunRef: RunRef{
+			FlowRef:  config.FlowRef{ID: s(f.Flow), Ver: f.Ver},
+			Run:      HostedIDRef{HostID: s(f.RunHost), ID: f.Run},
+			ExecHost: s(f.ExecHost),
+			Trigger:  s(f.Trigger),
+			Labels:   f.Labels,
+			Parent:   f.Parent,
+		},
+		SourceNode: config.NodeRef{Class: config.NodeClass(s(f.NodeClass)), ID: s(f.Node)},
+		Tag:        s(f.Tag),
+		Good:       f.Good,
+		ID:         f.ID,
+		Causal:     f.Causal,
+		Time:       f.Time,
+		ExecID:     f.ExecID,
+		Attempt:    f.Attempt,
+		Outcome:    s(f.Outcome),
+		Level:      f.Level,
+		Priority:   f.Priority,
+		Opts:       f.Opts,
+		Artifacts:  f.Artifacts,
+		PrevHash:   f.PrevHash,
+		Hash:       f.Hash,
+	}, nil
+}
+
+func TestDictEncoder(t *testing.T) {
+	ref := testRef(1)
+	ref.Trigger = "push"
+	start := time.Date(2017, 11, 1, 12, 0, 0, 0, time.UTC)
+	var stream []Event
+	for i := 1; i <= 50; i++ {
+		stream = append(stream, Event{
+			RunRef:     ref,
+			SourceNode: config.NodeRef{Class: "task", ID: fmt.Sprintf("step-%d", i%3)},
+			Tag:        TagNodeUpdate,
+			ID:         int64(i),
+			Time:       start.Add(time.Duration(i) * time.Second),
+			ExecID:     1,
+			Attempt:    i % 2,
+			Opts:       nt.Opts{"line": fmt.Sprintf("output line %d", i)},
+		})
+	}
+
+	enc := NewDictEncoder()
+	dec := &dictDecoder{}
+	plain, encoded := 0, 0
+	for _, e := range stream {
+		b, err := enc.Encode(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded += len(b)
+		p, _ := json.Marshal(e)
+		plain += len(p)
+
+		got, err := dec.decode(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.RunRef, e.RunRef) || got.SourceNode != e.SourceNode || got.Tag != e.Tag ||
+			got.ID != e.ID || !got.Time.Equal(e.Time) || got.Opts["line"] != e.Opts["line"] {
+			t.Fatalf("round trip failed\nwant %+v\ngot  %+v", e, got)
+		}
+	}
+	if encoded*2 > plain {
+		t.Errorf("encoded stream should be much smaller, %d bytes against %d", encoded, plain)
+	}
+	if len(dec.dict) != 8 {
+		t.Error("each repeated string should be sent once", dec.dict)
+	}
+}
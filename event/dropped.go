@@ -0,0 +1,72 @@
+package event
+
+// DropReason is why an event was dropped rather than published or delivered
+type DropReason int
+
+// The reasons an event is dropped
+const (
+	DropRejected    DropReason = iota + 1 // invalid or inconsistent with its run, so not published
+	DropRateLimit                         // its run exceeded the rate limit
+	DropLoop                              // its tag was looping in its run
+	DropQuota                             // its tenant exceeded its quota
+	DropDuplicate                         // it repeated the last event sent to the observer
+	DropPaused                            // the observer was paused with its buffer full
+	DropOverflow                          // the buffer group of the observer was full
+	DropCircuitOpen                       // the circuit breaker of the observer was open
+	DropExpired                           // the observer did not handle it before its deadline
+	DropFailed                            // the observer returned an error
+)
+
+var dropReasons = map[DropReason]string{
+	DropRejected:    "rejected",
+	DropRateLimit:   "rate_limit",
+	DropLoop:        "loop",
+	DropQuota:       "quota",
+	DropDuplicate:   "duplicate",
+	DropPaused:      "paused",
+	DropOverflow:    "overflow",
+	DropCircuitOpen: "circuit_open",
+	DropExpired:     "expired",
+	DropFailed:      "failed",
+}
+
+func (r DropReason) String() string {
+	if s, ok := dropReasons[r]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// DroppedEvent is an event that was dropped, why, and for observer errors the error
+type DroppedEvent struct {
+	Event  Event
+	Reason DropReason
+	Detail string
+}
+
+// WithDropHook sets f to be called with every event the queue drops, whether it was not
+// published or could not be delivered to an observer, so there is one place to see them
+// all. f is called outside the lock so may use the queue, but should be quick.
+func WithDropHook(f func(DroppedEvent)) QueueOption {
+	return func(q *Queue) {
+		q.dropHook = f
+	}
+}
+
+// dropped counts e as not published for reason, returning the delivery reporting it, along
+// with any notice to publish. dropped must be called in the lock.
+func (q *Queue) dropped(e Event, reason DropReason, notice *Event) delivery {
+	q.metrics.drop(reason.String())
+	return delivery{e: e, drop: reason, notice: notice, dropHook: q.dropHook}
+}
+
+// reportDrop sends e to the drop hook, if there is one. It must be called outside the lock.
+func (q *Queue) reportDrop(e Event, reason DropReason, detail string) {
+	q.RLock()
+	f := q.dropHook
+	q.RUnlock()
+	if f == nil {
+		return
+	}
+	f(DroppedEvent{Event: e.copy(), Reason: reason, Detail: detail})
+}
@@ -0,0 +1,62 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDropHook(t *testing.T) {
+	drops := make(chan DroppedEvent, 20)
+	q := NewQueue(WithStrict(), WithRunRateLimit(0.001, 2), WithDropHook(func(d DroppedEvent) {
+		drops <- d
+	}))
+	wait := func(want DropReason, tag string) DroppedEvent {
+		t.Helper()
+		select {
+		case d := <-drops:
+			if d.Reason != want || d.Event.Tag != tag {
+				t.Errorf("wanted %s drop of %s got %s of %s", want, tag, d.Reason, d.Event.Tag)
+			}
+			return d
+		case <-time.After(time.Second):
+			t.Fatalf("no %s drop reported", want)
+		}
+		return DroppedEvent{}
+	}
+
+	// dropped on publish
+	q.Publish(Event{RunRef: testRef(1), Tag: "task.my build.good"})
+	wait(DropRejected, "task.my build.good")
+	for _, tag := range []string{"task.a.good", "task.b.good", "task.c.good"} {
+		q.Publish(Event{RunRef: testRef(2), Tag: tag})
+	}
+	wait(DropRateLimit, "task.c.good")
+
+	// dropped on delivery
+	c := make(chanObs, 10)
+	q.RegisterWith(c, WithTags("dup"), WithoutDuplicates())
+	q.Publish(Event{Tag: "dup"})
+	next(t, c)
+	q.Publish(Event{Tag: "dup"})
+	wait(DropDuplicate, "dup")
+
+	p := make(chanObs, 10)
+	q.RegisterWith(p, WithTags("paused"))
+	q.PauseObserver(p, 0)
+	q.Publish(Event{Tag: "paused"})
+	wait(DropPaused, "paused")
+
+	s := &sleeper{cancelled: make(chan struct{}), quick: make(chan struct{})}
+	q.RegisterWith(s, WithTags("slow"), WithDeadline(10*time.Millisecond))
+	q.Publish(Event{Tag: "slow"})
+	if d := wait(DropExpired, "slow"); d.Detail != ErrDeadlineExceeded.Error() {
+		t.Error("expired drop should carry the error", d.Detail)
+	}
+
+	if len(drops) != 0 {
+		t.Error("unexpected drops", len(drops))
+	}
+	if DropCircuitOpen.String() != "circuit_open" || DropReason(99).String() != "unknown" {
+		t.Error("bad reason strings")
+	}
+}
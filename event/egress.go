@@ -0,0 +1,74 @@
+package event
+
+import nt "github.com/floeit/floe/config/nodetype"
+
+// AllowOpts returns a copy of e with only the opts whose keys are in allow. It is used
+// to strip internal opts from events leaving the host.
+func (e Event) AllowOpts(allow map[string]bool) Event {
+	ne := e
+	ne.Opts = nt.Opts{}
+	for k, v := range e.Opts {
+		if allow[k] {
+			ne.Opts[k] = v
+		}
+	}
+	return ne
+}
+
+// allowSet returns the set of keys, or nil if there are none to mean all are allowed
+func allowSet(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+	s := map[string]bool{}
+	for _, k := range keys {
+		s[k] = true
+	}
+	return s
+}
+
+// egress is an observer that projects the opts of events before passing them on
+type egress struct {
+	o     Observer
+	allow map[string]bool
+}
+
+// EgressObserver wraps an observer that sends events off this host, so it is only sent
+// the opts with the given keys. Local observers are unaffected and see all opts.
+func EgressObserver(o Observer, keys ...string) Observer {
+	return egress{o: o, allow: allowSet(keys)}
+}
+
+func (g egress) Notify(e Event) {
+	if g.allow != nil {
+		e = e.AllowOpts(g.allow)
+	}
+	g.o.Notify(e)
+}
+
+// BrokerAllowOpts limits the opts sent to the broker to those with the given keys
+func BrokerAllowOpts(keys ...string) BrokerOption {
+	return func(b *Broker) {
+		b.allow = allowSet(keys)
+	}
+}
+
+// BrokerRemapTags renames the tag of each event sent to the broker with f, and so the
+// subject it is sent to, to match the naming of an external system. Events delivered on
+// this host keep their tags.
+func BrokerRemapTags(f func(tag string) string) BrokerOption {
+	return func(b *Broker) {
+		b.remap = f
+	}
+}
+
+// TagMap returns a tag remapping function for BrokerRemapTags renaming the tags in m,
+// leaving any others unchanged.
+func TagMap(m map[string]string) func(string) string {
+	return func(tag string) string {
+		if to, ok := m[tag]; ok {
+			return to
+		}
+		return tag
+	}
+}
@@ -0,0 +1,83 @@
+package event
+
+import (
+	"testing"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func egressOpts() nt.Opts {
+	return nt.Opts{
+		"branch":    "master",
+		"sha":       "abc123",
+		"workspace": "/var/floe/ws/1",
+		"cache":     "big",
+	}
+}
+
+func TestEgressObserver(t *testing.T) {
+	q := NewQueue()
+	local := &recorder{}
+	q.Register(local)
+	out := &recorder{}
+	q.Register(EgressObserver(out, "branch"))
+
+	opts := egressOpts()
+	local.wg.Add(1)
+	oe := out.publish(q, Event{Tag: "task.build.good", Opts: opts})
+	local.wg.Wait()
+
+	if len(local.events[0].Opts) != 4 {
+		t.Error("local observers should see all opts", local.events[0].Opts)
+	}
+	if len(oe.Opts) != 1 || oe.Opts["branch"] != "master" {
+		t.Error("egress observer should only see the allowed opts", oe.Opts)
+	}
+	if len(opts) != 4 {
+		t.Error("publishers opts changed")
+	}
+}
+
+func TestBrokerAllowOpts(t *testing.T) {
+	fb := &fakeBroker{}
+	b := NewBroker(fb, JSONCodec{}, BrokerAllowOpts("branch", "sha"))
+	b.Notify(Event{Tag: "task.build.good", Opts: egressOpts()})
+
+	got, err := JSONCodec{}.Unmarshal(fb.payloads[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Opts) != 2 || got.Opts["sha"] != "abc123" || got.Opts["branch"] != "master" {
+		t.Error("broker should only send the allowed opts", got.Opts)
+	}
+}
+
+func TestBrokerRemapTags(t *testing.T) {
+	fb := &fakeBroker{}
+	b := NewBroker(fb, JSONCodec{}, BrokerRemapTags(TagMap(map[string]string{
+		"task.build.good": "ci.build.success",
+	})))
+	events := []Event{
+		{Tag: "task.build.good", Good: true},
+		{Tag: "task.test.bad"},
+	}
+	for _, e := range events {
+		b.Notify(e)
+	}
+
+	if events[0].Tag != "task.build.good" {
+		t.Error("the event delivered locally should keep its tag", events[0].Tag)
+	}
+	if len(fb.payloads) != 2 {
+		t.Fatal("expected two events sent", len(fb.payloads))
+	}
+	for i, want := range []string{"ci.build.success", "task.test.bad"} {
+		got, err := JSONCodec{}.Unmarshal(fb.payloads[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Tag != want || fb.subjects[i] != "floe.na."+want {
+			t.Errorf("got %s on %s wanted %s", got.Tag, fb.subjects[i], want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package event
+
+import (
+	"sync"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// MetaKey is the Opts key enriched events carry their display metadata in
+const MetaKey = "meta"
+
+// MetaSource provides the human friendly names of flows and nodes
+type MetaSource interface {
+	FlowName(flow config.FlowRef) (string, bool)
+	NodeName(flow config.FlowRef, node config.NodeRef) (string, bool)
+}
+
+// ConfigMeta is a MetaSource backed by the config
+type ConfigMeta struct {
+	Config *config.Config
+}
+
+// FlowName satisfies MetaSource
+func (c ConfigMeta) FlowName(flow config.FlowRef) (string, bool) {
+	f := c.Config.Flow(flow)
+	if f == nil {
+		return "", false
+	}
+	return f.Name, true
+}
+
+// NodeName satisfies MetaSource
+func (c ConfigMeta) NodeName(flow config.FlowRef, node config.NodeRef) (string, bool) {
+	f := c.Config.Flow(flow)
+	if f == nil {
+		return "", false
+	}
+	if node.Class == config.NcTrigger {
+		for _, t := range f.Triggers {
+			if t.ID == node.ID {
+				return t.Name, true
+			}
+		}
+		return "", false
+	}
+	n := f.Node(node.ID)
+	if n == nil {
+		return "", false
+	}
+	return n.Name, true
+}
+
+// NodeType satisfies NodeTypes
+func (c ConfigMeta) NodeType(flow config.FlowRef, node config.NodeRef) nt.NodeType {
+	f := c.Config.Flow(flow)
+	if f == nil {
+		return nil
+	}
+	if node.Class == config.NcTrigger {
+		for _, t := range f.Triggers {
+			if t.ID == node.ID {
+				return nt.GetNodeType(t.Type)
+			}
+		}
+		return nil
+	}
+	n := f.Node(node.ID)
+	if n == nil {
+		return nil
+	}
+	return nt.GetNodeType(n.Type)
+}
+
+type metaKey struct {
+	flow config.FlowRef
+	node config.NodeRef
+}
+
+// enricher adds display metadata to events, caching the lookups
+type enricher struct {
+	mu    sync.Mutex
+	src   MetaSource
+	cache map[metaKey]map[string]interface{}
+}
+
+// WithEnricher adds the flow and node names from src to the MetaKey opts of each
+// event before it is sent to the observers.
+func WithEnricher(src MetaSource) QueueOption {
+	return func(q *Queue) {
+		q.enricher = &enricher{
+			src:   src,
+			cache: map[metaKey]map[string]interface{}{},
+		}
+	}
+}
+
+// enrich must be called on an event that does not share its Opts
+func (en *enricher) enrich(e *Event) {
+	if !e.RunRef.FlowRef.NonZero() {
+		return
+	}
+	k := metaKey{flow: e.RunRef.FlowRef, node: e.SourceNode}
+	en.mu.Lock()
+	m, ok := en.cache[k]
+	if !ok {
+		m = map[string]interface{}{}
+		if name, ok := en.src.FlowName(k.flow); ok {
+			m["flow_name"] = name
+		}
+		if e.SourceNode.ID != "" {
+			if name, ok := en.src.NodeName(k.flow, k.node); ok {
+				m["node_name"] = name
+				m["node_class"] = string(k.node.Class)
+			}
+		}
+		en.cache[k] = m
+	}
+	en.mu.Unlock()
+	if len(m) == 0 {
+		return
+	}
+	meta := make(map[string]interface{}, len(m))
+	for mk, mv := range m {
+		meta[mk] = mv
+	}
+	e.Opts[MetaKey] = meta
+}
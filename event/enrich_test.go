@@ -0,0 +1,107 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+type fakeMeta struct {
+	lookups int
+}
+
+func (f *fakeMeta) FlowName(flow config.FlowRef) (string, bool) {
+	f.lookups++
+	if flow.ID != "build" {
+		return "", false
+	}
+	return "Build Project", true
+}
+
+func (f *fakeMeta) NodeName(flow config.FlowRef, node config.NodeRef) (string, bool) {
+	f.lookups++
+	if node.ID != "checkout" {
+		return "", false
+	}
+	return "Check Out", true
+}
+
+func TestEnricher(t *testing.T) {
+	fm := &fakeMeta{}
+	q := NewQueue(WithEnricher(fm))
+	r := &recorder{}
+	q.Register(r)
+
+	ref := testRef(1)
+	node := config.NodeRef{Class: "task", ID: "checkout"}
+	opts := nt.Opts{"branch": "master"}
+	e := r.publish(q, Event{RunRef: ref, SourceNode: node, Tag: "task.checkout.good", Opts: opts})
+
+	meta, ok := e.Opts[MetaKey].(map[string]interface{})
+	if !ok {
+		t.Fatal("no meta data on the event")
+	}
+	if meta["flow_name"] != "Build Project" || meta["node_name"] != "Check Out" || meta["node_class"] != "task" {
+		t.Error("bad meta data", meta)
+	}
+	if e.Opts["branch"] != "master" {
+		t.Error("lost the original opts")
+	}
+	if _, ok := opts[MetaKey]; ok {
+		t.Error("the publishers opts should not have been modified")
+	}
+
+	// the second lookup is cached
+	lookups := fm.lookups
+	r.publish(q, Event{RunRef: ref, SourceNode: node, Tag: "task.checkout.good"})
+	if fm.lookups != lookups {
+		t.Error("lookup was not cached")
+	}
+
+	// an unknown node still gets the flow name
+	e = r.publish(q, Event{RunRef: ref, SourceNode: config.NodeRef{Class: "task", ID: "nope"}, Tag: "x"})
+	meta = e.Opts[MetaKey].(map[string]interface{})
+	if meta["flow_name"] != "Build Project" || meta["node_name"] != nil {
+		t.Error("bad unknown node meta", meta)
+	}
+
+	// general events are not enriched
+	e = r.publish(q, Event{Tag: "inbound.push"})
+	if _, ok := e.Opts[MetaKey]; ok {
+		t.Error("general event should not be enriched")
+	}
+}
+
+func TestConfigMeta(t *testing.T) {
+	c, err := config.ParseYAML([]byte(`
+flows:
+  - id: build
+    ver: 1
+    name: Build It
+    triggers:
+      - name: Push Hook
+        type: data
+    tasks:
+      - name: Check Out
+        listen: trigger.good
+        type: end
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := ConfigMeta{Config: c}
+	fr := config.FlowRef{ID: "build", Ver: 1}
+	if n, _ := cm.FlowName(fr); n != "Build It" {
+		t.Error("bad flow name", n)
+	}
+	if n, _ := cm.NodeName(fr, config.NodeRef{Class: config.NcTrigger, ID: "push-hook"}); n != "Push Hook" {
+		t.Error("bad trigger name", n)
+	}
+	if n, _ := cm.NodeName(fr, config.NodeRef{Class: config.NcTask, ID: "check-out"}); n != "Check Out" {
+		t.Error("bad task name", n)
+	}
+	if _, ok := cm.NodeName(config.FlowRef{ID: "nope"}, config.NodeRef{}); ok {
+		t.Error("unknown flow should not be found")
+	}
+}
@@ -3,15 +3,27 @@ package event
 import (
 	"fmt"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/floeit/floe/config"
 	nt "github.com/floeit/floe/config/nodetype"
-	"github.com/floeit/floe/log"
 )
 
 const sysPrefix = "sys." // all internal events that nodes can not see
 
+// timeFormat is how event times are shown in logs, milliseconds are enough to correlate
+// with other logs
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// system event tags the queue is aware of
+const (
+	TagEndFlow      = "sys.end.all"       // a run has ended
+	TagRunThrottled = "sys.run.throttled" // a run has had events dropped due to the rate limit
+	TagRunLoop      = "sys.run.loop"      // a run has had a looping tag broken
+
+	TagQuotaExceeded = "sys.quota.exceeded" // a tenant has had events dropped for exceeding its quota
+)
+
 // HostedIDRef is any ID unique within the scope of the host that created it.
 type HostedIDRef struct {
 	HostID string
@@ -48,6 +60,17 @@ type RunRef struct {
 	// ExecHost is the host that is actually executing, or executed this event,
 	// use in conjunction with Run to find the active and archived run
 	ExecHost string
+
+	// Labels are optional key values describing the run, such as the environment.
+	// Observers can select runs by label with RegisterSelector.
+	Labels map[string]string `json:",omitempty"`
+
+	// Trigger is the type of trigger that started the run, such as a timer or push,
+	// it is set when the run is adopted and carried on all events of the run
+	Trigger string `json:",omitempty"`
+
+	// Parent is the run that spawned this one as a sub-flow, nil for top level runs
+	Parent *RunRef `json:",omitempty"`
 }
 
 func (r RunRef) String() string {
@@ -59,6 +82,26 @@ func (r RunRef) Equal(s RunRef) bool {
 	return r.FlowRef.Equal(s.FlowRef) && r.Run.Equal(s.Run)
 }
 
+// Child returns the reference for a new run of childFlow spawned by the run r. It is not
+// yet adopted, so the hub assigns it a run ID of its own, but keeps r as its Parent.
+func (r RunRef) Child(childFlow config.FlowRef) RunRef {
+	p := r.copy()
+	return RunRef{
+		FlowRef: childFlow,
+		Parent:  &p,
+	}
+}
+
+// IsChildOf returns true if r was spawned directly by the run p
+func (r RunRef) IsChildOf(p RunRef) bool {
+	return r.Parent != nil && r.Parent.Equal(p)
+}
+
+// TriggerType returns the type of trigger that started the run, empty if not yet adopted
+func (r RunRef) TriggerType() string {
+	return r.Trigger
+}
+
 // Adopted means that this RunRef has been added to a pending list and been assigned a
 // unique run ID
 func (r RunRef) Adopted() bool {
@@ -68,6 +111,12 @@ func (r RunRef) Adopted() bool {
 	return true
 }
 
+// IsZero returns true if r does not refer to any flow or run, which is the case for
+// general events that are only routed to triggers.
+func (r RunRef) IsZero() bool {
+	return r.FlowRef.ID == "" && r.FlowRef.Ver == 0 && r.Run.HostID == "" && r.Run.ID == 0 && r.ExecHost == ""
+}
+
 // Observer defines the interface for observers.
 type Observer interface {
 	Notify(e Event)
@@ -94,74 +143,135 @@ type Event struct {
 	// A flow initiating trigger will have ID 1.
 	ID int64
 
+	// Causal is the Lamport timestamp within the run, which unlike ID is comparable
+	// between events published on different hosts.
+	Causal int64
+
+	// Time is when the event was published
+	Time time.Time
+
+	// ExecID identifies a single execution of the SourceNode, it is set on the node
+	// start event and all update and end events of that execution, so retries of the same
+	// node in a run can be told apart.
+	ExecID int64
+
+	// Attempt is which execution of the SourceNode in the run this event is from, starting
+	// at 1 and going up each time the node is retried, so the attempts can be told apart.
+	// It is set by the executor, 0 means the event is not from a node execution.
+	Attempt int `json:",omitempty"`
+
+	// Outcome is set on the event that ends an execution of the SourceNode, to the good,
+	// bad or error sub tag it ended with, whatever the outcome tag template of the node
+	// made of its Tag. It is set by the executor, and by SetGood and SetError.
+	Outcome string `json:",omitempty"`
+
+	// Level is how serious the event is, when not set it follows from Good, see Severity
+	Level Level `json:",omitempty"`
+
+	// Priority is set by the publisher to have the event delivered ahead of any buffered
+	// events of lower priority, the default is 0.
+	Priority int `json:",omitempty"`
+
 	// Opts - some optional data in the event
 	Opts nt.Opts
+
+	// Artifacts reference any large outputs, such as build artifacts, stored outside the
+	// event for observers to fetch if they need them.
+	Artifacts []ArtifactRef `json:",omitempty"`
+
+	// PrevHash and Hash chain the event to the previous one when the queue is hash
+	// chained, see WithHashChain
+	PrevHash string `json:",omitempty"`
+	Hash     string `json:",omitempty"`
 }
 
-// copy makes a copy without sharing the underlying Opts aps.
-// Any pointers in the opts map (there should not be) will share memory
+// copy makes a copy without sharing the underlying Opts maps, or the maps and slices
+// nested in them. Any opts that can not be copied safely, such as pointers, are replaced
+// by a placeholder, see copyWarn.
 func (e Event) copy() Event {
+	return e.copyWarn(nil)
+}
+
+// copyWarn is copy reporting each opt replaced by a placeholder to warn, if not nil
+func (e Event) copyWarn(warn func(key string, v interface{})) Event {
 	newE := e
 	// break the common map memory link
-	newE.Opts = nt.Opts{}
-	for k, v := range e.Opts {
-		newE.Opts[k] = v
+	newE.Opts = deepOpts(e.Opts, warn)
+	if newE.Opts == nil {
+		newE.Opts = nt.Opts{}
+	}
+	if e.Artifacts != nil {
+		newE.Artifacts = append([]ArtifactRef(nil), e.Artifacts...)
 	}
+	newE.RunRef = e.RunRef.copy()
 	return newE
 }
 
-// SetGood sets this event as a good event
-func (e *Event) SetGood() {
+// copy makes a copy of r not sharing its labels or parent
+func (r RunRef) copy() RunRef {
+	newR := r
+	if r.Labels != nil {
+		newR.Labels = make(map[string]string, len(r.Labels))
+		for k, v := range r.Labels {
+			newR.Labels[k] = v
+		}
+	}
+	if r.Parent != nil {
+		p := r.Parent.copy()
+		newR.Parent = &p
+	}
+	return newR
+}
+
+// SetGood sets this event as a good event, given the outcome tag template of the source
+// node, if it has one, as described by config.OutcomeTag
+func (e *Event) SetGood(tmpl ...string) {
 	e.Good = true
-	e.Tag = fmt.Sprintf("%s.%s.good", e.SourceNode.Class, e.SourceNode.ID)
+	e.Outcome = config.SubTagGood
+	e.Tag = NormalizeTag(config.OutcomeTag(firstString(tmpl), e.SourceNode, e.Outcome))
 }
 
-// IsSystem returns true if the event is a internal system event
-func (e *Event) IsSystem() bool {
-	if len(e.Tag) < 3 {
-		return false
-	}
-	return strings.HasPrefix(e.Tag, sysPrefix)
+// SetError sets this event as the error event of its source node, given the outcome tag
+// template of the node, if it has one
+func (e *Event) SetError(tmpl ...string) {
+	e.Good = false
+	e.Outcome = config.SubTagError
+	e.Tag = NormalizeTag(config.OutcomeTag(firstString(tmpl), e.SourceNode, e.Outcome))
 }
 
-// Queue is not strictly a queue, it just distributes all events to the observers
-type Queue struct {
-	sync.RWMutex
+func firstString(s []string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
 
-	idCounter int64
-	// observers are any entities that care about events emitted from the queue
-	observers []Observer
+// String returns a compact single line description of the event for logs
+func (e Event) String() string {
+	good := "bad"
+	if e.Good {
+		good = "good"
+	}
+	return fmt.Sprintf("%s <%s-ev:%d> %s %s from: %s",
+		e.Time.UTC().Format(timeFormat), e.RunRef, e.ID, e.Tag, good, e.SourceNode)
 }
 
-// Register registers an observer to this q
-func (q *Queue) Register(o Observer) {
-	q.observers = append(q.observers, o)
+// logFields returns the key=value fields added to log lines about the event, so that
+// log lines from every host involved in a run can be correlated by the run origin.
+func (e Event) logFields() string {
+	return fmt.Sprintf("run_origin=%s exec_host=%s", e.RunRef.Run, e.RunRef.ExecHost)
 }
 
-// Publish sends an event to all the observers
-func (q *Queue) Publish(e Event) {
-	q.Lock()
-	// grab the next event ID
-	q.idCounter++
-	e.ID = q.idCounter
-	if e.Opts == nil {
-		e.Opts = nt.Opts{}
-	}
-	q.Unlock()
-
-	// node updates can be noisy - an event is issued for every line of output
-	// if e.Tag != "sys.node.update" {
-	// for helpfulness indicate if this event was issued by an already adopted flow
-	isTrig := " (trigger)"
-	if e.RunRef.Adopted() {
-		isTrig = ""
+// IsSystem returns true if the event is a internal system event
+func (e *Event) IsSystem() bool {
+	if len(e.Tag) < 3 {
+		return false
 	}
-	log.Debugf("<%s-ev:%d> - queue publish type:<%s>%s from: %s", e.RunRef, e.ID, e.Tag, isTrig, e.SourceNode)
-	// }
+	return strings.HasPrefix(e.Tag, sysPrefix)
+}
 
-	// and notify all observers - in background goroutines
-	for _, o := range q.observers {
-		// send separate copies to each observer to avoid any races
-		go o.Notify(e.copy())
-	}
+// TriggerType returns the type of trigger that started the run of the event, so nodes can
+// act differently for example on a timer than on a push
+func (e Event) TriggerType() string {
+	return e.RunRef.TriggerType()
 }
@@ -1,6 +1,11 @@
 package event
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+)
 
 type listener struct {
 	what func(e Event)
@@ -93,4 +98,101 @@ func BenchmarkRunRefPBV(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		notnop(r)
 	}
-}
\ No newline at end of file
+}
+func TestTail(t *testing.T) {
+	q := &Queue{}
+	if len(q.Tail(10)) != 0 {
+		t.Error("empty queue should have no tail")
+	}
+	total := defaultTailSize + 10
+	for i := 0; i < total; i++ {
+		q.Publish(Event{Tag: "foo"})
+	}
+	tail := q.Tail(5)
+	if len(tail) != 5 {
+		t.Fatal("wrong tail length", len(tail))
+	}
+	for i, e := range tail {
+		if e.ID != int64(total-4+i) {
+			t.Errorf("%d - wrong event in tail %d", i, e.ID)
+		}
+	}
+	// asking for more than the ring returns the whole ring
+	tail = q.Tail(total)
+	if len(tail) != defaultTailSize {
+		t.Fatal("tail should be bounded by the ring", len(tail))
+	}
+	if tail[0].ID != 11 || tail[len(tail)-1].ID != int64(total) {
+		t.Error("wrong full window", tail[0].ID, tail[len(tail)-1].ID)
+	}
+}
+
+func TestEventString(t *testing.T) {
+	fix := []struct {
+		e   Event
+		exp string
+	}{
+		{
+			e: Event{
+				RunRef:     testRef(3),
+				SourceNode: config.NodeRef{Class: "task", ID: "build"},
+				ID:         12,
+				Tag:        "task.build.good",
+				Good:       true,
+				Time:       time.Date(2017, 11, 1, 12, 0, 1, 250000000, time.UTC),
+			},
+			exp: "2017-11-01T12:00:01.250Z <runref_build-1_h1-3-ev:12> task.build.good good from: task.build",
+		},
+		{
+			e: Event{
+				ID:   1,
+				Tag:  "inbound.push",
+				Time: time.Date(2017, 11, 1, 13, 0, 0, 0, time.FixedZone("X", 3600)),
+			},
+			exp: "2017-11-01T12:00:00.000Z <runref_na_na-ev:1> inbound.push bad from: .",
+		},
+	}
+	for i, f := range fix {
+		if s := f.e.String(); s != f.exp {
+			t.Errorf("%d - got\n%s\nwanted\n%s", i, s, f.exp)
+		}
+	}
+}
+
+func TestLogFields(t *testing.T) {
+	// the run started on h1 but this event is from h2 executing it
+	ref := testRef(3)
+	ref.ExecHost = "h2"
+	f := Event{RunRef: ref}.logFields()
+	if f != "run_origin=h1-3 exec_host=h2" {
+		t.Error("log fields should have the run origin", f)
+	}
+}
+
+func TestChildRunRef(t *testing.T) {
+	parent := testRef(1)
+	parent.Labels = map[string]string{"env": "prod"}
+	child := parent.Child(config.FlowRef{ID: "deploy", Ver: 2})
+
+	if child.Adopted() || child.FlowRef.ID != "deploy" {
+		t.Error("child should be a new unadopted run of the child flow", child)
+	}
+	if !child.IsChildOf(parent) || parent.IsChildOf(child) {
+		t.Error("bad parent link", child.Parent)
+	}
+
+	// the child adopts a run id of its own and keeps its parent
+	child.Run = HostedIDRef{HostID: "h2", ID: 1}
+	if child.Equal(parent) || !child.IsChildOf(parent) {
+		t.Error("adopted child should be its own run of the parent", child)
+	}
+
+	// copies of events do not share the parent
+	e := Event{RunRef: child}
+	c := e.copy()
+	c.RunRef.Parent.Labels["env"] = "dev"
+	c.RunRef.Parent.Run.ID = 9
+	if e.RunRef.Parent.Labels["env"] != "prod" || !e.RunRef.IsChildOf(parent) {
+		t.Error("event copy shares the parent", e.RunRef.Parent)
+	}
+}
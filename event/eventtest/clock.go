@@ -0,0 +1,12 @@
+// Package eventtest has helpers for testing code that uses the event queue.
+package eventtest
+
+import "github.com/floeit/floe/event"
+
+// FakeClock is an event.Clock that only moves when advanced
+type FakeClock = event.FakeClock
+
+// NewFakeClock returns a FakeClock set to a fixed time
+func NewFakeClock() *FakeClock {
+	return event.NewFakeClock()
+}
@@ -0,0 +1,26 @@
+package eventtest
+
+import (
+	"time"
+
+	"github.com/floeit/floe/event"
+)
+
+type delayObserver struct {
+	inner event.Observer
+	d     time.Duration
+	clock event.Clock
+}
+
+// DelayObserver returns an observer that passes each event on to inner only after d has
+// elapsed on the clock, to model a lagging host in cluster tests. With a FakeClock the
+// delivery happens in the call to Advance.
+func DelayObserver(inner event.Observer, d time.Duration, clock event.Clock) event.Observer {
+	return delayObserver{inner: inner, d: d, clock: clock}
+}
+
+func (o delayObserver) Notify(e event.Event) {
+	o.clock.AfterFunc(o.d, func() {
+		o.inner.Notify(e)
+	})
+}
@@ -0,0 +1,48 @@
+package eventtest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/event"
+)
+
+type collect struct {
+	sync.Mutex
+	got []event.Event
+}
+
+func (c *collect) Notify(e event.Event) {
+	c.Lock()
+	defer c.Unlock()
+	c.got = append(c.got, e)
+}
+
+func (c *collect) len() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.got)
+}
+
+func TestDelayObserver(t *testing.T) {
+	clk := NewFakeClock()
+	c := &collect{}
+	o := DelayObserver(c, 50*time.Millisecond, clk)
+
+	o.Notify(event.Event{Tag: "a"})
+	clk.Advance(20 * time.Millisecond)
+	o.Notify(event.Event{Tag: "b"})
+
+	if c.len() != 0 {
+		t.Fatal("delivered before the delay")
+	}
+	clk.Advance(30 * time.Millisecond)
+	if c.len() != 1 || c.got[0].Tag != "a" {
+		t.Fatal("first event should be delivered after its delay", c.got)
+	}
+	clk.Advance(20 * time.Millisecond)
+	if c.len() != 2 || c.got[1].Tag != "b" {
+		t.Fatal("second event should be delivered after its delay", c.got)
+	}
+}
@@ -0,0 +1,122 @@
+package eventtest
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/event"
+)
+
+// Recorder is an observer that keeps every event it is sent, in the order delivered
+type Recorder struct {
+	sync.Mutex
+	events []event.Event
+	added  chan struct{}
+}
+
+// NewRecorder returns a Recorder registered on q to be sent events serially in publish
+// order.
+func NewRecorder(q *event.Queue) *Recorder {
+	r := &Recorder{
+		added: make(chan struct{}, 1),
+	}
+	q.RegisterWith(r, event.WithMaxConcurrent(1))
+	return r
+}
+
+// Notify records the event
+func (r *Recorder) Notify(e event.Event) {
+	r.Lock()
+	r.events = append(r.events, e)
+	r.Unlock()
+	select {
+	case r.added <- struct{}{}:
+	default:
+	}
+}
+
+// Events returns the events recorded so far
+func (r *Recorder) Events() []event.Event {
+	r.Lock()
+	defer r.Unlock()
+	return append([]event.Event(nil), r.events...)
+}
+
+// Wait waits up to d for at least n events to have been recorded, failing t if they
+// were not.
+func (r *Recorder) Wait(t testing.TB, n int, d time.Duration) {
+	t.Helper()
+	timeout := time.After(d)
+	for {
+		r.Lock()
+		got := len(r.events)
+		r.Unlock()
+		if got >= n {
+			return
+		}
+		select {
+		case <-r.added:
+		case <-timeout:
+			t.Fatalf("timed out with %d of %d events", got, n)
+		}
+	}
+}
+
+// AssertMonotonic fails t if the event IDs were not delivered in strictly increasing order.
+// The queue posts events in ID order even with concurrent publishers, so this holds for
+// any events of the same Priority, higher priority events buffered behind a slow Notify
+// overtake the rest.
+func (r *Recorder) AssertMonotonic(t testing.TB) {
+	t.Helper()
+	events := r.Events()
+	for i := 1; i < len(events); i++ {
+		if events[i].ID <= events[i-1].ID {
+			t.Errorf("event %d has ID %d after ID %d", i, events[i].ID, events[i-1].ID)
+		}
+	}
+}
+
+// AssertRunContiguous fails t if the events recorded for the run ref are not a complete
+// causal sequence with no gaps, with nothing after the end of the run other than
+// the run summary.
+func (r *Recorder) AssertRunContiguous(t testing.TB, ref event.RunRef) {
+	t.Helper()
+	var run []event.Event
+	summarised := false
+	for _, e := range r.Events() {
+		if !e.RunRef.Equal(ref) {
+			continue
+		}
+		// the summary is published after the causal clock of the run has ended
+		if e.Tag == event.TagRunSummary {
+			summarised = true
+			continue
+		}
+		run = append(run, e)
+	}
+	if len(run) == 0 {
+		t.Errorf("no events for run %s", ref)
+		return
+	}
+	sort.Slice(run, func(i, j int) bool {
+		return run[i].Causal < run[j].Causal
+	})
+	ended := false
+	for i, e := range run {
+		if e.Causal != int64(i+1) {
+			t.Errorf("run %s expected causal time %d got %d (%s)", ref, i+1, e.Causal, e.Tag)
+			return
+		}
+		if ended {
+			t.Errorf("run %s had event %s after it ended", ref, e.Tag)
+		}
+		if e.Tag == event.TagEndFlow {
+			ended = true
+		}
+	}
+	if summarised && !ended {
+		t.Errorf("run %s has a summary but did not end", ref)
+	}
+}
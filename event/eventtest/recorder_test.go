@@ -0,0 +1,76 @@
+package eventtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+	"github.com/floeit/floe/event"
+)
+
+// fakeT notes any failure instead of failing the test
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.failed = true
+}
+
+func TestRecorder(t *testing.T) {
+	q := event.NewQueue()
+	r := NewRecorder(q)
+	ref := event.RunRef{
+		FlowRef: config.FlowRef{ID: "build", Ver: 1},
+		Run:     event.HostedIDRef{HostID: "h1", ID: 1},
+	}
+	other := ref
+	other.Run.ID = 2
+
+	q.Publish(event.Event{Tag: "inbound.push"})
+	for _, tag := range []string{"trigger.good", "task.build.good", event.TagEndFlow} {
+		q.Publish(event.Event{RunRef: ref, Tag: tag})
+		q.Publish(event.Event{RunRef: other, Tag: tag})
+	}
+	// 7 published and 2 run summaries
+	r.Wait(t, 9, time.Second)
+
+	r.AssertMonotonic(t)
+	r.AssertRunContiguous(t, ref)
+	r.AssertRunContiguous(t, other)
+
+	// and the assertions fail when they should
+	bad := &Recorder{events: []event.Event{
+		{ID: 2, RunRef: ref, Causal: 1, Tag: event.TagEndFlow},
+		{ID: 1, RunRef: ref, Causal: 3, Tag: "task.build.good"},
+	}}
+	ft := &fakeT{}
+	bad.AssertMonotonic(ft)
+	if !ft.failed {
+		t.Error("out of order IDs should fail")
+	}
+	ft = &fakeT{}
+	bad.AssertRunContiguous(ft, ref)
+	if !ft.failed {
+		t.Error("causal gap should fail")
+	}
+}
+
+func TestRecorderConcurrentPublishers(t *testing.T) {
+	q := event.NewQueue()
+	r := NewRecorder(q)
+
+	const publishers, each = 8, 25
+	for p := 0; p < publishers; p++ {
+		go func() {
+			for i := 0; i < each; i++ {
+				q.Publish(event.Event{Tag: "inbound.push"})
+			}
+		}()
+	}
+	r.Wait(t, publishers*each, time.Second)
+	r.AssertMonotonic(t)
+}
@@ -0,0 +1,47 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestExecIDRetries(t *testing.T) {
+	q := NewQueue()
+	r := &recorder{}
+	q.Register(r)
+	ref := testRef(1)
+	node := config.NodeRef{Class: "task", ID: "flaky"}
+
+	// the same node executed twice in the run, the first attempt fails
+	attempts := map[int64][]string{}
+	for _, good := range []bool{false, true} {
+		execID := q.NewExecID()
+		start := Event{RunRef: ref, SourceNode: node, Tag: "sys.node.start", ExecID: execID}
+		up := UpdateEvent(ref, node, StreamStdout, 1, "running")
+		up.ExecID = execID
+		end := Event{RunRef: ref, SourceNode: node, Tag: "task.flaky.bad", ExecID: execID}
+		if good {
+			end.SetGood()
+		}
+		for _, e := range []Event{start, up, end} {
+			got := r.publish(q, e)
+			attempts[got.ExecID] = append(attempts[got.ExecID], got.Tag)
+		}
+	}
+
+	if len(attempts) != 2 {
+		t.Fatal("each attempt should have its own exec id", attempts)
+	}
+	for id, tags := range attempts {
+		if id == 0 {
+			t.Error("exec id not set")
+		}
+		if len(tags) != 3 || tags[0] != "sys.node.start" {
+			t.Errorf("attempt %d has wrong events %v", id, tags)
+		}
+	}
+	if attempts[1][2] != "task.flaky.bad" || attempts[2][2] != "task.flaky.good" {
+		t.Error("attempts ended wrongly", attempts)
+	}
+}
@@ -0,0 +1,60 @@
+package event
+
+// RouteDecision is whether an observer would be sent an event, and if not why not
+type RouteDecision struct {
+	Observer  Observer
+	Delivered bool
+	// Reason names what excluded the event, such as "tag subscription" or the filter of
+	// an option like "label selector". Filters that depend on the events before, or on
+	// chance, such as sampling, can not be decided so are given as the reason with
+	// Delivered false.
+	Reason string
+}
+
+// ExplainRouting returns the decision for each registered observer, in the order they
+// were registered, of whether it would be sent e if it were published now. Groups of
+// observers are a single decision. Nothing is published.
+func (q *Queue) ExplainRouting(e Event) []RouteDecision {
+	q.Lock()
+	q.init()
+	observers := q.observers
+	router := q.router
+	alert := q.alert
+	q.Unlock()
+
+	e.Tag = NormalizeTag(e.Tag)
+	var ds []RouteDecision
+	for _, r := range observers {
+		ds = append(ds, r.explain(e, router))
+	}
+	if alert != nil {
+		// the alert observer is sent error events whatever the routing
+		ds = append(ds, alert.explain(e, nil))
+	}
+	return ds
+}
+
+// explain decides if the registration would be sent e, a nil router is not consulted
+func (r *registration) explain(e Event, router Router) RouteDecision {
+	d := RouteDecision{Observer: r.o}
+	if r.runScoped && e.RunRef.IsZero() {
+		d.Reason = "run scope"
+		return d
+	}
+	for _, f := range r.filters {
+		if f.stateful {
+			d.Reason = f.name + " (undecidable)"
+			return d
+		}
+		if !f.pass(e) {
+			d.Reason = f.name
+			return d
+		}
+	}
+	if router != nil && !router.Matches(e, r.sub) {
+		d.Reason = "tag subscription"
+		return d
+	}
+	d.Delivered = true
+	return d
+}
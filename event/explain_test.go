@@ -0,0 +1,57 @@
+package event
+
+import "testing"
+
+func TestExplainRouting(t *testing.T) {
+	alert := make(chanObs, 1)
+	q := NewQueue(WithAlertObserver(alert))
+	all := make(chanObs, 1)
+	tagged := make(chanObs, 1)
+	good := make(chanObs, 1)
+	prod := make(chanObs, 1)
+	run := make(chanObs, 1)
+	ends := make(chanObs, 1)
+	q.Register(all)
+	q.Subscribe(tagged, "task.deploy.bad")
+	q.RegisterGood(good)
+	q.RegisterSelector("env=prod", prod)
+	q.SubscribeRun(testRef(2), 0, run)
+	q.RegisterBookends(ends)
+
+	ref := testRef(1)
+	ref.Labels = map[string]string{"env": "staging"}
+	e := Event{RunRef: ref, Tag: "Task.Build.bad"}
+	want := []struct {
+		o         Observer
+		delivered bool
+		reason    string
+	}{
+		{o: all, delivered: true},
+		{o: tagged, reason: "tag subscription"},
+		{o: good, reason: "good only"},
+		{o: prod, reason: "label selector"},
+		{o: run, reason: "run"},
+		{o: ends, reason: "bookends (undecidable)"},
+		{o: alert, delivered: true},
+	}
+
+	ds := q.ExplainRouting(e)
+	if len(ds) != len(want) {
+		t.Fatal("wrong number of decisions", len(ds))
+	}
+	for i, w := range want {
+		d := ds[i]
+		if d.Observer != w.o || d.Delivered != w.delivered || d.Reason != w.reason {
+			t.Errorf("%d: got %v %q wanted %v %q", i, d.Delivered, d.Reason, w.delivered, w.reason)
+		}
+	}
+
+	// general events never reach run scoped observers
+	ds = q.ExplainRouting(Event{Tag: "inbound.data"})
+	if ds[4].Delivered || ds[4].Reason != "run scope" {
+		t.Error("general event should be excluded by the run scope", ds[4].Reason)
+	}
+	if len(q.Tail(10)) != 0 {
+		t.Error("explaining must not publish")
+	}
+}
@@ -0,0 +1,92 @@
+package event
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithPersistFilter sets which events of a run are archived by ExportRun, all events
+// are still delivered live and retained in the run history. By default every event
+// other than node updates is archived.
+func WithPersistFilter(f func(Event) bool) QueueOption {
+	return func(q *Queue) {
+		q.persist = f
+	}
+}
+
+// persistMilestones is the default persist filter dropping the noisy node updates
+func persistMilestones(e Event) bool {
+	return e.Tag != TagNodeUpdate
+}
+
+// persists returns true if e passes the persist filter, it must be called in the lock
+func (q *Queue) persists(e Event) bool {
+	if q.persist == nil {
+		return persistMilestones(e)
+	}
+	return q.persist(e)
+}
+
+// ExportRun writes the retained history of the run that passes the persist filter to w,
+// one encoded event per line in ID order, and then drops the history from memory. Any
+// events published for the run while the export was being written are kept.
+func (q *Queue) ExportRun(ref RunRef, w io.Writer, codec EventCodec) error {
+	events := q.History(ref)
+	if events == nil {
+		return fmt.Errorf("no history for run %s", ref)
+	}
+	// a live run already exported, or one whose events were all compacted away
+	if len(events) == 0 {
+		return nil
+	}
+	q.RLock()
+	persist := q.persist
+	q.RUnlock()
+	if persist == nil {
+		persist = persistMilestones
+	}
+	for _, e := range events {
+		if !persist(e) {
+			continue
+		}
+		b, err := codec.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	q.evict(ref, events[len(events)-1].ID)
+	return nil
+}
+
+// evict drops the history of the run up to and including event ID upTo, noting them as
+// evicted so later subscribers are told of the gap
+func (q *Queue) evict(ref RunRef, upTo int64) {
+	q.Lock()
+	defer q.Unlock()
+	k := ref.key()
+	h, ok := q.history[k]
+	if !ok {
+		return
+	}
+	i := 0
+	for i < len(h.events) && h.events[i].ID <= upTo {
+		i++
+	}
+	h.events = h.events[i:]
+	if upTo > h.evicted {
+		h.evicted = upTo
+	}
+	if len(h.events) > 0 || !h.ended {
+		return
+	}
+	delete(q.history, k)
+	for i, d := range q.done {
+		if d == k {
+			q.done = append(q.done[:i], q.done[i+1:]...)
+			break
+		}
+	}
+}
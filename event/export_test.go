@@ -0,0 +1,138 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestExportRun(t *testing.T) {
+	q := NewQueue()
+	ref, other := testRef(1), testRef(2)
+	for _, tag := range []string{"trigger.good", "task.build.good", TagEndFlow} {
+		q.Publish(Event{RunRef: ref, Tag: tag})
+		q.Publish(Event{RunRef: other, Tag: tag})
+	}
+
+	buf := &bytes.Buffer{}
+	if err := q.ExportRun(ref, buf, JSONCodec{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Event
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		e, err := JSONCodec{}.Unmarshal(s.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+	}
+	// the three published and the run summary
+	if len(got) != 4 || got[3].Tag != TagRunSummary {
+		t.Fatal("wrong events exported", len(got))
+	}
+	for i, e := range got {
+		if !e.RunRef.Equal(ref) {
+			t.Error("exported another run", e.RunRef)
+		}
+		if i > 0 && e.ID <= got[i-1].ID {
+			t.Error("events not in ID order")
+		}
+	}
+
+	if h := q.History(ref); len(h) != 0 {
+		t.Error("exported events should be gone from history", len(h))
+	}
+	if h := q.History(other); len(h) != 4 {
+		t.Error("other run should be untouched", len(h))
+	}
+	if err := q.ExportRun(ref, buf, JSONCodec{}); err == nil {
+		t.Error("exporting an exported run should fail")
+	}
+}
+
+func TestExportPersistFilter(t *testing.T) {
+	export := func(q *Queue) []string {
+		ref := testRef(1)
+		q.Publish(Event{RunRef: ref, Tag: "trigger.good"})
+		q.Publish(UpdateEvent(ref, config.NodeRef{Class: "task", ID: "build"}, StreamStdout, 1, "compiling"))
+		q.Publish(Event{RunRef: ref, Tag: "task.build.good"})
+		if n := len(q.History(ref)); n != 3 {
+			t.Error("all events should be retained", n)
+		}
+		buf := &bytes.Buffer{}
+		if err := q.ExportRun(ref, buf, JSONCodec{}); err != nil {
+			t.Fatal(err)
+		}
+		var tags []string
+		s := bufio.NewScanner(buf)
+		for s.Scan() {
+			e, _ := JSONCodec{}.Unmarshal(s.Bytes())
+			tags = append(tags, e.Tag)
+		}
+		if n := len(q.History(ref)); n != 0 {
+			t.Error("exported history should be dropped", n)
+		}
+		return tags
+	}
+
+	// updates are delivered live but not archived
+	q := NewQueue()
+	c := make(chanObs, 10)
+	q.RegisterWith(c, WithMaxConcurrent(1))
+	tags := export(q)
+	if len(tags) != 2 || tags[0] != "trigger.good" || tags[1] != "task.build.good" {
+		t.Error("updates should not be persisted", tags)
+	}
+	for _, want := range []string{"trigger.good", TagNodeUpdate, "task.build.good"} {
+		if e := next(t, c); e.Tag != want {
+			t.Errorf("got %s wanted %s", e.Tag, want)
+		}
+	}
+
+	q = NewQueue(WithPersistFilter(func(e Event) bool { return e.Good || e.IsSystem() }))
+	tags = export(q)
+	if len(tags) != 1 || tags[0] != TagNodeUpdate {
+		t.Error("custom filter not used", tags)
+	}
+}
+
+func TestExportActiveRunTwice(t *testing.T) {
+	q := NewQueue()
+	ref := testRef(1)
+	q.Publish(Event{RunRef: ref, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good"})
+
+	lines := func() int {
+		buf := &bytes.Buffer{}
+		if err := q.ExportRun(ref, buf, JSONCodec{}); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Count(buf.String(), "\n")
+	}
+	if n := lines(); n != 2 {
+		t.Error("first export should have both events", n)
+	}
+	// nothing new to export
+	if n := lines(); n != 0 {
+		t.Error("second export should be empty", n)
+	}
+
+	// a later subscriber is told the exported events are gone
+	q.Publish(Event{RunRef: ref, Tag: "task.test.good"})
+	c := make(chanObs, 10)
+	q.SubscribeRun(ref, 0, c)
+	if e := next(t, c); e.Tag != TagRunSnapshot || e.Opts["evicted"] != int64(2) {
+		t.Error("expected the gap marker for the exported events", e.Tag, e.Opts)
+	}
+	if e := next(t, c); e.Tag != "task.test.good" {
+		t.Error("expected the retained event", e.Tag)
+	}
+	if n := lines(); n != 1 {
+		t.Error("third export should have the new event", n)
+	}
+}
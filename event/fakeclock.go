@@ -0,0 +1,79 @@
+package event
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a Clock that only moves when advanced, for tests of code using the queue
+// to control time, see WithClock
+type FakeClock struct {
+	sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	c  *FakeClock
+	at time.Time
+	f  func()
+}
+
+// NewFakeClock returns a FakeClock set to a fixed time
+func NewFakeClock() *FakeClock {
+	return &FakeClock{
+		now: time.Date(2017, 11, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// Now returns the current fake time
+func (c *FakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules f to be called when the clock is advanced past d from now
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.Lock()
+	defer c.Unlock()
+	t := &fakeTimer{c: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock on by d synchronously firing any timers that fall due, in order.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Lock()
+	end := c.now.Add(d)
+	c.Unlock()
+	for {
+		c.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool {
+			return c.timers[i].at.Before(c.timers[j].at)
+		})
+		if len(c.timers) == 0 || c.timers[0].at.After(end) {
+			c.now = end
+			c.Unlock()
+			return
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = t.at
+		c.Unlock()
+		t.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.c.Lock()
+	defer t.c.Unlock()
+	for i, ft := range t.c.timers {
+		if ft == t {
+			t.c.timers = append(t.c.timers[:i], t.c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
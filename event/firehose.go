@@ -0,0 +1,61 @@
+package event
+
+import "math/rand"
+
+// Firehose is a policy for an observer of a sample of all events, built up declaratively
+// then registered, such as 1% of all events but every error event, only for production:
+//
+//	NewFirehose().Sample(0.01).KeepErrors().Select("env=prod").Register(q, o)
+type Firehose struct {
+	rate       float64
+	keepErrors bool
+	selector   string
+	random     func() float64 // returns [0,1) to decide if an event is sampled
+}
+
+// NewFirehose returns a policy passing every event
+func NewFirehose() *Firehose {
+	return &Firehose{rate: 1, random: rand.Float64}
+}
+
+// Sample passes the fraction rate of events, chosen at random
+func (f *Firehose) Sample(rate float64) *Firehose {
+	f.rate = rate
+	return f
+}
+
+// KeepErrors passes every error event whatever the sample rate, the same events as are
+// sent to RegisterErrors observers
+func (f *Firehose) KeepErrors() *Firehose {
+	f.keepErrors = true
+	return f
+}
+
+// Select only passes the events of runs whose labels match the selector, see ParseSelector
+func (f *Firehose) Select(sel string) *Firehose {
+	f.selector = sel
+	return f
+}
+
+// Register registers o on q for the events passed by the policy, with any options
+func (f *Firehose) Register(q *Queue, o Observer, opts ...RegisterOption) error {
+	sel, err := ParseSelector(f.selector)
+	if err != nil {
+		return err
+	}
+	rate, keepErrors, random := f.rate, f.keepErrors, f.random
+	selected := func(e Event) bool {
+		return sel.Matches(e.RunRef.Labels)
+	}
+	sampled := func(e Event) bool {
+		if keepErrors && isError(e) {
+			return true
+		}
+		return rate >= 1 || random() < rate
+	}
+	policy := []RegisterOption{
+		withNamedFilter("label selector", selected),
+		withStatefulFilter("sample", sampled),
+	}
+	return q.RegisterWith(o, append(policy, opts...)...)
+}
@@ -0,0 +1,68 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFirehose(t *testing.T) {
+	q := NewQueue()
+
+	// cycle through 0.00 to 0.99 so exactly 1% of events are sampled
+	var mu sync.Mutex
+	n := 0
+	f := NewFirehose().Sample(0.01).KeepErrors().Select("env=prod")
+	f.random = func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return float64(n%100) / 100
+	}
+	wg := &sync.WaitGroup{}
+	c := &counter{wg: wg}
+	if err := f.Register(q, c); err != nil {
+		t.Fatal(err)
+	}
+
+	prod, staging := testRef(1), testRef(2)
+	prod.Labels = map[string]string{"env": "prod"}
+	staging.Labels = map[string]string{"env": "staging"}
+
+	wg.Add(2 + 5)
+	for i := 0; i < 200; i++ {
+		q.Publish(Event{RunRef: prod, Tag: "task.build.good", Good: true})
+		q.Publish(Event{RunRef: staging, Tag: "task.build.good", Good: true})
+	}
+	for i := 0; i < 5; i++ {
+		q.Publish(Event{RunRef: prod, Tag: "task.build.bad"})
+		q.Publish(Event{RunRef: staging, Tag: "task.build.bad"})
+	}
+	wg.Wait()
+
+	time.Sleep(10 * time.Millisecond)
+	c.Lock()
+	defer c.Unlock()
+	if c.n != 7 {
+		t.Error("expected 1% of prod events and all prod errors", c.n)
+	}
+
+	if err := NewFirehose().Select("env in").Register(q, &counter{}); err == nil {
+		t.Error("bad selector should error")
+	}
+}
+
+// kept errors are those of RegisterErrors, never system or downgraded events
+func TestFirehoseKeepErrors(t *testing.T) {
+	q := NewQueue()
+	errs := make(chanObs, 10)
+	if err := NewFirehose().Sample(0).KeepErrors().Register(q, errs); err != nil {
+		t.Fatal(err)
+	}
+	q.Publish(Event{Tag: "sys.state"})
+	q.Publish(Event{Tag: "task.flaky.bad", Level: LevelWarn})
+	q.Publish(Event{Tag: "task.build.bad"})
+	if e := next(t, errs); e.Tag != "task.build.bad" {
+		t.Error("only the error should be kept", e.Tag)
+	}
+}
@@ -0,0 +1,228 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// Fork returns a copy of e for each of tags, each with its own deep copy of the opts so
+// changing one variant never changes another. The copies keep the RunRef and source of e,
+// publish them with PublishAll to give them consecutive IDs. Opts that can not be copied
+// safely are replaced by a placeholder in the forks, and logged by the queue logger.
+func (q *Queue) Fork(e Event, tags ...string) []Event {
+	forks := make([]Event, 0, len(tags))
+	// warn once, the placeholders in c copy without any warnings
+	c := e.copyWarn(warnUncopyable(q.getLogger(), e))
+	for _, tag := range tags {
+		f := c.copy()
+		f.Tag = tag
+		forks = append(forks, f)
+	}
+	return forks
+}
+
+// PublishAll publishes events as one contiguous batch, no other event is published between
+// them, so they have consecutive IDs. Each is checked as if published on its own.
+func (q *Queue) PublishAll(events []Event) {
+	if q.parent != nil {
+		scoped := make([]Event, len(events))
+		for i, e := range events {
+			e.RunRef = q.scopeRef
+			e.SourceNode = q.scopeNode
+			scoped[i] = e
+		}
+		q.parent.PublishAll(scoped)
+		return
+	}
+	q.Lock()
+	q.init()
+	ds := make([]delivery, 0, len(events))
+	for _, e := range events {
+		if q.hold(e) {
+			continue
+		}
+		ds = append(ds, q.stamp(e, false))
+	}
+	q.Unlock()
+	// post them all before anything they lead to is published
+	drops := make([][]func(), len(ds))
+	for i, d := range ds {
+		drops[i] = d.post(q)
+	}
+	for i, d := range ds {
+		d.finish(q, drops[i])
+	}
+}
+
+// deepOpts copies o along with any maps, slices and structs nested in it. Values it can not copy
+// safely, such as pointers, are replaced by a placeholder string naming their type rather
+// than shared, and reported to warn, if not nil, with their key.
+func deepOpts(o nt.Opts, warn func(key string, v interface{})) nt.Opts {
+	if o == nil {
+		return nil
+	}
+	c := make(nt.Opts, len(o))
+	for k, v := range o {
+		c[k] = deepValue(k, v, warn)
+	}
+	return c
+}
+
+func deepValue(key string, v interface{}, warn func(key string, v interface{})) interface{} {
+	switch t := v.(type) {
+	case nil, string, bool, int, int64, float64, json.Number, time.Time:
+		return v
+	case nt.Opts:
+		return deepOpts(t, nestedWarn(key, warn))
+	case map[string]interface{}:
+		return map[string]interface{}(deepOpts(nt.Opts(t), nestedWarn(key, warn)))
+	case []interface{}:
+		c := make([]interface{}, len(t))
+		for i, iv := range t {
+			c[i] = deepValue(fmt.Sprintf("%s.%d", key, i), iv, warn)
+		}
+		return c
+	case []string:
+		return append([]string(nil), t...)
+	}
+	if c, ok := deepReflect(reflect.ValueOf(v)); ok {
+		return c.Interface()
+	}
+	if warn != nil {
+		warn(key, v)
+	}
+	return uncopyable(v)
+}
+
+// uncopyable is the placeholder for a value deepOpts can not copy
+func uncopyable(v interface{}) string {
+	return fmt.Sprintf("<uncopyable %T>", v)
+}
+
+// nestedWarn reports values nested under key with the full dotted path to them
+func nestedWarn(key string, warn func(key string, v interface{})) func(string, interface{}) {
+	if warn == nil {
+		return nil
+	}
+	return func(k string, v interface{}) {
+		warn(key+"."+k, v)
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// deepReflect copies v along with any slices, maps and structs it holds, returning false
+// if anything in it can not be copied safely - pointers, chans, funcs and unexported
+// fields holding references.
+func deepReflect(v reflect.Value) (reflect.Value, bool) {
+	if flat(v.Type()) || v.Type() == timeType {
+		return v, true
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, true
+		}
+		c, ok := deepReflect(v.Elem())
+		if !ok {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(c)
+		return out, true
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, true
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		return out, deepElems(v, out)
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		return out, deepElems(v, out)
+	case reflect.Map:
+		if v.IsNil() {
+			return v, true
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, mk := range v.MapKeys() {
+			k, ok := deepReflect(mk)
+			if !ok {
+				return v, false
+			}
+			e, ok := deepReflect(v.MapIndex(mk))
+			if !ok {
+				return v, false
+			}
+			out.SetMapIndex(k, e)
+		}
+		return out, true
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if flat(f.Type) {
+				continue
+			}
+			if f.PkgPath != "" { // unexported so can not be copied
+				return v, false
+			}
+			c, ok := deepReflect(v.Field(i))
+			if !ok {
+				return v, false
+			}
+			out.Field(i).Set(c)
+		}
+		return out, true
+	case reflect.Ptr, reflect.Chan, reflect.Func:
+		if v.IsNil() {
+			return v, true
+		}
+	}
+	// pointers, chans, funcs and unsafe pointers would share memory
+	return v, false
+}
+
+// deepElems copies the elements of the slice or array v into out
+func deepElems(v, out reflect.Value) bool {
+	for i := 0; i < v.Len(); i++ {
+		c, ok := deepReflect(v.Index(i))
+		if !ok {
+			return false
+		}
+		out.Index(i).Set(c)
+	}
+	return true
+}
+
+// flat returns true if values of type t hold no references, so assigning them copies them
+func flat(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return flat(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !flat(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// warnUncopyable returns a warn func for deepOpts logging to l what was replaced in e
+func warnUncopyable(l Logger, e Event) func(string, interface{}) {
+	return func(key string, v interface{}) {
+		l.Errorf("<%s> - warning: can not copy opt '%s' of %s, type %T replaced by a placeholder", e.RunRef, key, e.Tag, v)
+	}
+}
@@ -0,0 +1,164 @@
+package event
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/floeit/floe/config"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestFork(t *testing.T) {
+	e := Event{
+		RunRef: testRef(1),
+		Tag:    "task.build.good",
+		Opts:   nt.Opts{"env": []string{"A=1"}, "meta": nt.Opts{"k": "v"}},
+	}
+	q := NewQueue()
+	forks := q.Fork(e, "task.build.good", "task.build.docker")
+	if len(forks) != 2 || forks[1].Tag != "task.build.docker" || forks[1].RunRef.key() != e.RunRef.key() {
+		t.Fatal("bad forks", forks)
+	}
+	// the opts of each fork are independent, even nested ones
+	forks[0].Opts["meta"].(nt.Opts)["k"] = "changed"
+	forks[0].Opts["env"].([]string)[0] = "A=2"
+	if forks[1].Opts["meta"].(nt.Opts)["k"] != "v" || forks[1].Opts["env"].([]string)[0] != "A=1" {
+		t.Error("forks share opts", forks[1].Opts)
+	}
+	if e.Opts["meta"].(nt.Opts)["k"] != "v" {
+		t.Error("fork changed the original opts", e.Opts)
+	}
+
+	// each fork is routed to its own subscriber with consecutive ids
+	good := make(chanObs, 2)
+	docker := make(chanObs, 2)
+	q.RegisterWith(good, WithTags("task.build.good"))
+	q.RegisterWith(docker, WithTags("task.build.docker"))
+	q.PublishAll(q.Fork(e, "task.build.good", "task.build.docker"))
+	g := next(t, good)
+	d := next(t, docker)
+	if g.Tag != "task.build.good" || d.Tag != "task.build.docker" {
+		t.Fatal("forks routed to the wrong subscribers", g.Tag, d.Tag)
+	}
+	if d.ID != g.ID+1 {
+		t.Error("batch ids should be consecutive", g.ID, d.ID)
+	}
+	if len(good) != 0 || len(docker) != 0 {
+		t.Error("subscriber got the other fork")
+	}
+}
+
+func TestDeepOptsUncopyable(t *testing.T) {
+	type point struct{ X, Y int }
+	n := 3
+	o := nt.Opts{
+		"count":  2,
+		"point":  point{1, 2},
+		"ports":  []int{80, 443},
+		"ptr":    &n,
+		"nested": nt.Opts{"ch": make(chan int)},
+	}
+	var warned []string
+	c := deepOpts(o, func(key string, v interface{}) {
+		warned = append(warned, key)
+	})
+	sort.Strings(warned)
+	if len(warned) != 2 || warned[0] != "nested.ch" || warned[1] != "ptr" {
+		t.Error("expected warnings for the pointer and channel", warned)
+	}
+	if c["ptr"] != "<uncopyable *int>" || c["nested"].(nt.Opts)["ch"] != "<uncopyable chan int>" {
+		t.Error("uncopyable values should be replaced by a placeholder", c)
+	}
+	// values with no references are kept, and slices of them copied
+	c["ports"].([]int)[0] = 8080
+	if c["count"] != 2 || c["point"] != (point{1, 2}) || o["ports"].([]int)[0] != 80 {
+		t.Error("copyable values changed or shared", c, o)
+	}
+
+	// the queue logs the replaced values
+	l := &captureLogger{}
+	q := NewQueue(WithLogger(l))
+	warnedOf := func(tag string) bool {
+		l.Lock()
+		defer l.Unlock()
+		for _, line := range l.lines {
+			if strings.HasPrefix(line, "error ") && strings.Contains(line, "can not copy opt 'ptr' of "+tag+", type *int") {
+				return true
+			}
+		}
+		return false
+	}
+	ref := testRef(1)
+	tc := make(chanObs, 1)
+	q.Subscribe(tc, "trigger.good")
+	pub := nt.Opts{"ptr": &n}
+	q.Publish(Event{RunRef: ref, SourceNode: config.NodeRef{Class: "trigger", ID: "push"}, Tag: "trigger.good", Good: true,
+		Opts: pub})
+	if e := next(t, tc); e.Opts["ptr"] != "<uncopyable *int>" || pub["ptr"] != &n {
+		t.Error("observers should be sent the placeholder, leaving the publishers opts", e.Opts, pub)
+	}
+	if !warnedOf("trigger.good") {
+		t.Error("no warning logged for the published uncopyable opt", l.lines)
+	}
+
+	pc := make(chanObs, 1)
+	q.Subscribe(pc, InboundPrefix+".push")
+	if err := q.ReplayAsTrigger(ref, "push"); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, pc); e.Opts["ptr"] != "<uncopyable *int>" {
+		t.Error("replayed trigger should have the placeholder", e.Opts)
+	}
+
+	forks := q.Fork(Event{RunRef: ref, Tag: "task.build.good", Opts: nt.Opts{"ptr": &n}}, "task.build.docker")
+	if forks[0].Opts["ptr"] != "<uncopyable *int>" {
+		t.Error("fork should have the placeholder", forks[0].Opts)
+	}
+	if !warnedOf("task.build.good") {
+		t.Error("no warning logged by the queue for the forked uncopyable opt", l.lines)
+	}
+}
+
+func TestDeepOptsDataNode(t *testing.T) {
+	in := nt.Opts{
+		"form": nt.Opts{
+			"title":  "Sign off",
+			"fields": []interface{}{nt.Opts{"id": "tests_passed", "prompt": "Passed?", "type": "bool"}},
+		},
+		"values": map[string]string{"tests_passed": "true"},
+	}
+	_, out, err := nt.GetNodeType("data").Execute(&nt.Workspace{}, in, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := &captureLogger{}
+	q := NewQueue(WithLogger(l))
+	c := make(chanObs, 1)
+	q.Subscribe(c, "task.sign-off.good")
+	q.Publish(Event{RunRef: testRef(1), Tag: "task.sign-off.good", Good: true, Opts: out})
+	e := next(t, c)
+
+	// the form struct is copied intact, not shared
+	if !reflect.DeepEqual(e.Opts["form"], out["form"]) {
+		t.Fatalf("form not copied intact: %#v", e.Opts["form"])
+	}
+	if v := reflect.ValueOf(out["form"]).FieldByName("Fields").Index(0).FieldByName("Value").String(); v != "true" {
+		t.Error("field value lost", v)
+	}
+	delivered := reflect.ValueOf(e.Opts["form"]).FieldByName("Fields")
+	published := reflect.ValueOf(out["form"]).FieldByName("Fields")
+	if delivered.Pointer() == published.Pointer() {
+		t.Error("the delivered form shares its fields with the publisher")
+	}
+	l.Lock()
+	defer l.Unlock()
+	for _, line := range l.lines {
+		if strings.HasPrefix(line, "error ") {
+			t.Error("nothing should be logged as uncopyable", line)
+		}
+	}
+}
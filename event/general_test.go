@@ -0,0 +1,43 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestRunRefIsZero(t *testing.T) {
+	if !(RunRef{}).IsZero() {
+		t.Error("empty ref should be zero")
+	}
+	if (RunRef{FlowRef: config.FlowRef{ID: "build", Ver: 1}}).IsZero() {
+		t.Error("ref targeting a flow is not zero")
+	}
+	if testRef(1).IsZero() {
+		t.Error("adopted ref is not zero")
+	}
+}
+
+func TestGeneralEventRouting(t *testing.T) {
+	q := NewQueue()
+	triggers := make(chanObs, 10)
+	q.Register(triggers)
+	run := make(chanObs, 10)
+	if err := q.SubscribeRun(testRef(1), 0, run); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.SubscribeRun(RunRef{}, 0, run); err == nil {
+		t.Error("subscribing to the zero run should fail")
+	}
+
+	q.Publish(Event{Tag: "inbound.push"})
+	if e := next(t, triggers); e.Tag != "inbound.push" {
+		t.Error("trigger observer should get general events", e.Tag)
+	}
+	select {
+	case e := <-run:
+		t.Error("run subscription should not get general events", e.Tag)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,85 @@
+package event
+
+import "sync"
+
+// group is registered on the queue as a single observer, passing each event on to just
+// one of its members by smooth weighted round robin.
+type group struct {
+	sync.Mutex
+	name    string
+	members []*member
+}
+
+type member struct {
+	o       Observer
+	weight  int
+	current int
+}
+
+// RegisterGroup registers o as a member of the named group. Each event is sent to only
+// one member of a group in turn, rather than to all of them, so identical workers can
+// share the load.
+func (q *Queue) RegisterGroup(name string, o Observer) error {
+	return q.RegisterGroupWeighted(name, o, 1)
+}
+
+// RegisterGroupWeighted registers o as a member of the named group sent weight events
+// for every one sent to a member of weight 1. A group counts as one observer towards the
+// maximum observers on the queue.
+func (q *Queue) RegisterGroupWeighted(name string, o Observer, weight int) error {
+	if weight < 1 {
+		weight = 1
+	}
+	q.Lock()
+	defer q.Unlock()
+	g, ok := q.groups[name]
+	if !ok {
+		if q.groups == nil {
+			q.groups = map[string]*group{}
+		}
+		g = &group{name: name}
+		if err := q.add(&registration{o: g}); err != nil {
+			return err
+		}
+		q.groups[name] = g
+	}
+	g.Lock()
+	defer g.Unlock()
+	g.members = append(g.members, &member{o: o, weight: weight})
+	return nil
+}
+
+// Notify sends e to the next member of the group
+func (g *group) Notify(e Event) {
+	g.Lock()
+	var next *member
+	total := 0
+	for _, m := range g.members {
+		m.current += m.weight
+		total += m.weight
+		if next == nil || m.current > next.current {
+			next = m
+		}
+	}
+	if next != nil {
+		next.current -= total
+	}
+	g.Unlock()
+
+	if next != nil {
+		next.o.Notify(e)
+	}
+}
+
+// remove drops o from the group returning true if it was a member
+func (g *group) remove(o Observer) bool {
+	g.Lock()
+	defer g.Unlock()
+	for i, m := range g.members {
+		if sameObserver(m.o, o) {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
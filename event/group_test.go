@@ -0,0 +1,65 @@
+package event
+
+import (
+	"sync"
+	"testing"
+)
+
+// counter counts the events it is sent
+type counter struct {
+	sync.Mutex
+	wg *sync.WaitGroup
+	n  int
+}
+
+func (c *counter) Notify(e Event) {
+	c.Lock()
+	c.n++
+	c.Unlock()
+	c.wg.Done()
+}
+
+func TestRegisterGroup(t *testing.T) {
+	q := NewQueue()
+	wg := &sync.WaitGroup{}
+	workers := []*counter{{wg: wg}, {wg: wg}, {wg: wg}}
+	for _, w := range workers {
+		q.RegisterGroup("workers", w)
+	}
+	heavy := &counter{wg: wg}
+	light := &counter{wg: wg}
+	q.RegisterGroupWeighted("weighted", heavy, 3)
+	q.RegisterGroup("weighted", light)
+	all := &counter{wg: wg}
+	q.Register(all)
+
+	const n = 300
+	wg.Add(n * 3) // one worker, one weighted member and all for each event
+	for i := 0; i < n; i++ {
+		q.Publish(Event{Tag: "job"})
+	}
+	wg.Wait()
+
+	for i, w := range workers {
+		if w.n != n/3 {
+			t.Errorf("worker %d had %d events, wanted %d", i, w.n, n/3)
+		}
+	}
+	if heavy.n != 3*n/4 || light.n != n/4 {
+		t.Errorf("weighted split wrong %d %d", heavy.n, light.n)
+	}
+	if all.n != n {
+		t.Error("ordinary observers should get every event", all.n)
+	}
+
+	if !q.Unregister(light) {
+		t.Error("group member not unregistered")
+	}
+	wg.Add(6)
+	q.Publish(Event{Tag: "job"})
+	q.Publish(Event{Tag: "job"})
+	wg.Wait()
+	if light.n != n/4 || heavy.n != 3*n/4+2 {
+		t.Errorf("unregistered member should get no more events %d %d", heavy.n, light.n)
+	}
+}
@@ -0,0 +1,137 @@
+package event
+
+import (
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// TagRunSummary is the tag of the event published once a run has ended, summarising the run
+const TagRunSummary = "sys.run.summary"
+
+const (
+	defaultRunEvents = 10000 // maximum events retained per run
+	defaultDoneRuns  = 100   // how many ended runs keep their history
+)
+
+// runHistory is the retained events for a single run along with a tally of the run
+type runHistory struct {
+	ref     RunRef
+	events  []Event
+	evicted int64 // the ID of the most recent event that had to be dropped, 0 if none
+	ended   bool
+
+	chainHead string // hash of the last event of the run chained, see WithHashChain
+	trigger   *Event // the trigger event that started the run, kept whatever is evicted
+
+	// tally
+	total int                     // all events seen - including evicted ones
+	nodes map[config.NodeRef]bool // distinct nodes that published events
+	first time.Time
+	last  time.Time
+}
+
+// runHistoryOf returns the history of the run of e, starting it if e is the first event
+// of the run. runHistoryOf must be called in the lock.
+func (q *Queue) runHistoryOf(e Event) *runHistory {
+	if q.history == nil {
+		q.history = map[runKey]*runHistory{}
+	}
+	k := e.RunRef.key()
+	h, ok := q.history[k]
+	if !ok {
+		h = &runHistory{
+			ref:   e.RunRef,
+			nodes: map[config.NodeRef]bool{},
+			first: e.Time,
+		}
+		q.history[k] = h
+	}
+	return h
+}
+
+// record adds the event to the history for its run. record must be called in the lock.
+func (q *Queue) record(e Event) {
+	if !e.RunRef.Adopted() {
+		return
+	}
+	k := e.RunRef.key()
+	h := q.runHistoryOf(e)
+	if h.trigger == nil && isTrigger(e) {
+		t := e.copy()
+		h.trigger = &t
+	}
+	c := q.compactionOf(e.RunRef.FlowRef)
+	if c.Keep(e) {
+		h.events = append(h.events, e.copy())
+	}
+	max := q.runEvents
+	if n := c.Max(); n > 0 && (max == 0 || n < max) {
+		max = n
+	}
+	for max > 0 && len(h.events) > max {
+		h.evicted = h.events[0].ID
+		h.events[0] = Event{}
+		h.events = h.events[1:]
+	}
+	h.total++
+	h.last = e.Time
+	if e.SourceNode.ID != "" {
+		h.nodes[e.SourceNode] = true
+	}
+	if e.Tag == TagEndFlow && !h.ended {
+		h.ended = true
+		q.done = append(q.done, k)
+		q.trimDone()
+	}
+}
+
+// trimDone drops the history of the oldest ended runs over the retention limit.
+// trimDone must be called in the lock.
+func (q *Queue) trimDone() {
+	for q.doneRuns > 0 && len(q.done) > q.doneRuns {
+		delete(q.history, q.done[0])
+		q.done = q.done[1:]
+	}
+}
+
+// History returns copies of the retained events of the run in the order they were published
+func (q *Queue) History(ref RunRef) []Event {
+	q.RLock()
+	defer q.RUnlock()
+	h, ok := q.history[ref.key()]
+	if !ok {
+		return nil
+	}
+	res := make([]Event, len(h.events))
+	for i, e := range h.events {
+		res[i] = e.copy()
+	}
+	return res
+}
+
+// summary returns the run summary event for the ended run e. summary must be called in the lock.
+func (q *Queue) summary(e Event) *Event {
+	if e.Tag != TagEndFlow || !e.RunRef.Adopted() {
+		return nil
+	}
+	h, ok := q.history[e.RunRef.key()]
+	if !ok {
+		return nil
+	}
+	return &Event{
+		RunRef:     e.RunRef,
+		SourceNode: e.SourceNode,
+		Tag:        TagRunSummary,
+		Good:       e.Good,
+		Opts: nt.Opts{
+			"good":     e.Good,
+			"duration": h.last.Sub(h.first).Seconds(),
+			"started":  h.first,
+			"ended":    h.last,
+			"nodes":    len(h.nodes),
+			"events":   h.total,
+		},
+	}
+}
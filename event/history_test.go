@@ -0,0 +1,87 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+)
+
+func testRef(id int64) RunRef {
+	return RunRef{
+		FlowRef:  config.FlowRef{ID: "build", Ver: 1},
+		Run:      HostedIDRef{HostID: "h1", ID: id},
+		ExecHost: "h1",
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk))
+	summaries := make(chan Event, 1)
+	q.Register(&listener{what: func(e Event) {
+		if e.Tag == TagRunSummary {
+			summaries <- e
+		}
+	}})
+
+	ref := testRef(1)
+	trig := config.NodeRef{Class: "trigger", ID: "push"}
+	build := config.NodeRef{Class: "task", ID: "build"}
+	end := config.NodeRef{Class: "task", ID: "end"}
+
+	q.Publish(Event{Tag: "inbound.push"}) // not part of the run
+	q.Publish(Event{RunRef: ref, Tag: "trigger.good", SourceNode: trig, Good: true})
+	clk.Advance(time.Second)
+	q.Publish(UpdateEvent(ref, build, StreamStdout, 1, "building"))
+	clk.Advance(90 * time.Second)
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good", SourceNode: build, Good: true})
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow, SourceNode: end, Good: true})
+
+	var s Event
+	select {
+	case s = <-summaries:
+	case <-time.After(time.Second):
+		t.Fatal("no summary event")
+	}
+	if !s.RunRef.Equal(ref) || !s.Good {
+		t.Error("bad summary event", s.RunRef, s.Good)
+	}
+	if s.Opts["good"] != true {
+		t.Error("bad good status", s.Opts["good"])
+	}
+	if s.Opts["duration"] != float64(91) {
+		t.Error("bad duration", s.Opts["duration"])
+	}
+	if s.Opts["nodes"] != 3 {
+		t.Error("bad node count", s.Opts["nodes"])
+	}
+	if s.Opts["events"] != 4 {
+		t.Error("bad event count", s.Opts["events"])
+	}
+
+	h := q.History(ref)
+	if len(h) < 4 || h[0].Tag != "trigger.good" || h[3].Tag != TagEndFlow {
+		t.Error("bad history", len(h))
+	}
+}
+
+func TestHistoryLimits(t *testing.T) {
+	q := NewQueue(WithRunHistory(3, 1))
+	r1, r2 := testRef(1), testRef(2)
+	for i := 0; i < 5; i++ {
+		q.Publish(Event{RunRef: r1, Tag: "sys.node.update"})
+	}
+	h := q.History(r1)
+	if len(h) != 3 || h[0].ID != 3 {
+		t.Fatal("history should be trimmed to the most recent", len(h))
+	}
+	q.Publish(Event{RunRef: r1, Tag: TagEndFlow})
+	q.Publish(Event{RunRef: r2, Tag: TagEndFlow})
+	if len(q.History(r1)) != 0 {
+		t.Error("oldest ended run should have been dropped")
+	}
+	if len(q.History(r2)) == 0 {
+		t.Error("most recent ended run should be retained")
+	}
+}
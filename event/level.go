@@ -0,0 +1,50 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is how serious an event is
+type Level int
+
+// The levels of events, an event with no level has the Severity given by Good
+const (
+	LevelUnset Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"", "debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level named s, one of debug, info, warn or error
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, n := range levelNames {
+		if i > 0 && (n == s || (n == "warn" && s == "warning")) {
+			return Level(i), nil
+		}
+	}
+	return LevelUnset, fmt.Errorf("unknown level '%s'", s)
+}
+
+// Severity returns the Level of the event, or when it has none, error for the bad result
+// of a node and info for everything else
+func (e Event) Severity() Level {
+	if e.Level != LevelUnset {
+		return e.Level
+	}
+	if isTerminal(e) && !e.Good {
+		return LevelError
+	}
+	return LevelInfo
+}
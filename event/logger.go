@@ -0,0 +1,32 @@
+package event
+
+import "github.com/floeit/floe/log"
+
+// Logger is where a queue writes its logs
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// WithLogger sets the logger of the queue, replacing the floe log package, so that the
+// logs of one queue can be captured or told apart from those of others.
+func WithLogger(l Logger) QueueOption {
+	return func(q *Queue) {
+		q.logger = l
+	}
+}
+
+// queueLogged is an observer that logs to the logger of the queue it is registered on
+type queueLogged interface {
+	useLogger(Logger)
+}
+
+func (q *Queue) getLogger() Logger {
+	q.Lock()
+	defer q.Unlock()
+	q.init()
+	return q.logger
+}
+
+// defaultLogger is the floe log package
+var defaultLogger Logger = log.Log{}
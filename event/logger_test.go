@@ -0,0 +1,70 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureLogger keeps every line logged
+type captureLogger struct {
+	sync.Mutex
+	lines []string
+}
+
+func (c *captureLogger) Debugf(format string, args ...interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	c.lines = append(c.lines, "debug "+fmt.Sprintf(format, args...))
+}
+
+func (c *captureLogger) Errorf(format string, args ...interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	c.lines = append(c.lines, "error "+fmt.Sprintf(format, args...))
+}
+
+func TestWithLogger(t *testing.T) {
+	l := &captureLogger{}
+	q := NewQueue(WithLogger(l))
+	q.Publish(Event{RunRef: testRef(3), Tag: "task.build.good", Good: true})
+
+	l.Lock()
+	defer l.Unlock()
+	if len(l.lines) != 1 {
+		t.Fatal("expected one publish line", l.lines)
+	}
+	line := l.lines[0]
+	if !strings.HasPrefix(line, "debug queue publish: ") ||
+		!strings.Contains(line, "<runref_build-1_h1-3-ev:1> task.build.good good") {
+		t.Error("wrong publish line", line)
+	}
+}
+
+func TestObserversLogToQueue(t *testing.T) {
+	l := &captureLogger{}
+	q := NewQueue(WithLogger(l))
+
+	b := NewBroker(&fakeBroker{down: true}, JSONCodec{})
+	q.Register(b)
+	b.Notify(Event{Tag: "task.build.good"})
+
+	q.RegisterWith(&flaky{failing: true}, WithBreaker(1, time.Minute))
+	q.observers[1].deliver(Event{Tag: "fail"})
+
+	l.Lock()
+	defer l.Unlock()
+	for _, want := range []string{"debug broker - publish failed", "debug breaker - observer failing, opening"} {
+		found := false
+		for _, line := range l.lines {
+			if strings.HasPrefix(line, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("the queue logger did not get %q %v", want, l.lines)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package event
+
+import (
+	"time"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// WithLoopBreaker protects the host from runs stuck in an event loop, such as a node whose
+// good event triggers the same node. If a run publishes the same tag more than max times
+// within window a TagRunLoop event is published and that tag is dropped for the rest of
+// the run. System events, such as node updates, are not counted.
+func WithLoopBreaker(max int, window time.Duration) QueueOption {
+	return func(q *Queue) {
+		q.loopMax = max
+		q.loopWindow = window
+	}
+}
+
+// loopCount counts the events of a tag in a run within the current window
+type loopCount struct {
+	start  time.Time
+	n      int
+	broken bool
+}
+
+// loopFree returns false if e should be dropped as its tag is looping in its run, and
+// the loop event to publish the first time the loop is broken.
+// loopFree must be called in the lock.
+func (q *Queue) loopFree(e Event, now time.Time) (bool, *Event) {
+	if q.loopMax <= 0 || !e.RunRef.Adopted() {
+		return true, nil
+	}
+	k := e.RunRef.key()
+	if e.Tag == TagEndFlow {
+		delete(q.loops, k)
+		return true, nil
+	}
+	if e.IsSystem() {
+		return true, nil
+	}
+	if q.loops == nil {
+		q.loops = map[runKey]map[string]*loopCount{}
+	}
+	tags := q.loops[k]
+	if tags == nil {
+		tags = map[string]*loopCount{}
+		q.loops[k] = tags
+	}
+	c, ok := tags[e.Tag]
+	if !ok {
+		c = &loopCount{start: now}
+		tags[e.Tag] = c
+	}
+	if c.broken {
+		return false, nil
+	}
+	if now.Sub(c.start) > q.loopWindow {
+		c.start, c.n = now, 0
+	}
+	c.n++
+	if c.n <= q.loopMax {
+		return true, nil
+	}
+	c.broken = true
+	q.logger.Errorf("<%s> - queue - run is looping on %s, dropping its events", e.RunRef, e.Tag)
+	return false, &Event{
+		RunRef:     e.RunRef,
+		SourceNode: e.SourceNode,
+		Tag:        TagRunLoop,
+		Opts: nt.Opts{
+			"tag":    e.Tag,
+			"max":    q.loopMax,
+			"window": q.loopWindow.String(),
+		},
+	}
+}
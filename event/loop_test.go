@@ -0,0 +1,52 @@
+package event
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoopBreaker(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk), WithLoopBreaker(20, time.Second))
+	ref := testRef(1)
+
+	// a node whose good event triggers itself
+	var runs int32
+	q.Subscribe(&listener{what: func(e Event) {
+		atomic.AddInt32(&runs, 1)
+		q.Publish(Event{RunRef: ref, Tag: "task.a.good", Good: true})
+	}}, "task.a.good")
+	loops := make(chanObs, 10)
+	q.Subscribe(loops, TagRunLoop)
+
+	q.Publish(Event{RunRef: ref, Tag: "task.a.good", Good: true})
+	e := next(t, loops)
+	if e.Opts["tag"] != "task.a.good" || !e.RunRef.Equal(ref) {
+		t.Error("bad loop event", e)
+	}
+	if n := atomic.LoadInt32(&runs); n != 20 {
+		t.Error("loop should be broken at the threshold", n)
+	}
+
+	// the loop stays broken and is only reported once
+	clk.Advance(2 * time.Second)
+	q.Publish(Event{RunRef: ref, Tag: "task.a.good", Good: true})
+	if n := atomic.LoadInt32(&runs); n != 20 || len(loops) != 0 {
+		t.Error("broken loop should stay broken", n)
+	}
+
+	// a tag repeating slower than the threshold is not a loop
+	other := testRef(2)
+	for i := 0; i < 30; i++ {
+		clk.Advance(100 * time.Millisecond)
+		q.Publish(Event{RunRef: other, Tag: "task.b.good", Good: true})
+	}
+	b := &bytes.Buffer{}
+	q.WriteMetrics(b)
+	if !strings.Contains(b.String(), `floe_events_dropped_total{reason="loop"} 2`) {
+		t.Error("only the looping events should be dropped", b.String())
+	}
+}
@@ -0,0 +1,68 @@
+package event
+
+import "sync"
+
+// mailbox buffers events for an observer delivering them with at most max concurrent
+// workers, a max of 1 delivers strictly in priority then publish order.
+type mailbox struct {
+	sync.Mutex
+	pending []Event
+	workers int
+	max     int
+	budget  *BufferGroup // optional budget shared with other mailboxes
+}
+
+// WithMaxConcurrent limits the observer to n concurrent calls to Notify, events
+// published while n calls are in progress are buffered, and buffered events of higher
+// Priority are delivered first. With n of 1 the observer is notified serially in
+// publish order within each priority, the order the queue gave the events their IDs, even
+// when published concurrently. Zero, the default, is unlimited.
+func WithMaxConcurrent(n int) RegisterOption {
+	return func(r *registration) {
+		if n > 0 {
+			r.box = &mailbox{max: n}
+		}
+	}
+}
+
+// post buffers e ahead of any buffered events of lower priority, starting a worker to
+// deliver it if below the max. post returns false if the budget has no room for e.
+func (m *mailbox) post(e Event, deliver func(Event)) bool {
+	if m.budget != nil && !m.budget.take() {
+		return false
+	}
+	m.Lock()
+	defer m.Unlock()
+	i := len(m.pending)
+	for i > 0 && m.pending[i-1].Priority < e.Priority {
+		i--
+	}
+	m.pending = append(m.pending, Event{})
+	copy(m.pending[i+1:], m.pending[i:])
+	m.pending[i] = e
+	if m.workers < m.max {
+		m.workers++
+		go m.run(deliver)
+	}
+	return true
+}
+
+// run delivers pending events until there are none left
+func (m *mailbox) run(deliver func(Event)) {
+	for {
+		m.Lock()
+		if len(m.pending) == 0 {
+			m.workers--
+			m.Unlock()
+			return
+		}
+		e := m.pending[0]
+		m.pending[0] = Event{}
+		m.pending = m.pending[1:]
+		m.Unlock()
+		if m.budget != nil {
+			m.budget.release()
+		}
+		deliver(e)
+	}
+}
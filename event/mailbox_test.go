@@ -0,0 +1,159 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowObs records the peak number of concurrent calls to Notify
+type slowObs struct {
+	sync.Mutex
+	wg     sync.WaitGroup
+	active int
+	peak   int
+	tags   []string
+}
+
+func (o *slowObs) Notify(e Event) {
+	o.Lock()
+	o.active++
+	if o.active > o.peak {
+		o.peak = o.active
+	}
+	o.tags = append(o.tags, e.Tag)
+	o.Unlock()
+
+	time.Sleep(2 * time.Millisecond)
+
+	o.Lock()
+	o.active--
+	o.Unlock()
+	o.wg.Done()
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	tags := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	for _, max := range []int{1, 3} {
+		q := NewQueue()
+		o := &slowObs{}
+		q.RegisterWith(o, WithMaxConcurrent(max))
+		o.wg.Add(len(tags))
+		for _, tag := range tags {
+			q.Publish(Event{Tag: tag})
+		}
+		o.wg.Wait()
+
+		if o.peak > max {
+			t.Errorf("max %d exceeded with %d concurrent", max, o.peak)
+		}
+		if len(o.tags) != len(tags) {
+			t.Errorf("max %d delivered %d events", max, len(o.tags))
+		}
+		if max != 1 {
+			continue
+		}
+		for i, tag := range tags {
+			if o.tags[i] != tag {
+				t.Error("serial delivery out of order", o.tags)
+				break
+			}
+		}
+	}
+}
+
+// gateObs blocks on the first event until released
+type gateObs struct {
+	release chan struct{}
+	got     chan Event
+	once    sync.Once
+}
+
+func (o *gateObs) Notify(e Event) {
+	o.once.Do(func() { <-o.release })
+	o.got <- e
+}
+
+func TestPriorityDelivery(t *testing.T) {
+	q := NewQueue()
+	o := &gateObs{release: make(chan struct{}), got: make(chan Event, 10)}
+	q.RegisterWith(o, WithMaxConcurrent(1))
+
+	q.Publish(Event{Tag: "blocking"})
+	// give the worker time to take the blocking event
+	time.Sleep(10 * time.Millisecond)
+	q.Publish(Event{Tag: "low.1"})
+	q.Publish(Event{Tag: "low.2"})
+	q.Publish(Event{Tag: "approve", Priority: 10})
+	q.Publish(Event{Tag: "mid", Priority: 5})
+	close(o.release)
+
+	for _, tag := range []string{"blocking", "approve", "mid", "low.1", "low.2"} {
+		select {
+		case e := <-o.got:
+			if e.Tag != tag {
+				t.Errorf("got %s wanted %s", e.Tag, tag)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+}
+
+// idObs records the IDs of the events sent to it
+type idObs struct {
+	sync.Mutex
+	wg  sync.WaitGroup
+	ids []int64
+}
+
+func (o *idObs) Notify(e Event) {
+	o.Lock()
+	o.ids = append(o.ids, e.ID)
+	o.Unlock()
+	o.wg.Done()
+}
+
+func TestSerialConcurrentPublishers(t *testing.T) {
+	q := NewQueue()
+	o := &idObs{}
+	q.RegisterWith(o, WithMaxConcurrent(1))
+
+	const publishers, each = 10, 50
+	o.wg.Add(publishers * each)
+	for p := 0; p < publishers; p++ {
+		go func() {
+			for i := 0; i < each; i++ {
+				q.Publish(Event{Tag: "concurrent"})
+			}
+		}()
+	}
+	o.wg.Wait()
+
+	for i := 1; i < len(o.ids); i++ {
+		if o.ids[i] <= o.ids[i-1] {
+			t.Fatalf("event %d delivered after %d", o.ids[i], o.ids[i-1])
+		}
+	}
+}
+
+func TestDropHookPublishesInBatch(t *testing.T) {
+	var q *Queue
+	q = NewQueue(WithDropHook(func(d DroppedEvent) {
+		if d.Reason == DropDuplicate {
+			q.Publish(Event{Tag: "dup.seen"})
+		}
+	}))
+	c := make(chanObs, 10)
+	q.RegisterWith(c, WithoutDuplicates(), WithMaxConcurrent(1))
+
+	q.PublishAll([]Event{{Tag: "same"}, {Tag: "same"}, {Tag: "other"}})
+
+	got := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		got[next(t, c).Tag] = true
+	}
+	if !got["same"] || !got["other"] || !got["dup.seen"] {
+		t.Error("wrong events after dropping a duplicate", got)
+	}
+}
@@ -0,0 +1,113 @@
+package event
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maxMetricTags is how many distinct tags are counted separately, the events of any
+// further tags are counted under otherTag to bound the metric cardinality.
+const (
+	maxMetricTags = 100
+	otherTag      = "other"
+)
+
+// metrics are the counters exposed by WriteMetrics, they are updated in the queue lock
+type metrics struct {
+	events      map[string]int64 // published events by tag
+	dropped     map[string]int64 // events not published by reason
+	deadLetters int64
+}
+
+// count adds the published event e. count must be called in the lock.
+func (m *metrics) count(e Event) {
+	if m.events == nil {
+		m.events = map[string]int64{}
+	}
+	tag := e.Tag
+	if _, ok := m.events[tag]; !ok && len(m.events) >= maxMetricTags {
+		tag = otherTag
+	}
+	m.events[tag]++
+}
+
+// drop counts an event not published for the reason. drop must be called in the lock.
+func (m *metrics) drop(reason string) {
+	if m.dropped == nil {
+		m.dropped = map[string]int64{}
+	}
+	m.dropped[reason]++
+}
+
+// WriteMetrics writes the queue counters to w in the Prometheus text exposition format,
+// which OpenMetrics scrapers also accept.
+func (q *Queue) WriteMetrics(w io.Writer) error {
+	q.RLock()
+	events := sortedCounts(q.metrics.events)
+	dropped := sortedCounts(q.metrics.dropped)
+	observers := len(q.observers)
+	deadLetters := q.metrics.deadLetters
+	tenants := map[string]int64{}
+	tenantDrops := map[string]int64{}
+	for t, u := range q.tenants {
+		tenants[t] = u.published
+		tenantDrops[t] = u.dropped
+	}
+	q.RUnlock()
+
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "# HELP floe_events_total Events published by tag.")
+	fmt.Fprintln(b, "# TYPE floe_events_total counter")
+	for _, c := range events {
+		fmt.Fprintf(b, "floe_events_total{tag=\"%s\"} %d\n", escapeLabel(c.key), c.n)
+	}
+	fmt.Fprintln(b, "# HELP floe_events_dropped_total Events not published by reason.")
+	fmt.Fprintln(b, "# TYPE floe_events_dropped_total counter")
+	for _, c := range dropped {
+		fmt.Fprintf(b, "floe_events_dropped_total{reason=\"%s\"} %d\n", escapeLabel(c.key), c.n)
+	}
+	if len(tenants) > 0 {
+		fmt.Fprintln(b, "# HELP floe_tenant_events_total Events published by tenant.")
+		fmt.Fprintln(b, "# TYPE floe_tenant_events_total counter")
+		for _, c := range sortedCounts(tenants) {
+			fmt.Fprintf(b, "floe_tenant_events_total{tenant=\"%s\"} %d\n", escapeLabel(c.key), c.n)
+		}
+		fmt.Fprintln(b, "# HELP floe_tenant_events_dropped_total Events dropped for exceeding the tenant quota.")
+		fmt.Fprintln(b, "# TYPE floe_tenant_events_dropped_total counter")
+		for _, c := range sortedCounts(tenantDrops) {
+			fmt.Fprintf(b, "floe_tenant_events_dropped_total{tenant=\"%s\"} %d\n", escapeLabel(c.key), c.n)
+		}
+	}
+	fmt.Fprintln(b, "# HELP floe_dead_letters_total Events that could not be delivered to an observer.")
+	fmt.Fprintln(b, "# TYPE floe_dead_letters_total counter")
+	fmt.Fprintf(b, "floe_dead_letters_total %d\n", deadLetters)
+	fmt.Fprintln(b, "# HELP floe_observers Observers registered on the queue.")
+	fmt.Fprintln(b, "# TYPE floe_observers gauge")
+	fmt.Fprintf(b, "floe_observers %d\n", observers)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type count struct {
+	key string
+	n   int64
+}
+
+func sortedCounts(m map[string]int64) []count {
+	res := make([]count, 0, len(m))
+	for k, n := range m {
+		res = append(res, count{key: k, n: n})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].key < res[j].key
+	})
+	return res
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
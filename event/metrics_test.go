@@ -0,0 +1,57 @@
+package event
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	q := NewQueue(WithRunRateLimit(1, 1))
+	q.Register(&listener{what: func(Event) {}})
+	ref := testRef(1)
+	q.Publish(Event{RunRef: ref, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good"}) // over the rate limit
+	for i := 0; i < maxMetricTags+20; i++ {
+		q.Publish(Event{Tag: fmt.Sprintf("inbound.tag%d", i)})
+	}
+	q.deadLetter(Event{Tag: "x"}, DropFailed, "test")
+
+	buf := &bytes.Buffer{}
+	if err := q.WriteMetrics(buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	line := regexp.MustCompile(`^[a-z_]+(\{[a-z]+="[^"]*"\})? [0-9]+$`)
+	comment := regexp.MustCompile(`^# (HELP|TYPE) [a-z_]+ .+$`)
+	tags := 0
+	for _, l := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if !line.MatchString(l) && !comment.MatchString(l) {
+			t.Errorf("malformed line %q", l)
+		}
+		if strings.HasPrefix(l, "floe_events_total{") {
+			tags++
+		}
+	}
+	if tags != maxMetricTags+1 {
+		t.Error("tags should be bounded with the rest as other", tags)
+	}
+	for _, exp := range []string{
+		`floe_events_total{tag="trigger.good"} 1`,
+		`floe_events_total{tag="other"} `,
+		`floe_events_dropped_total{reason="rate_limit"} 1`,
+		`floe_events_total{tag="sys.run.throttled"} 1`,
+		`floe_dead_letters_total 1`,
+		`floe_observers 1`,
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("missing %q", exp)
+		}
+	}
+	if escapeLabel(`a"b\c`) != `a\"b\\c` {
+		t.Error("labels not escaped")
+	}
+}
@@ -0,0 +1,105 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Middleware decorates an observer with behaviour common to many, such as logging
+type Middleware func(Observer) Observer
+
+// Chain returns o decorated by each of mw, the first is the outermost so it sees each
+// event first and is the last to finish with it.
+func Chain(o Observer, mw ...Middleware) Observer {
+	for i := len(mw) - 1; i >= 0; i-- {
+		o = mw[i](o)
+	}
+	return o
+}
+
+// around is an observer that calls its hook around the delivery of each event to inner.
+// It passes on NotifyCtx and Interests so decorating an observer does not change how
+// the queue treats it.
+type around struct {
+	inner Observer
+	hook  func(e Event, next func() error) error
+}
+
+// Notify satisfies Observer
+func (a *around) Notify(e Event) {
+	a.hook(e, func() error {
+		a.inner.Notify(e)
+		return nil
+	})
+}
+
+// NotifyCtx satisfies ContextObserver
+func (a *around) NotifyCtx(ctx context.Context, e Event) error {
+	return a.hook(e, func() error {
+		if co, ok := a.inner.(ContextObserver); ok {
+			return co.NotifyCtx(ctx, e)
+		}
+		a.inner.Notify(e)
+		return nil
+	})
+}
+
+// Interests satisfies Interested, no interests means all events
+func (a *around) Interests() []string {
+	if i, ok := a.inner.(Interested); ok {
+		return i.Interests()
+	}
+	return nil
+}
+
+// LoggingMiddleware logs each event delivered to the observer, how long it took, and
+// any error it returned.
+func LoggingMiddleware(l Logger) Middleware {
+	return func(o Observer) Observer {
+		return &around{inner: o, hook: func(e Event, next func() error) error {
+			start := time.Now()
+			err := next()
+			if err != nil {
+				l.Errorf("<%s> - observer %T failed on %s after %s: %v", e.RunRef, o, e.Tag, time.Since(start), err)
+				return err
+			}
+			l.Debugf("<%s> - observer %T handled %s in %s", e.RunRef, o, e.Tag, time.Since(start))
+			return nil
+		}}
+	}
+}
+
+// ObserverMetrics counts the events handled by the observers decorated with its Middleware
+type ObserverMetrics struct {
+	mu       sync.Mutex
+	handled  int64
+	failed   int64
+	duration time.Duration
+}
+
+// Middleware returns the middleware adding to m
+func (m *ObserverMetrics) Middleware() Middleware {
+	return func(o Observer) Observer {
+		return &around{inner: o, hook: func(e Event, next func() error) error {
+			start := time.Now()
+			err := next()
+			m.mu.Lock()
+			m.handled++
+			if err != nil {
+				m.failed++
+			}
+			m.duration += time.Since(start)
+			m.mu.Unlock()
+			return err
+		}}
+	}
+}
+
+// Counts returns the events handled, how many of them failed, and the total time spent
+// handling them
+func (m *ObserverMetrics) Counts() (handled, failed int64, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.handled, m.failed, m.duration
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"strings"
+	"testing"
+)
+
+// tracer is a middleware recording when it runs around the observer in calls
+func tracer(name string, calls *[]string) Middleware {
+	return func(o Observer) Observer {
+		return &around{inner: o, hook: func(e Event, next func() error) error {
+			*calls = append(*calls, name+" before")
+			err := next()
+			*calls = append(*calls, name+" after")
+			return err
+		}}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	inner := &listener{what: func(e Event) {
+		calls = append(calls, "notify "+e.Tag)
+	}}
+	o := Chain(inner, tracer("a", &calls), tracer("b", &calls))
+	o.Notify(Event{Tag: "tag"})
+
+	want := []string{"a before", "b before", "notify tag", "b after", "a after"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Error("middlewares ran in the wrong order", calls)
+	}
+
+	// the shipped middlewares on a queue
+	l := &captureLogger{}
+	m := &ObserverMetrics{}
+	// the outermost middleware tells when the others are done
+	done := make(chanObs, 10)
+	signal := func(o Observer) Observer {
+		return &around{inner: o, hook: func(e Event, next func() error) error {
+			err := next()
+			done <- e
+			return err
+		}}
+	}
+	q := NewQueue()
+	q.Register(Chain(&listener{what: func(Event) {}}, signal, LoggingMiddleware(l), m.Middleware()))
+	q.Publish(Event{Tag: "task.build.good"})
+	next(t, done)
+	q.Publish(Event{Tag: "task.test.good"})
+	next(t, done)
+
+	if handled, failed, _ := m.Counts(); handled != 2 || failed != 0 {
+		t.Error("bad metrics", handled, failed)
+	}
+	l.Lock()
+	defer l.Unlock()
+	if len(l.lines) != 2 || !strings.Contains(l.lines[0], "handled task.build.good") {
+		t.Error("bad logs", l.lines)
+	}
+}
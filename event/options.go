@@ -0,0 +1,45 @@
+package event
+
+// QueueOption configures a Queue created by NewQueue
+type QueueOption func(*Queue)
+
+// WithClock sets the clock the queue uses for all timing
+func WithClock(c Clock) QueueOption {
+	return func(q *Queue) {
+		q.clock = c
+	}
+}
+
+// WithTailSize sets how many of the most recent events are retained for Tail
+func WithTailSize(n int) QueueOption {
+	return func(q *Queue) {
+		q.tailSize = n
+	}
+}
+
+// WithRunRateLimit limits the events any single run can publish to rate per second,
+// allowing bursts of up to burst events. Events over the limit are dropped, and a single
+// TagRunThrottled event is published for the run. A rate of zero disables the limit.
+func WithRunRateLimit(rate float64, burst int) QueueOption {
+	return func(q *Queue) {
+		q.rate = rate
+		q.burst = burst
+	}
+}
+
+// WithRunHistory sets the maximum events retained in the history of each run, and
+// how many ended runs keep their history.
+func WithRunHistory(events, doneRuns int) QueueOption {
+	return func(q *Queue) {
+		q.runEvents = events
+		q.doneRuns = doneRuns
+	}
+}
+
+// WithMaxObservers limits how many observers can be registered on the queue, registering
+// more returns ErrTooManyObservers. Zero, the default, is unlimited.
+func WithMaxObservers(n int) QueueOption {
+	return func(q *Queue) {
+		q.maxObservers = n
+	}
+}
@@ -0,0 +1,34 @@
+package event
+
+import (
+	"strings"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// OptPath returns the value at the dotted path into the nested maps in the event Opts,
+// for example "push.head_commit.id". If any part of the path is missing or is not a map
+// then false is returned.
+func (e Event) OptPath(path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	var v interface{} = map[string]interface{}(e.Opts)
+	for _, k := range strings.Split(path, ".") {
+		var m map[string]interface{}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			m = t
+		case nt.Opts:
+			m = t
+		default:
+			return nil, false
+		}
+		var ok bool
+		v, ok = m[k]
+		if !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
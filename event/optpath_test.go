@@ -0,0 +1,53 @@
+package event
+
+import (
+	"testing"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestOptPath(t *testing.T) {
+	e := Event{
+		Opts: nt.Opts{
+			"push": map[string]interface{}{
+				"ref": "refs/heads/master",
+				"head_commit": map[string]interface{}{
+					"id": "abc123",
+				},
+			},
+			"sub":  nt.Opts{"a": 1},
+			"name": "flow",
+		},
+	}
+
+	fix := []struct {
+		path  string
+		found bool
+		val   interface{}
+	}{
+		{path: "push.head_commit.id", found: true, val: "abc123"},
+		{path: "push.ref", found: true, val: "refs/heads/master"},
+		{path: "sub.a", found: true, val: 1},
+		{path: "name", found: true, val: "flow"},
+		{path: "push.head_commit.missing"},
+		{path: "missing.id"},
+		{path: "name.id"},                  // name is a string not a map
+		{path: "push.head_commit.id.more"}, // id is a string
+		{path: ""},
+	}
+	for i, f := range fix {
+		v, ok := e.OptPath(f.path)
+		if ok != f.found {
+			t.Errorf("%d - %s found should be %v", i, f.path, f.found)
+			continue
+		}
+		if ok && v != f.val {
+			t.Errorf("%d - %s got %v wanted %v", i, f.path, v, f.val)
+		}
+	}
+
+	// no opts at all
+	if _, ok := (Event{}).OptPath("a.b"); ok {
+		t.Error("empty event should not find anything")
+	}
+}
@@ -0,0 +1,35 @@
+package event
+
+import "sort"
+
+// originHost is the host that published the event, which its ID is unique within
+func (e Event) originHost() string {
+	if e.RunRef.ExecHost != "" {
+		return e.RunRef.ExecHost
+	}
+	return e.RunRef.Run.HostID
+}
+
+// Before is a total order of events from any hosts by publish time. Events published at
+// the same time are ordered by the host that published them, then by ID.
+func (e Event) Before(f Event) bool {
+	if !e.Time.Equal(f.Time) {
+		return e.Time.Before(f.Time)
+	}
+	if eh, fh := e.originHost(), f.originHost(); eh != fh {
+		return eh < fh
+	}
+	return e.ID < f.ID
+}
+
+// MergeStreams merges the event streams from several hosts into one ordered by Before
+func MergeStreams(streams ...[]Event) []Event {
+	var all []Event
+	for _, s := range streams {
+		all = append(all, s...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Before(all[j])
+	})
+	return all
+}
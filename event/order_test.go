@@ -0,0 +1,40 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeStreams(t *testing.T) {
+	t0 := time.Date(2017, 11, 1, 12, 0, 0, 0, time.UTC)
+	onHost := func(host string, id int64, at time.Time) Event {
+		ref := testRef(1)
+		ref.ExecHost = host
+		return Event{RunRef: ref, ID: id, Time: at}
+	}
+	// a burst on each host all in the same instant, with a ID that would mislead
+	a := []Event{onHost("a", 5, t0), onHost("a", 6, t0), onHost("a", 7, t0.Add(time.Millisecond))}
+	b := []Event{onHost("b", 1, t0), onHost("b", 2, t0)}
+	// a general event with no exec host falls back to the run host
+	c := []Event{{RunRef: RunRef{Run: HostedIDRef{HostID: "c"}}, ID: 1, Time: t0}}
+
+	exp := []string{"a-5", "a-6", "b-1", "b-2", "c-1", "a-7"}
+	for _, order := range [][][]Event{{a, b, c}, {c, b, a}, {b, c, a}} {
+		got := MergeStreams(order...)
+		for i, e := range got {
+			s := e.originHost() + "-" + string(rune('0'+e.ID))
+			if s != exp[i] {
+				t.Errorf("%d got %s wanted %s", i, s, exp[i])
+			}
+		}
+	}
+
+	// a strict total order, never both before each other
+	x, y := onHost("a", 1, t0), onHost("b", 1, t0)
+	if x.Before(y) == y.Before(x) {
+		t.Error("before is not a total order")
+	}
+	if x.Before(x) {
+		t.Error("an event is not before itself")
+	}
+}
@@ -0,0 +1,90 @@
+package event
+
+import "sync"
+
+// pauser buffers the events of a paused registration, the zero value is not paused
+type pauser struct {
+	sync.Mutex
+	paused bool
+	max    int
+	held   []Event
+}
+
+// PauseObserver stops events being delivered to the observer o without unregistering it,
+// the events are kept, up to max of them, and delivered in order by ResumeObserver.
+// Events beyond max are dead lettered. Unlike quiescing a run this is for a single
+// observer, for example a UI client that has gone to the background. It returns false
+// if o is not registered.
+func (q *Queue) PauseObserver(o Observer, max int) bool {
+	found := false
+	for _, r := range q.registrations(o) {
+		r.pause.Lock()
+		if !r.pause.paused {
+			r.pause.paused = true
+			r.pause.held = nil
+		}
+		r.pause.max = max
+		r.pause.Unlock()
+		found = true
+	}
+	return found
+}
+
+// ResumeObserver restarts delivery to the observer o, first sending it the events kept
+// while it was paused in the order they were published. It returns false if o is
+// not registered.
+func (q *Queue) ResumeObserver(o Observer) bool {
+	found := false
+	for _, r := range q.registrations(o) {
+		r.pause.Lock()
+		held := r.pause.held
+		r.pause.paused = false
+		r.pause.held = nil
+		r.pause.Unlock()
+		found = true
+		if r.box != nil {
+			for _, e := range held {
+				if !r.box.post(e, r.deliver) {
+					q.deadLetter(e, DropOverflow, "buffer group full")
+				}
+			}
+			continue
+		}
+		if len(held) > 0 {
+			go func(r *registration) {
+				for _, e := range held {
+					r.deliver(e)
+				}
+			}(r)
+		}
+	}
+	return found
+}
+
+// registrations returns all the registrations of o
+func (q *Queue) registrations(o Observer) []*registration {
+	q.RLock()
+	defer q.RUnlock()
+	var regs []*registration
+	for _, r := range q.observers {
+		if sameObserver(r.o, o) {
+			regs = append(regs, r)
+		}
+	}
+	return regs
+}
+
+// held keeps e if the registration is paused, returning true if it was paused, along
+// with false if e could not be kept as the buffer is full.
+func (r *registration) held(e Event) (paused, kept bool) {
+	r.pause.Lock()
+	defer r.pause.Unlock()
+	if !r.pause.paused {
+		return false, false
+	}
+	if len(r.pause.held) >= r.pause.max {
+		return true, false
+	}
+	r.pause.held = append(r.pause.held, e)
+	return true, true
+}
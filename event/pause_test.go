@@ -0,0 +1,44 @@
+package event
+
+import "testing"
+
+func TestPauseObserver(t *testing.T) {
+	dead := make(chanObs, 10)
+	q := NewQueue(WithDeadLetter(dead))
+	c := make(chanObs, 10)
+	if q.PauseObserver(c, 3) {
+		t.Fatal("unregistered observer paused")
+	}
+	q.Register(c)
+
+	q.Publish(Event{Tag: "a"})
+	next(t, c)
+
+	if !q.PauseObserver(c, 3) {
+		t.Fatal("registered observer not paused")
+	}
+	for _, tag := range []string{"b", "c", "d", "e"} {
+		q.Publish(Event{Tag: tag})
+	}
+	if len(c) != 0 {
+		t.Fatal("paused observer was sent events")
+	}
+	// beyond the buffer limit events are dead lettered
+	if d := next(t, dead); d.Tag != "e" || d.Opts[DeadLetterKey] != "observer paused" {
+		t.Error("bad dead letter", d)
+	}
+
+	if !q.ResumeObserver(c) {
+		t.Fatal("registered observer not resumed")
+	}
+	for _, tag := range []string{"b", "c", "d"} {
+		if e := next(t, c); e.Tag != tag {
+			t.Errorf("flushed out of order, wanted %s got %s", tag, e.Tag)
+		}
+	}
+
+	q.Publish(Event{Tag: "f"})
+	if e := next(t, c); e.Tag != "f" {
+		t.Error("resumed observer not sent live events", e.Tag)
+	}
+}
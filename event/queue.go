@@ -0,0 +1,395 @@
+package event
+
+import (
+	"sync"
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// Queue is not strictly a queue, it just distributes all events to the observers.
+// Queues should be created with NewQueue, the zero value is usable but can not be configured.
+type Queue struct {
+	sync.RWMutex
+
+	idCounter   int64
+	execCounter int64
+	// observers are any entities that care about events emitted from the queue
+	observers    []*registration
+	maxObservers int
+	// groups are observers sharing events between them
+	groups map[string]*group
+	// tail retains the most recent events across all runs
+	tail     *ring
+	tailSize int
+	// clock is the time source, defaults to the real time
+	clock Clock
+	// logger defaults to the floe log package
+	logger Logger
+	// router matches events to observer subscriptions
+	router Router
+	// enricher is optional and adds display metadata to events
+	enricher *enricher
+	// alert is also sent all error events
+	alert *registration
+	// deadLetters receives events that could not be delivered
+	deadLetters Observer
+	// dropHook is told of every dropped event
+	dropHook func(DroppedEvent)
+	// redactor hides sensitive opts from observers that are not privileged
+	redactor *redactor
+	// rules change events as they are published
+	rules []Rule
+	// delayed are the pending delayed events of each run
+	delayed map[runKey]map[*Delayed]bool
+
+	// quiesced holds the events of runs whose delivery is paused
+	quiesced map[runKey][]Event
+
+	// metrics are the counters for WriteMetrics
+	metrics metrics
+
+	// parent is set if this queue is scoped to a node of the parents run
+	parent    *Queue
+	scopeRef  RunRef
+	scopeNode config.NodeRef
+	// ids allocates the run IDs of runs started by the queue
+	ids HostIDAllocator
+	// copyPolicy is the default for whether observers share events
+	copyPolicy CopyPolicy
+
+	// optional hash chaining of events
+	chain     bool
+	chainHead string // hash of the last event published outside of a run
+
+	// order posts the events to the observers in the order they were stamped
+	order order
+
+	// per run rate limiting
+	rate    float64
+	burst   int
+	buckets map[runKey]*bucket
+
+	// loop breaking of a tag repeating too often in a run
+	loopMax    int
+	loopWindow time.Duration
+	loops      map[runKey]map[string]*loopCount
+
+	// per tenant quotas
+	tenantOf     func(RunRef) string
+	quotaDefault Quota
+	quotas       map[string]Quota
+	tenants      map[string]*tenantUsage
+
+	// stale detection of runs with no recent events
+	staleAfter time.Duration
+	stale      map[runKey]*staleWatch
+
+	// strict rejects events inconsistent with their run
+	strict   bool
+	runFlows map[HostedIDRef]config.FlowRef // the flow of each active run
+	ended    map[runKey]map[execKey]string  // the end tag of each node execution
+
+	// runOpts are the opts merged across the events of each run
+	runOpts map[runKey]nt.Opts
+	// runLabels are the labels rules have added to each run
+	runLabels map[runKey]map[string]string
+
+	// causal is the Lamport clock per run
+	causal map[runKey]int64
+
+	// active are the runs that have not yet ended
+	active map[runKey]activeRun
+
+	// persist decides which events ExportRun archives
+	persist func(Event) bool
+
+	// sealed are the recent published events checked for changes in debug builds
+	sealed []sealedOpts
+
+	// compaction decides which events the history retains for each flow
+	compaction map[config.FlowRef]Compaction
+
+	// history retains the events of each run
+	history   map[runKey]*runHistory
+	done      []runKey // ended runs, oldest first
+	runEvents int      // max events per run
+	doneRuns  int      // max ended runs retained
+}
+
+// NewQueue returns a Queue configured with any options
+func NewQueue(opts ...QueueOption) *Queue {
+	q := &Queue{}
+	for _, o := range opts {
+		o(q)
+	}
+	q.init()
+	return q
+}
+
+// init sets up any defaults not already set, so that zero value queues work.
+// init must be called in the lock.
+func (q *Queue) init() {
+	if q.tail == nil {
+		if q.tailSize == 0 {
+			q.tailSize = defaultTailSize
+		}
+		q.tail = newRing(q.tailSize)
+	}
+	if q.clock == nil {
+		q.clock = realClock{}
+	}
+	if q.logger == nil {
+		q.logger = defaultLogger
+	}
+	if q.router == nil {
+		q.router = TagRouter{}
+	}
+	if q.runEvents == 0 {
+		q.runEvents = defaultRunEvents
+	}
+	if q.doneRuns == 0 {
+		q.doneRuns = defaultDoneRuns
+	}
+}
+
+func (q *Queue) getClock() Clock {
+	q.Lock()
+	defer q.Unlock()
+	q.init()
+	return q.clock
+}
+
+// now must be called in the lock, after init
+func (q *Queue) now() time.Time {
+	return q.clock.Now()
+}
+
+// Publish sends an event to all the observers
+func (q *Queue) Publish(e Event) {
+	if q.parent != nil {
+		q.reparent(e)
+		return
+	}
+	q.publish(e)
+}
+
+// publish stamps e and sends it to the observers of this queue, unless its run is quiesced
+func (q *Queue) publish(e Event) {
+	q.Lock()
+	q.init()
+	if q.hold(e) {
+		q.Unlock()
+		return
+	}
+	q.send(e)
+}
+
+// send stamps e and sends it to the observers of this queue.
+// send must be called in the lock, after init, and unlocks it.
+func (q *Queue) send(e Event) {
+	d := q.stamp(e, false)
+	q.Unlock()
+	d.dispatch(q)
+}
+
+// delivery is a stamped event along with the queue state needed to send it
+type delivery struct {
+	e         Event
+	sent      bool   // false if the event was dropped
+	ticket    int64  // the order of sent events, see order
+	notice    *Event // published if the event was rate limited or broke a loop
+	drop      DropReason
+	dropHook  func(DroppedEvent)
+	summary   *Event // published once the event is sent
+	observers []*registration
+	router    Router
+	policy    CopyPolicy
+	alert     *registration
+	redactor  *redactor
+	logger    Logger
+}
+
+// stamp checks and stamps e, recording it in the queue state, returning what is needed
+// to send it. Events received from another host keep their time, see Receive.
+// stamp must be called in the lock, after init.
+func (q *Queue) stamp(e Event, received bool) delivery {
+	q.checkSealed()
+	e.Tag = NormalizeTag(e.Tag)
+	if len(q.rules) > 0 {
+		e = q.applyRules(e)
+	}
+	if !q.validTag(e) || !q.consistent(e) || !q.endsOnce(e) {
+		return q.dropped(e, DropRejected, nil)
+	}
+	now := q.now()
+	ok, notice := q.limit(e, now)
+	if !ok {
+		return q.dropped(e, DropRateLimit, notice)
+	}
+	if ok, notice = q.loopFree(e, now); !ok {
+		return q.dropped(e, DropLoop, notice)
+	}
+	if ok, notice = q.withinQuota(e, now); !ok {
+		return q.dropped(e, DropQuota, notice)
+	}
+	if q.enricher != nil {
+		// never modify the publishers opts
+		e = e.copy()
+		q.enricher.enrich(&e)
+	}
+	// events in a scoped queue keep the ID and times given by the parent
+	if q.parent == nil {
+		// grab the next event ID
+		q.idCounter++
+		e.ID = q.idCounter
+		if received {
+			q.stampReceived(&e)
+		} else {
+			e.Time = q.now()
+		}
+		q.stampCausal(&e)
+	}
+	if e.Opts == nil {
+		e.Opts = nt.Opts{}
+	}
+	q.link(&e)
+	q.metrics.count(e)
+	// the tail copy logs once for the event any opts no copy of it can hold
+	q.tail.add(e.copyWarn(warnUncopyable(q.logger, e)))
+	q.record(e)
+	q.track(e)
+	q.watch(e)
+	q.cancelDelayed(e)
+	q.accumulate(e)
+	q.seal(e)
+	// the run subscribers are still sent this event if it ends their run
+	observers := q.observers
+	summary := q.summary(e)
+	q.endRunSubscriptions(e, summary != nil)
+	return delivery{
+		e:         e,
+		sent:      true,
+		ticket:    q.order.take(),
+		summary:   summary,
+		observers: observers,
+		router:    q.router,
+		policy:    q.copyPolicy,
+		alert:     q.alert,
+		redactor:  q.redactor,
+		logger:    q.logger,
+	}
+}
+
+// dispatch sends the stamped event to the observers, it must be called outside the lock.
+func (d delivery) dispatch(q *Queue) {
+	d.finish(q, d.post(q))
+}
+
+// finish reports the drops of the posted event and publishes any events that follow it,
+// it must be called after post so the events it publishes are ordered after this one.
+func (d delivery) finish(q *Queue, drops []func()) {
+	if !d.sent {
+		if d.dropHook != nil {
+			d.dropHook(DroppedEvent{Event: d.e.copy(), Reason: d.drop})
+		}
+		if d.notice != nil {
+			q.publish(*d.notice)
+		}
+		return
+	}
+	for _, dropped := range drops {
+		dropped()
+	}
+	if d.summary != nil {
+		q.publish(*d.summary)
+	}
+}
+
+// post notifies the observers of the stamped event after those of any event stamped before
+// it, so an observer limited to one call at a time sees events in the order published.
+// post returns the reports of the event being dropped by any observer.
+func (d delivery) post(q *Queue) (drops []func()) {
+	if !d.sent {
+		return nil
+	}
+	e := d.e
+
+	// node updates can be noisy - an event is issued for every line of output
+	// if e.Tag != "sys.node.update" {
+	// for helpfulness indicate if this event was issued by an already adopted flow
+	isTrig := " (trigger)"
+	if e.RunRef.Adopted() {
+		isTrig = ""
+	} else if e.RunRef.IsZero() {
+		isTrig = " (general)"
+	}
+	d.logger.Debugf("queue publish%s: %s %s", isTrig, e, e.logFields())
+	// }
+
+	q.order.wait(d.ticket)
+	defer q.order.done()
+
+	// and notify all observers - in background goroutines
+	notify := func(r *registration, ev Event) {
+		if dropped := r.notify(ev); dropped != nil {
+			drops = append(drops, dropped)
+		}
+	}
+	var shared, sharedRedacted, redacted *Event
+	for _, r := range d.observers {
+		// general events are not part of any run
+		if r.runScoped && e.RunRef.IsZero() {
+			continue
+		}
+		if !r.accepts(e) || !d.router.Matches(e, r.sub) {
+			continue
+		}
+		ev, sh := e, &shared
+		if d.redactor != nil && !r.privileged {
+			if redacted == nil {
+				rd := d.redactor.redact(e)
+				redacted = &rd
+			}
+			ev, sh = *redacted, &sharedRedacted
+		}
+		if r.shares(d.policy) {
+			if *sh == nil {
+				c := ev.copy()
+				*sh = &c
+			}
+			notify(r, **sh)
+			continue
+		}
+		// send separate copies to each observer to avoid any races
+		notify(r, ev.copy())
+	}
+	if d.alert != nil && d.alert.accepts(e) {
+		ev := e
+		if d.redactor != nil && !d.alert.privileged {
+			ev = d.redactor.redact(e)
+		}
+		notify(d.alert, ev.copy())
+	}
+	return drops
+}
+
+// Tail returns the most recent n events published on this queue across all runs,
+// oldest first. At most the size of the tail ring is retained.
+func (q *Queue) Tail(n int) []Event {
+	q.RLock()
+	defer q.RUnlock()
+	if q.tail == nil {
+		return nil
+	}
+	return q.tail.last(n)
+}
+
+// NewExecID returns a new ID unique on this queue, to identify a node execution
+func (q *Queue) NewExecID() int64 {
+	q.Lock()
+	defer q.Unlock()
+	q.execCounter++
+	return q.execCounter
+}
@@ -0,0 +1,40 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewQueue(t *testing.T) {
+	// with no options the defaults are set up
+	q := NewQueue()
+	if q.clock == nil || q.tail == nil || len(q.tail.events) != defaultTailSize {
+		t.Error("queue defaults not set")
+	}
+
+	clk := NewFakeClock()
+	q = NewQueue(WithClock(clk), WithTailSize(2), WithRunRateLimit(5, 10))
+	if q.getClock() != clk {
+		t.Error("clock option not applied")
+	}
+	if q.rate != 5 || q.burst != 10 {
+		t.Error("rate limit option not applied", q.rate, q.burst)
+	}
+	for i := 0; i < 3; i++ {
+		q.Publish(Event{Tag: "foo"})
+	}
+	if tail := q.Tail(10); len(tail) != 2 || tail[0].ID != 2 {
+		t.Error("tail size option not applied", len(tail))
+	}
+
+	// the zero value still works with the same defaults
+	zq := &Queue{}
+	zq.Publish(Event{Tag: "foo"})
+	if len(zq.Tail(1)) != 1 {
+		t.Error("zero value queue has no tail")
+	}
+	if _, ok := zq.getClock().(realClock); !ok {
+		t.Error("zero value queue should use the real clock")
+	}
+	zq.PublishAfter(time.Millisecond, Event{Tag: "bar"}).Cancel()
+}
@@ -0,0 +1,57 @@
+package event
+
+// QuiesceRun holds all events published for the run, without stamping or delivering them,
+// until ResumeRun is called, while the events of other runs continue to flow. It is used
+// while a run migrates between executors so neither processes its events twice.
+func (q *Queue) QuiesceRun(ref RunRef) {
+	if !ref.Adopted() {
+		return
+	}
+	q.Lock()
+	defer q.Unlock()
+	if q.quiesced == nil {
+		q.quiesced = map[runKey][]Event{}
+	}
+	k := ref.key()
+	if _, ok := q.quiesced[k]; !ok {
+		q.quiesced[k] = []Event{}
+	}
+}
+
+// ResumeRun publishes the events held for the run, in the order they were published,
+// then lets new events for the run flow again.
+func (q *Queue) ResumeRun(ref RunRef) {
+	k := ref.key()
+	for {
+		q.Lock()
+		held, ok := q.quiesced[k]
+		if !ok || len(held) == 0 {
+			delete(q.quiesced, k)
+			q.Unlock()
+			return
+		}
+		// keep holding any published while these are sent so the order is kept
+		q.quiesced[k] = []Event{}
+		q.Unlock()
+		for _, e := range held {
+			q.Lock()
+			q.init()
+			q.send(e)
+		}
+	}
+}
+
+// hold keeps e if its run is quiesced, returning true if it did.
+// hold must be called in the lock.
+func (q *Queue) hold(e Event) bool {
+	if !e.RunRef.Adopted() {
+		return false
+	}
+	k := e.RunRef.key()
+	held, ok := q.quiesced[k]
+	if !ok {
+		return false
+	}
+	q.quiesced[k] = append(held, e.copy())
+	return true
+}
@@ -0,0 +1,32 @@
+package event
+
+import "testing"
+
+func TestQuiesceRun(t *testing.T) {
+	q := NewQueue()
+	c := make(chanObs, 20)
+	q.RegisterWith(c, WithMaxConcurrent(1))
+
+	moving, other := testRef(1), testRef(2)
+	q.QuiesceRun(moving)
+	q.Publish(Event{RunRef: moving, Tag: "task.a.good"})
+	q.Publish(Event{RunRef: other, Tag: "task.x.good"})
+	q.Publish(Event{RunRef: moving, Tag: "task.b.good"})
+
+	// other runs keep flowing
+	if e := next(t, c); e.Tag != "task.x.good" || e.ID != 1 {
+		t.Fatal("other run should not be held", e.Tag, e.ID)
+	}
+	if len(q.History(moving)) != 0 {
+		t.Error("held events should not be published yet")
+	}
+
+	q.ResumeRun(moving)
+	q.Publish(Event{RunRef: moving, Tag: "task.c.good"})
+	for i, tag := range []string{"task.a.good", "task.b.good", "task.c.good"} {
+		e := next(t, c)
+		if e.Tag != tag || e.ID != int64(i+2) {
+			t.Errorf("got %s (%d) wanted %s", e.Tag, e.ID, tag)
+		}
+	}
+}
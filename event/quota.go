@@ -0,0 +1,102 @@
+package event
+
+import (
+	"time"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// TenantLabel is the run label naming the tenant of the run for LabelTenant
+const TenantLabel = "tenant"
+
+// Quota is the rate of events a tenant may publish, in events per second, with bursts of
+// up to Burst events. A zero Rate is no limit.
+type Quota struct {
+	Rate  float64
+	Burst int
+}
+
+// LabelTenant is the tenant of a run given by its TenantLabel label, or its flow ID for
+// runs with no tenant label, such as the inbound events before a run is adopted.
+func LabelTenant(r RunRef) string {
+	if t := r.Labels[TenantLabel]; t != "" {
+		return t
+	}
+	return r.FlowRef.ID
+}
+
+// WithTenantQuotas stops one tenant monopolising the queue by limiting the rate each
+// tenant, as given by tenant, can publish events at. Each tenant has the quota given for
+// it in quotas or else def. Events over the quota are dropped and a TagQuotaExceeded
+// event published each time a tenant goes over it. A nil tenant uses LabelTenant.
+func WithTenantQuotas(tenant func(RunRef) string, def Quota, quotas map[string]Quota) QueueOption {
+	if tenant == nil {
+		tenant = LabelTenant
+	}
+	return func(q *Queue) {
+		q.tenantOf = tenant
+		q.quotaDefault = def
+		q.quotas = quotas
+	}
+}
+
+// tenantUsage is the events published and dropped for a tenant
+type tenantUsage struct {
+	bucket
+	published int64
+}
+
+// TenantUsage returns how many events the tenant has published, and how many were dropped
+// for exceeding its quota.
+func (q *Queue) TenantUsage(tenant string) (published, dropped int64) {
+	q.RLock()
+	defer q.RUnlock()
+	u, ok := q.tenants[tenant]
+	if !ok {
+		return 0, 0
+	}
+	return u.published, u.dropped
+}
+
+// withinQuota returns false if e should be dropped for its tenant exceeding its quota,
+// and the event to publish the first time the tenant goes over it. Run ends and their
+// summaries are always allowed so runs are not left open. withinQuota must be called in the lock.
+func (q *Queue) withinQuota(e Event, now time.Time) (bool, *Event) {
+	if q.tenantOf == nil || e.Tag == TagQuotaExceeded {
+		return true, nil
+	}
+	tenant := q.tenantOf(e.RunRef)
+	quota, ok := q.quotas[tenant]
+	if !ok {
+		quota = q.quotaDefault
+	}
+	if q.tenants == nil {
+		q.tenants = map[string]*tenantUsage{}
+	}
+	u, ok := q.tenants[tenant]
+	if !ok {
+		u = &tenantUsage{bucket: bucket{tokens: float64(quota.Burst), last: now}}
+		q.tenants[tenant] = u
+	}
+	if quota.Rate <= 0 || e.Tag == TagEndFlow || e.Tag == TagRunSummary || u.take(now, quota.Rate, quota.Burst) {
+		u.published++
+		u.throttled = false
+		return true, nil
+	}
+	u.dropped++
+	if u.throttled {
+		return false, nil
+	}
+	u.throttled = true
+	q.logger.Errorf("<%s> - queue - tenant %s exceeded its quota, dropping events", e.RunRef, tenant)
+	return false, &Event{
+		RunRef:     e.RunRef,
+		SourceNode: e.SourceNode,
+		Tag:        TagQuotaExceeded,
+		Opts: nt.Opts{
+			"tenant": tenant,
+			"rate":   quota.Rate,
+			"burst":  quota.Burst,
+		},
+	}
+}
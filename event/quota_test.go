@@ -0,0 +1,72 @@
+package event
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTenantQuotas(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk), WithTenantQuotas(nil, Quota{Rate: 1, Burst: 5}, map[string]Quota{
+		"acme": {Rate: 1, Burst: 3},
+	}))
+	noisy := testRef(1)
+	noisy.Labels = map[string]string{TenantLabel: "acme"}
+	quiet := testRef(2)
+
+	exceeded := make(chanObs, 10)
+	q.Subscribe(exceeded, TagQuotaExceeded)
+	c := make(chanObs, 20)
+	q.Subscribe(c, "task.a.good")
+
+	for i := 0; i < 10; i++ {
+		q.Publish(Event{RunRef: noisy, Tag: "task.a.good", Good: true})
+	}
+	for i := 0; i < 4; i++ {
+		q.Publish(Event{RunRef: quiet, Tag: "task.a.good", Good: true})
+	}
+
+	e := next(t, exceeded)
+	if e.Opts["tenant"] != "acme" || !e.RunRef.Equal(noisy) {
+		t.Error("bad quota event", e)
+	}
+	if len(exceeded) != 0 {
+		t.Error("going over the quota should be reported once")
+	}
+	if pub, drop := q.TenantUsage("acme"); pub != 3 || drop != 7 {
+		t.Error("acme should have been held to its burst", pub, drop)
+	}
+	// the other tenant, by flow id, has its own quota
+	if pub, drop := q.TenantUsage(quiet.FlowRef.ID); pub != 4 || drop != 0 {
+		t.Error("the quiet tenant should not be affected", pub, drop)
+	}
+	for i := 0; i < 7; i++ {
+		next(t, c)
+	}
+
+	// the run can always end
+	q.Publish(Event{RunRef: noisy, Tag: TagEndFlow, Good: true})
+	if pub, _ := q.TenantUsage("acme"); pub != 5 {
+		t.Error("run end and summary should not be dropped", pub)
+	}
+
+	// the quota refills and going over again is reported again
+	clk.Advance(time.Second)
+	q.Publish(Event{RunRef: noisy, Tag: "task.a.good", Good: true})
+	q.Publish(Event{RunRef: noisy, Tag: "task.a.good", Good: true})
+	next(t, exceeded)
+
+	b := &bytes.Buffer{}
+	q.WriteMetrics(b)
+	for _, want := range []string{
+		`floe_events_dropped_total{reason="quota"} 8`,
+		`floe_tenant_events_total{tenant="acme"} 6`,
+		`floe_tenant_events_dropped_total{tenant="acme"} 8`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Error("missing metric", want, "\n"+b.String())
+		}
+	}
+}
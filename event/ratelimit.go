@@ -0,0 +1,93 @@
+package event
+
+import (
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// runKey identifies a run ignoring which host is executing it, matching RunRef.Equal
+type runKey struct {
+	flow config.FlowRef
+	run  HostedIDRef
+}
+
+func (r RunRef) key() runKey {
+	return runKey{flow: r.FlowRef, run: r.Run}
+}
+
+// bucket is a token bucket rate limiter
+type bucket struct {
+	tokens    float64
+	last      time.Time
+	dropped   int64
+	throttled bool // true once the throttle notice has been sent
+}
+
+// take refills the bucket for the time elapsed since it was last used and takes a token
+// if one is available.
+func (b *bucket) take(now time.Time, rate float64, burst int) bool {
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Throttled returns how many events have been dropped for the run by the rate limit
+func (q *Queue) Throttled(ref RunRef) int64 {
+	q.RLock()
+	defer q.RUnlock()
+	b, ok := q.buckets[ref.key()]
+	if !ok {
+		return 0
+	}
+	return b.dropped
+}
+
+// limit returns false if e should be dropped due to its run exceeding the rate limit,
+// and a notice event to publish if this is the first drop for the run.
+// limit must be called in the lock.
+func (q *Queue) limit(e Event, now time.Time) (bool, *Event) {
+	if q.rate <= 0 || !e.RunRef.Adopted() || e.Tag == TagRunThrottled {
+		return true, nil
+	}
+	k := e.RunRef.key()
+	if e.Tag == TagEndFlow {
+		// allow the run end through and forget the run
+		delete(q.buckets, k)
+		return true, nil
+	}
+	if q.buckets == nil {
+		q.buckets = map[runKey]*bucket{}
+	}
+	b, ok := q.buckets[k]
+	if !ok {
+		b = &bucket{tokens: float64(q.burst), last: now}
+		q.buckets[k] = b
+	}
+	if b.take(now, q.rate, q.burst) {
+		return true, nil
+	}
+	b.dropped++
+	if b.throttled {
+		return false, nil
+	}
+	b.throttled = true
+	q.logger.Debugf("<%s> - queue - run exceeded rate limit, dropping events", e.RunRef)
+	return false, &Event{
+		RunRef:     e.RunRef,
+		SourceNode: e.SourceNode,
+		Tag:        TagRunThrottled,
+		Opts: nt.Opts{
+			"rate":  q.rate,
+			"burst": q.burst,
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestRunRateLimit(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk), WithRunRateLimit(10, 5))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	counts := map[string]int{}
+	notices := 0
+	q.Register(&listener{what: func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Tag == TagRunThrottled {
+			notices++
+		} else {
+			counts[e.RunRef.Run.String()]++
+		}
+		wg.Done()
+	}})
+
+	noisy := RunRef{
+		FlowRef: config.FlowRef{ID: "flow", Ver: 1},
+		Run:     HostedIDRef{HostID: "h1", ID: 1},
+	}
+	quiet := RunRef{
+		FlowRef: config.FlowRef{ID: "flow", Ver: 1},
+		Run:     HostedIDRef{HostID: "h1", ID: 2},
+	}
+
+	// 5 burst + 1 notice + 3 for the quiet run
+	wg.Add(9)
+	for i := 0; i < 1000; i++ {
+		q.Publish(Event{RunRef: noisy, Tag: "sys.node.update"})
+	}
+	for i := 0; i < 3; i++ {
+		q.Publish(Event{RunRef: quiet, Tag: "sys.node.update"})
+	}
+	wg.Wait()
+
+	// after a second the noisy run has earned another 5 (capped at the burst)
+	clk.Advance(time.Second)
+	wg.Add(5)
+	for i := 0; i < 100; i++ {
+		q.Publish(Event{RunRef: noisy, Tag: "sys.node.update"})
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if counts["h1-1"] != 10 {
+		t.Error("noisy run should have been throttled", counts["h1-1"])
+	}
+	if counts["h1-2"] != 3 {
+		t.Error("quiet run should not be throttled", counts["h1-2"])
+	}
+	if notices != 1 {
+		t.Error("should have had a single throttle notice", notices)
+	}
+	if q.Throttled(noisy) != 1090 {
+		t.Error("wrong throttled count", q.Throttled(noisy))
+	}
+	if q.Throttled(quiet) != 0 {
+		t.Error("quiet run should have no dropped events", q.Throttled(quiet))
+	}
+}
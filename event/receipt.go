@@ -0,0 +1,100 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/floeit/floe/store"
+)
+
+// Receipts are the events sent to a durable observer that it has not yet acknowledged,
+// persisted so they can be sent again if the host restarts before they are.
+type Receipts struct {
+	sync.Mutex
+	key     string
+	store   store.Store
+	pending []Event
+	logger  Logger
+}
+
+// RegisterDurable registers o to receive every event at least once, even across restarts.
+// Each event is saved in s under the name of the observer before it is delivered, and
+// stays there until the observer calls Ack on the returned receipts with its ID. On
+// registering, any events saved and not acknowledged before a restart are delivered
+// first. As the IDs of a restarted queue start again, Ack the redelivered events before
+// new events with the same ID arrive.
+func (q *Queue) RegisterDurable(name string, o Observer, s store.Store, opts ...RegisterOption) (*Receipts, error) {
+	rc := &Receipts{
+		key:    "receipts-" + name,
+		store:  s,
+		logger: q.getLogger(),
+	}
+	if err := s.Load(rc.key, &rc.pending); err != nil {
+		return nil, err
+	}
+	r := &registration{
+		o:        o,
+		ready:    make(chan struct{}),
+		receipts: rc,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	replay := rc.Pending()
+
+	q.Lock()
+	if err := q.add(r); err != nil {
+		q.Unlock()
+		return nil, err
+	}
+	q.Unlock()
+
+	// redeliver before any live events
+	go func() {
+		for _, e := range replay {
+			o.Notify(e)
+		}
+		close(r.ready)
+	}()
+	return rc, nil
+}
+
+// Ack marks the event as processed by the observer so it will not be sent again
+func (rc *Receipts) Ack(id int64) error {
+	rc.Lock()
+	defer rc.Unlock()
+	for i, e := range rc.pending {
+		if e.ID != id {
+			continue
+		}
+		pending := make([]Event, 0, len(rc.pending)-1)
+		pending = append(pending, rc.pending[:i]...)
+		rc.pending = append(pending, rc.pending[i+1:]...)
+		return rc.store.Save(rc.key, rc.pending)
+	}
+	return fmt.Errorf("event %d is not awaiting a receipt", id)
+}
+
+// Pending returns the events not yet acknowledged, oldest first
+func (rc *Receipts) Pending() []Event {
+	rc.Lock()
+	defer rc.Unlock()
+	pending := make([]Event, len(rc.pending))
+	for i, e := range rc.pending {
+		pending[i] = e.copy()
+	}
+	return pending
+}
+
+// hold saves e as awaiting a receipt
+func (rc *Receipts) hold(e Event) {
+	rc.Lock()
+	defer rc.Unlock()
+	// a new slice each time as stores may keep the one saved
+	pending := make([]Event, len(rc.pending), len(rc.pending)+1)
+	copy(pending, rc.pending)
+	rc.pending = append(pending, e.copy())
+	if err := rc.store.Save(rc.key, rc.pending); err != nil {
+		rc.logger.Errorf("<%s> - could not save %s for event %d: %v", e.RunRef, rc.key, e.ID, err)
+	}
+}
@@ -0,0 +1,65 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/store"
+)
+
+func TestRegisterDurable(t *testing.T) {
+	s := store.NewMemStore()
+
+	q := NewQueue()
+	c := make(chanObs, 10)
+	rc, err := q.RegisterDurable("audit", c, s, WithMaxConcurrent(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"a", "b", "c"} {
+		q.Publish(Event{Tag: tag})
+	}
+	for range []string{"a", "b", "c"} {
+		next(t, c)
+	}
+	// only the first is processed before the crash
+	if err := rc.Ack(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := rc.Ack(1); err == nil {
+		t.Error("acking twice should error")
+	}
+	if n := len(rc.Pending()); n != 2 {
+		t.Error("expected 2 pending", n)
+	}
+
+	// restart with a new queue on the same store
+	q = NewQueue()
+	c = make(chanObs, 10)
+	rc, err = q.RegisterDurable("audit", c, s, WithMaxConcurrent(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Publish(Event{Tag: "d"})
+	for _, tag := range []string{"b", "c"} {
+		e := next(t, c)
+		if e.Tag != tag {
+			t.Errorf("redelivered %s wanted %s", e.Tag, tag)
+		}
+		if err := rc.Ack(e.ID); err != nil {
+			t.Error(err)
+		}
+	}
+	if e := next(t, c); e.Tag != "d" {
+		t.Error("live event should follow the redelivered ones", e.Tag)
+	}
+	pending := rc.Pending()
+	if len(pending) != 1 || pending[0].Tag != "d" {
+		t.Error("only the live event should be pending", pending)
+	}
+	select {
+	case e := <-c:
+		t.Error("unexpected event", e.Tag)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -0,0 +1,86 @@
+package event
+
+import (
+	"sync"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// RedactedValue replaces the values of sensitive opts in redacted events
+const RedactedValue = "<redacted>"
+
+// NodeTypes gives the node type of the nodes of a flow, nil if it has none
+type NodeTypes interface {
+	NodeType(flow config.FlowRef, node config.NodeRef) nt.NodeType
+}
+
+// WithRedaction hides secrets from observers by replacing the opts that the node type of
+// the source node of each event declares sensitive, with nt.Sensitive, by RedactedValue.
+// Only observers registered WithPrivileged, such as the hub that needs them to execute
+// nodes, are sent the secrets. History and Tail are not redacted, use Redact on events
+// before they are logged or sent on.
+func WithRedaction(src NodeTypes) QueueOption {
+	return func(q *Queue) {
+		q.redactor = &redactor{src: src, cache: map[metaKey][]string{}}
+	}
+}
+
+// WithPrivileged sends the observer the opts that WithRedaction would hide
+func WithPrivileged() RegisterOption {
+	return func(r *registration) {
+		r.privileged = true
+	}
+}
+
+// Redact returns e with its sensitive opts redacted, as sent to observers that are not
+// privileged, e itself if the queue has no redaction or e has no sensitive opts
+func (q *Queue) Redact(e Event) Event {
+	q.RLock()
+	rd := q.redactor
+	q.RUnlock()
+	if rd == nil {
+		return e
+	}
+	return rd.redact(e)
+}
+
+// redactor finds the sensitive opts of events, caching them by node
+type redactor struct {
+	mu    sync.Mutex
+	src   NodeTypes
+	cache map[metaKey][]string
+}
+
+// redact returns a copy of e with the sensitive opts replaced, or e if it has none
+func (rd *redactor) redact(e Event) Event {
+	if len(e.Opts) == 0 || e.SourceNode.ID == "" {
+		return e
+	}
+	k := metaKey{flow: e.RunRef.FlowRef, node: e.SourceNode}
+	rd.mu.Lock()
+	keys, ok := rd.cache[k]
+	if !ok {
+		if ty := rd.src.NodeType(k.flow, k.node); ty != nil {
+			keys = nt.SensitiveOpts(ty)
+		}
+		rd.cache[k] = keys
+	}
+	rd.mu.Unlock()
+
+	var c *Event
+	for _, key := range keys {
+		if _, ok := e.Opts[key]; !ok {
+			continue
+		}
+		if c == nil {
+			cp := e.copy()
+			c = &cp
+		}
+		c.Opts[key] = RedactedValue
+	}
+	if c == nil {
+		return e
+	}
+	return *c
+}
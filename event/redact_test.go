@@ -0,0 +1,62 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// deployType is a node type with a secret token
+type deployType struct{}
+
+func (deployType) Match(nt.Opts, nt.Opts) bool { return true }
+
+func (deployType) Execute(*nt.Workspace, nt.Opts, chan nt.Output) (int, nt.Opts, error) {
+	return 0, nil, nil
+}
+
+func (deployType) SensitiveOpts() []string { return []string{"token"} }
+
+// deployTypes says the deploy node is a deployType
+type deployTypes struct{}
+
+func (deployTypes) NodeType(flow config.FlowRef, node config.NodeRef) nt.NodeType {
+	if node.ID == "deploy" {
+		return deployType{}
+	}
+	return nil
+}
+
+func TestRedaction(t *testing.T) {
+	q := NewQueue(WithRedaction(deployTypes{}))
+	plain := make(chanObs, 10)
+	q.Register(plain)
+	privileged := make(chanObs, 10)
+	q.RegisterWith(privileged, WithPrivileged())
+
+	deploy := config.NodeRef{Class: "task", ID: "deploy"}
+	opts := nt.Opts{"token": "s3cret", "env": "prod"}
+	q.Publish(Event{RunRef: testRef(1), SourceNode: deploy, Tag: "task.deploy.good", Opts: opts})
+
+	e := next(t, plain)
+	if e.Opts["token"] != RedactedValue || e.Opts["env"] != "prod" {
+		t.Error("the secret should be redacted for other observers", e.Opts)
+	}
+	if e = next(t, privileged); e.Opts["token"] != "s3cret" {
+		t.Error("privileged observers should see the secret", e.Opts)
+	}
+	if opts["token"] != "s3cret" {
+		t.Error("redaction changed the published opts")
+	}
+	if r := q.Redact(e); r.Opts["token"] != RedactedValue || e.Opts["token"] != "s3cret" {
+		t.Error("bad redact", r.Opts, e.Opts)
+	}
+
+	// nodes with no sensitive opts are untouched
+	build := config.NodeRef{Class: "task", ID: "build"}
+	q.Publish(Event{RunRef: testRef(1), SourceNode: build, Tag: "task.build.good", Opts: nt.Opts{"token": "t"}})
+	if e := next(t, plain); e.Opts["token"] != "t" {
+		t.Error("only the opts the node type declares should be redacted", e.Opts)
+	}
+}
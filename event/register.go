@@ -0,0 +1,259 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ErrTooManyObservers is returned when registering an observer would exceed the maximum
+// observers set on the queue by WithMaxObservers
+var ErrTooManyObservers = errors.New("too many observers registered on the queue")
+
+// ErrAlreadyRegistered is returned when registering an observer that is already registered
+// on the queue, the existing registration is kept so the observer still receives every
+// event just once
+var ErrAlreadyRegistered = errors.New("observer already registered on the queue")
+
+// ContextObserver is an Observer whose handling of an event can fail. Observers that
+// implement it have NotifyCtx called instead of Notify, and the errors feed features
+// such as circuit breaking.
+type ContextObserver interface {
+	Observer
+	NotifyCtx(ctx context.Context, e Event) error
+}
+
+// RegisterOption configures how an observer is registered on a Queue
+type RegisterOption func(*registration)
+
+// registration is an observer registered on the queue along with how events are
+// delivered to it
+type registration struct {
+	o       Observer
+	sub     Subscription  // what the observer subscribed to
+	filters []filter      // all must pass for the event to be delivered
+	ready   chan struct{} // if not nil live events wait for it to be closed
+	breaker *breaker      // optional circuit breaker
+	box     *mailbox      // optional buffer limiting concurrent delivery
+	dedup   *dedup        // optional suppression of consecutive duplicates
+	budget  *BufferGroup  // optional budget for buffered events shared with others
+
+	deadline time.Duration // optional limit on handling each event by a ContextObserver
+
+	receipts *Receipts // optional durable record of the events not yet acknowledged
+	pause    pauser    // holds events while the observer is paused
+
+	copyPolicy CopyPolicy // overrides the queue policy if set
+	runScoped  bool       // only for the events of a run, so never sent general events
+	run        *RunRef    // the run of a SubscribeRun registration, removed once it ends
+	persistent bool       // kept when the observers are swapped
+	privileged bool       // sent opts the queue would otherwise redact
+
+	q *Queue // the queue this observer is registered on
+}
+
+// filter decides if an event is delivered, named so routing can be explained
+type filter struct {
+	name string
+	pass func(Event) bool
+	// stateful filters depend on earlier events or chance so can not be explained
+	stateful bool
+}
+
+// WithFilter only delivers the events to the observer for which f returns true.
+// Multiple filters can be given, all of which must pass.
+func WithFilter(f func(Event) bool) RegisterOption {
+	return withNamedFilter("filter", f)
+}
+
+func withNamedFilter(name string, f func(Event) bool) RegisterOption {
+	return func(r *registration) {
+		r.filters = append(r.filters, filter{name: name, pass: f})
+	}
+}
+
+func withStatefulFilter(name string, f func(Event) bool) RegisterOption {
+	return func(r *registration) {
+		r.filters = append(r.filters, filter{name: name, pass: f, stateful: true})
+	}
+}
+
+// accepts returns true if e should be delivered to this registration
+func (r *registration) accepts(e Event) bool {
+	for _, f := range r.filters {
+		if !f.pass(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// notify sends e to the observer in the background, returning a report to make if e was
+// dropped, which is called once the event has been posted to every observer
+func (r *registration) notify(e Event) (dropped func()) {
+	if r.dedup != nil && r.dedup.repeat(e) {
+		return func() { r.q.reportDrop(e, DropDuplicate, "") }
+	}
+	if r.receipts != nil {
+		r.receipts.hold(e)
+	}
+	if paused, kept := r.held(e); paused {
+		if !kept {
+			return func() { r.q.deadLetter(e, DropPaused, "observer paused") }
+		}
+		return nil
+	}
+	if r.box != nil {
+		if !r.box.post(e, r.deliver) {
+			return func() { r.q.deadLetter(e, DropOverflow, "buffer group full") }
+		}
+		return nil
+	}
+	go r.deliver(e)
+	return nil
+}
+
+// deliver sends e to the observer once any replay is complete, and if the breaker allows
+func (r *registration) deliver(e Event) {
+	if r.ready != nil {
+		<-r.ready
+	}
+	if r.breaker != nil && !r.breaker.allow(r.q.getClock().Now()) {
+		r.q.deadLetter(e, DropCircuitOpen, "circuit open")
+		return
+	}
+	co, ok := r.o.(ContextObserver)
+	if !ok {
+		r.o.Notify(e)
+		return
+	}
+	err := r.notifyCtx(co, e)
+	if r.breaker != nil {
+		r.breaker.done(err, r.q.getClock().Now(), r.q.getLogger())
+	}
+	if err == ErrDeadlineExceeded {
+		r.q.deadLetter(e, DropExpired, err.Error())
+	} else if err != nil {
+		r.q.deadLetter(e, DropFailed, err.Error())
+	}
+}
+
+// isGood is the filter matching good events
+func isGood(e Event) bool {
+	return e.Good
+}
+
+// RegisterWith registers an observer to this q configured by opts
+func (q *Queue) RegisterWith(o Observer, opts ...RegisterOption) error {
+	r := newRegistration(o, opts)
+	q.Lock()
+	defer q.Unlock()
+	return q.add(r)
+}
+
+// newRegistration returns the registration of o configured by opts
+func newRegistration(o Observer, opts []RegisterOption) *registration {
+	r := &registration{o: o}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if i, ok := o.(Interested); ok && len(r.sub.Tags) == 0 {
+		r.sub.Tags = i.Interests()
+	}
+	return r
+}
+
+// add adds the registration to the observers, unless the queue already has the maximum,
+// or the observer is already registered other than for a run. add must be called in the lock.
+func (q *Queue) add(r *registration) error {
+	if !r.runScoped && q.registered(r.o) {
+		return ErrAlreadyRegistered
+	}
+	if q.maxObservers > 0 && len(q.observers) >= q.maxObservers {
+		return ErrTooManyObservers
+	}
+	if r.budget != nil {
+		if r.box == nil {
+			r.box = &mailbox{max: 1}
+		}
+		r.box.budget = r.budget
+	}
+	r.q = q
+	if ql, ok := r.o.(queueLogged); ok {
+		q.init()
+		ql.useLogger(q.logger)
+	}
+	q.observers = append(q.observers, r)
+	return nil
+}
+
+// sameObserver returns true if a and b are the same observer. Only pointers, maps and chans
+// have an identity, so other observers, such as funcs and value structs, never match.
+func sameObserver(a, b Observer) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan:
+	default:
+		return false
+	}
+	return va.Type() == vb.Type() && va.Pointer() == vb.Pointer()
+}
+
+// registered returns true if o has a registration that is not for a single run.
+// Observers with no identity, see sameObserver, are never found.
+// registered must be called in the lock.
+func (q *Queue) registered(o Observer) bool {
+	for _, r := range q.observers {
+		if !r.runScoped && sameObserver(r.o, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unregister removes all registrations of the observer o, returning false if it was not
+// registered. Deliveries already in progress may still complete. Observers that are not
+// pointers, maps or chans, such as funcs and value structs, can not be unregistered.
+func (q *Queue) Unregister(o Observer) bool {
+	q.Lock()
+	defer q.Unlock()
+	// a new slice as publishers may be ranging over the current one
+	var obs []*registration
+	found := false
+	for _, r := range q.observers {
+		if sameObserver(r.o, o) {
+			found = true
+			continue
+		}
+		obs = append(obs, r)
+	}
+	q.observers = obs
+	for _, g := range q.groups {
+		if g.remove(o) {
+			found = true
+		}
+	}
+	return found
+}
+
+// Register registers an observer to this q. Registering an observer that is already
+// registered, other than with SubscribeRun, returns ErrAlreadyRegistered and leaves the
+// first registration in place.
+func (q *Queue) Register(o Observer) error {
+	return q.RegisterWith(o)
+}
+
+// RegisterErrors registers an observer that is only sent error events, the same events
+// as the alert observer
+func (q *Queue) RegisterErrors(o Observer) error {
+	return q.RegisterWith(o, withNamedFilter("errors only", isError))
+}
+
+// RegisterGood registers an observer that is only sent good events
+func (q *Queue) RegisterGood(o Observer) error {
+	return q.RegisterWith(o, withNamedFilter("good only", isGood))
+}
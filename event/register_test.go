@@ -0,0 +1,180 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegisterErrors(t *testing.T) {
+	q := NewQueue()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs, goods, all []string
+	rec := func(l *[]string) *listener {
+		return &listener{what: func(e Event) {
+			mu.Lock()
+			*l = append(*l, e.Tag)
+			mu.Unlock()
+			wg.Done()
+		}}
+	}
+	q.RegisterErrors(rec(&errs))
+	q.RegisterGood(rec(&goods))
+	q.Register(rec(&all))
+	q.RegisterWith(rec(&all), WithFilter(isGood), WithFilter(func(e Event) bool {
+		return e.Tag == "task.a.good"
+	}))
+
+	// 3 to the all observer, 1 error, 2 good, 1 to the double filtered
+	wg.Add(7)
+	q.Publish(Event{Tag: "task.a.good", Good: true})
+	q.Publish(Event{Tag: "task.b.bad"})
+	q.Publish(Event{Tag: "task.c.good", Good: true})
+	wg.Wait()
+
+	if len(errs) != 1 || errs[0] != "task.b.bad" {
+		t.Error("errors observer got wrong events", errs)
+	}
+	if len(goods) != 2 {
+		t.Error("good observer got wrong events", goods)
+	}
+	if len(all) != 4 {
+		t.Error("unfiltered observers got wrong events", all)
+	}
+}
+
+func TestRegisterErrorsLevels(t *testing.T) {
+	alerts := make(chanObs, 10)
+	q := NewQueue(WithAlertObserver(alerts))
+	errs := make(chanObs, 10)
+	q.RegisterErrors(errs)
+
+	q.Publish(Event{Tag: "sys.state"})                                      // system events are never errors
+	q.Publish(Event{Tag: "task.flaky.bad", Level: LevelWarn})               // downgraded
+	q.Publish(Event{Tag: "task.audit.good", Good: true, Level: LevelError}) // upgraded
+	q.Publish(Event{Tag: "task.build.bad"})
+
+	// the error observers and the alert observer agree
+	for _, c := range []chanObs{errs, alerts} {
+		got := map[string]bool{next(t, c).Tag: true, next(t, c).Tag: true}
+		if !got["task.audit.good"] || !got["task.build.bad"] {
+			t.Error("wrong errors", got)
+		}
+		select {
+		case e := <-c:
+			t.Error("not an error", e.Tag)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	q := NewQueue()
+	r := &recorder{}
+	q.Register(r)
+	q.Register(&listener{what: func(Event) {}})
+	if !q.Unregister(r) {
+		t.Fatal("registered observer not found")
+	}
+	if len(q.observers) != 1 {
+		t.Error("only the one observer should be removed", len(q.observers))
+	}
+	if q.Unregister(r) {
+		t.Error("already unregistered observer found")
+	}
+	// publishing must not reach the unregistered recorder which would panic its wait group
+	q.Publish(Event{Tag: "a"})
+}
+
+func TestMaxObservers(t *testing.T) {
+	q := NewQueue(WithMaxObservers(2))
+	if err := q.Register(&recorder{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.RegisterGroup("workers", &recorder{}); err != nil {
+		t.Fatal(err)
+	}
+	// joining an existing group does not add an observer
+	if err := q.RegisterGroup("workers", &recorder{}); err != nil {
+		t.Error("joining a group should not count", err)
+	}
+	if err := q.Register(&recorder{}); err != ErrTooManyObservers {
+		t.Error("expected too many observers", err)
+	}
+	if err := q.SubscribeRun(testRef(1), 0, &recorder{}); err != ErrTooManyObservers {
+		t.Error("expected too many observers for run subscribers", err)
+	}
+	if len(q.observers) != 2 {
+		t.Error("rejected observers should not be added", len(q.observers))
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	q := NewQueue()
+	wg := &sync.WaitGroup{}
+	c := &counter{wg: wg}
+	if err := q.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Register(c); err != ErrAlreadyRegistered {
+		t.Error("expected already registered", err)
+	}
+	if err := q.RegisterErrors(c); err != ErrAlreadyRegistered {
+		t.Error("expected already registered with options", err)
+	}
+
+	wg.Add(3)
+	for _, tag := range []string{"a", "b", "c"} {
+		q.Publish(Event{Tag: tag})
+	}
+	wg.Wait()
+	// give any duplicate deliveries a chance to arrive
+	time.Sleep(10 * time.Millisecond)
+	c.Lock()
+	if c.n != 3 {
+		t.Error("each event should be received once", c.n)
+	}
+	c.Unlock()
+
+	// the same observer can still follow individual runs
+	if err := q.SubscribeRun(testRef(1), 0, c); err != nil {
+		t.Error("run subscription should be allowed", err)
+	}
+}
+
+// wrapper is a value observer holding an interface, so it is comparable by type but
+// comparing two of them can panic
+type wrapper struct {
+	inner Observer
+}
+
+func (w wrapper) Notify(e Event) { w.inner.Notify(e) }
+
+// funcObs is an observer with no identity
+type funcObs func(Event)
+
+func (f funcObs) Notify(e Event) { f(e) }
+
+func TestRegisterValueObservers(t *testing.T) {
+	q := NewQueue()
+	// an uncomparable value in the interface would panic on ==
+	w := wrapper{inner: funcObs(func(Event) {})}
+	if err := q.Register(w); err != nil {
+		t.Fatal(err)
+	}
+	// value observers have no identity so are never duplicates
+	if err := q.Register(w); err != nil {
+		t.Error("value observers should not be found registered", err)
+	}
+	if err := q.Register(wrapper{inner: make(chanObs, 1)}); err != nil {
+		t.Error(err)
+	}
+	if q.Unregister(w) || q.PauseObserver(w, 1) {
+		t.Error("value observers can not be found to unregister or pause")
+	}
+	if len(q.observers) != 3 {
+		t.Error("expected all the registrations", len(q.observers))
+	}
+}
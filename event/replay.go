@@ -0,0 +1,47 @@
+package event
+
+import (
+	"errors"
+	"time"
+)
+
+var errNegativeSpeed = errors.New("replay speed can not be negative")
+
+// ReplayTimed sends events to o in order, spaced by the gaps between their recorded times
+// divided by speed, so a speed of 2 plays back twice as fast as the events happened.
+// A speed of 0 sends all the events at once. ReplayTimed returns straight away, the events
+// are sent from timers on clock, which defaults to the real time if nil.
+func ReplayTimed(events []Event, speed float64, o Observer, clock Clock) error {
+	if speed < 0 {
+		return errNegativeSpeed
+	}
+	if clock == nil {
+		clock = realClock{}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	events = append([]Event(nil), events...)
+	if speed == 0 {
+		clock.AfterFunc(0, func() {
+			for _, e := range events {
+				o.Notify(e.copy())
+			}
+		})
+		return nil
+	}
+	var next func(i int)
+	next = func(i int) {
+		o.Notify(events[i].copy())
+		if i+1 == len(events) {
+			return
+		}
+		gap := events[i+1].Time.Sub(events[i].Time)
+		if gap < 0 { // out of order times are played without waiting
+			gap = 0
+		}
+		clock.AfterFunc(time.Duration(float64(gap)/speed), func() { next(i + 1) })
+	}
+	clock.AfterFunc(0, func() { next(0) })
+	return nil
+}
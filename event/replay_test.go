@@ -0,0 +1,70 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReplayTimed(t *testing.T) {
+	start := time.Date(2017, 11, 1, 10, 0, 0, 0, time.UTC)
+	events := []Event{
+		{ID: 1, Time: start},
+		{ID: 2, Time: start.Add(10 * time.Second)},
+		{ID: 3, Time: start.Add(30 * time.Second)},
+	}
+
+	var mu sync.Mutex
+	var got []int64
+	o := &listener{what: func(e Event) {
+		mu.Lock()
+		got = append(got, e.ID)
+		mu.Unlock()
+	}}
+	delivered := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got)
+	}
+
+	if err := ReplayTimed(events, -1, o, nil); err == nil {
+		t.Error("negative speed should error")
+	}
+
+	clk := NewFakeClock()
+	if err := ReplayTimed(events, 2, o, clk); err != nil {
+		t.Fatal(err)
+	}
+	clk.Advance(0)
+	if n := delivered(); n != 1 {
+		t.Fatal("first event should be sent straight away", n)
+	}
+	// the 10s gap takes 5s at double speed
+	clk.Advance(4900 * time.Millisecond)
+	if n := delivered(); n != 1 {
+		t.Fatal("second event sent early", n)
+	}
+	clk.Advance(100 * time.Millisecond)
+	if n := delivered(); n != 2 {
+		t.Fatal("second event not sent", n)
+	}
+	clk.Advance(9900 * time.Millisecond)
+	if n := delivered(); n != 2 {
+		t.Fatal("third event sent early", n)
+	}
+	clk.Advance(100 * time.Millisecond)
+	if n := delivered(); n != 3 {
+		t.Fatal("third event not sent", n)
+	}
+
+	// instant replay
+	got = nil
+	clk = NewFakeClock()
+	if err := ReplayTimed(events, 0, o, clk); err != nil {
+		t.Fatal(err)
+	}
+	clk.Advance(0)
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Error("instant replay should send all in order", got)
+	}
+}
@@ -0,0 +1,166 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// HostIDAllocator hands out new run IDs for the given host
+type HostIDAllocator interface {
+	NextID(hostID string) HostedIDRef
+}
+
+// counterAllocator allocates increasing IDs per host
+type counterAllocator struct {
+	sync.Mutex
+	counters map[string]int64
+}
+
+func (c *counterAllocator) NextID(hostID string) HostedIDRef {
+	c.Lock()
+	defer c.Unlock()
+	if c.counters == nil {
+		c.counters = map[string]int64{}
+	}
+	c.counters[hostID]++
+	return HostedIDRef{HostID: hostID, ID: c.counters[hostID]}
+}
+
+// NewCounterAllocator returns an allocator whose IDs for each host start at 1 and
+// increase, so a queue given its own allocator assigns the same run IDs every time.
+func NewCounterAllocator() HostIDAllocator {
+	return &counterAllocator{}
+}
+
+// defaultAllocator is shared by all queues so run IDs are unique in the process
+var defaultAllocator = &counterAllocator{}
+
+// WithHostIDAllocator sets the allocator of the IDs of runs started by the queue, such as
+// by Rerun. By default all queues share an allocator, so the IDs depend on what any other
+// queue in the process has allocated.
+func WithHostIDAllocator(a HostIDAllocator) QueueOption {
+	return func(q *Queue) {
+		q.ids = a
+	}
+}
+
+// triggerClass is the node class of the trigger nodes that start runs
+const triggerClass = "trigger"
+
+// RunStarter starts new runs, allocating their run IDs, such as the hub with its pending
+// runs. Rerun hands its runs to the first observer registered on the queue that is a
+// RunStarter, so they are allocated and made active where they execute.
+type RunStarter interface {
+	StartRun(ref RunRef, trig config.NodeRef, opts nt.Opts) (RunRef, error)
+}
+
+// Rerun starts a new run of the same flow as the run ref, from a copy of the trigger event
+// that started it. The opts of the original trigger are used with any overrides replacing
+// them. The run must still be in the history of this queue. If a RunStarter is registered
+// it starts the run, otherwise the queue allocates the run and publishes its trigger.
+func (q *Queue) Rerun(ref RunRef, overrides nt.Opts) (RunRef, error) {
+	trig, err := q.trigger(ref)
+	if err != nil {
+		return RunRef{}, err
+	}
+	opts := nt.MergeOpts(trig.Opts, overrides)
+	if s := q.runStarter(); s != nil {
+		return s.StartRun(ref, trig.SourceNode, opts)
+	}
+
+	host := ref.ExecHost
+	if host == "" {
+		host = ref.Run.HostID
+	}
+	nr := RunRef{
+		FlowRef:  ref.FlowRef,
+		ExecHost: ref.ExecHost,
+		Trigger:  ref.Trigger,
+	}
+	// skip any IDs of runs this queue already knows of
+	ids := q.allocator()
+	for {
+		nr.Run = ids.NextID(host)
+		if !q.known(nr) {
+			break
+		}
+	}
+	q.Publish(Event{
+		RunRef:     nr,
+		SourceNode: trig.SourceNode,
+		Tag:        trig.Tag,
+		Good:       true,
+		Opts:       opts,
+	})
+	return nr, nil
+}
+
+// RunScopedOpts are the opts keys that only have meaning in the run they were set in, so
+// are not carried into a fresh run by ReplayAsTrigger
+var RunScopedOpts = []string{CorrelationKey, DeadLetterKey, MetaKey, ApprovalKey}
+
+// ReplayAsTrigger publishes the opts of the trigger event of the run ref as a new unadopted
+// inbound event of triggerType for the same flow. The hub then starts a fresh run, with
+// its own RunRef, resolving the opts and templates of the flow again. Unlike Rerun nothing
+// of the old run is kept apart from the trigger payload, less any RunScopedOpts.
+func (q *Queue) ReplayAsTrigger(ref RunRef, triggerType string) error {
+	trig, err := q.trigger(ref)
+	if err != nil {
+		return err
+	}
+	payload := deepOpts(trig.Opts, warnUncopyable(q.getLogger(), *trig))
+	for _, k := range RunScopedOpts {
+		delete(payload, k)
+	}
+	q.Publish(NewTriggerEvent(ref.FlowRef, triggerType, payload))
+	return nil
+}
+
+// isTrigger returns true if e is a trigger event that starts a run
+func isTrigger(e Event) bool {
+	return e.SourceNode.Class == triggerClass && e.Good
+}
+
+// trigger returns the trigger event that started the run ref, which the history keeps
+// even once it has been evicted
+func (q *Queue) trigger(ref RunRef) (*Event, error) {
+	q.RLock()
+	defer q.RUnlock()
+	if h, ok := q.history[ref.key()]; ok && h.trigger != nil {
+		t := h.trigger.copy()
+		return &t, nil
+	}
+	return nil, fmt.Errorf("no trigger event in the history of run %s", ref)
+}
+
+// known returns true if the queue has any history of the run
+func (q *Queue) known(ref RunRef) bool {
+	q.RLock()
+	defer q.RUnlock()
+	_, ok := q.history[ref.key()]
+	return ok
+}
+
+// runStarter returns the first registered observer that is a RunStarter, if any
+func (q *Queue) runStarter() RunStarter {
+	q.RLock()
+	defer q.RUnlock()
+	for _, r := range q.observers {
+		if s, ok := r.o.(RunStarter); ok {
+			return s
+		}
+	}
+	return nil
+}
+
+func (q *Queue) allocator() HostIDAllocator {
+	q.RLock()
+	defer q.RUnlock()
+	if q.ids == nil {
+		return defaultAllocator
+	}
+	return q.ids
+}
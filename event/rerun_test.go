@@ -0,0 +1,214 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestRerun(t *testing.T) {
+	q := NewQueue()
+	ref := testRef(1)
+	trig := config.NodeRef{Class: "trigger", ID: "push"}
+	q.Publish(Event{RunRef: ref, SourceNode: trig, Tag: "trigger.good", Good: true, Opts: nt.Opts{
+		"branch": "master",
+		"sha":    "abc123",
+	}})
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good", Good: true})
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow, Good: true})
+
+	c := make(chanObs, 10)
+	q.Subscribe(c, "trigger.good")
+
+	// a faithful re-run
+	nr, err := q.Rerun(ref, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nr.Equal(ref) || !nr.FlowRef.Equal(ref.FlowRef) || !nr.Adopted() {
+		t.Error("re-run should be a new adopted run of the same flow", nr)
+	}
+	e := next(t, c)
+	if !e.RunRef.Equal(nr) || e.SourceNode != trig || !e.Good {
+		t.Error("re-run trigger wrong", e)
+	}
+	if e.Opts["branch"] != "master" || e.Opts["sha"] != "abc123" {
+		t.Error("re-run should use the original opts", e.Opts)
+	}
+
+	// with an override
+	nr2, err := q.Rerun(ref, nt.Opts{"branch": "release"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nr2.Equal(nr) {
+		t.Error("each re-run should be a new run")
+	}
+	e = next(t, c)
+	if e.Opts["branch"] != "release" || e.Opts["sha"] != "abc123" {
+		t.Error("override not applied", e.Opts)
+	}
+
+	if _, err := q.Rerun(testRef(99), nil); err == nil {
+		t.Error("re-run of an unknown run should fail")
+	}
+}
+
+func TestRerunDeterministic(t *testing.T) {
+	rerun := func(q *Queue) []string {
+		ref := testRef(1)
+		q.Publish(Event{RunRef: ref, SourceNode: config.NodeRef{Class: "trigger", ID: "push"},
+			Tag: "trigger.good", Good: true})
+		var refs []string
+		for i := 0; i < 2; i++ {
+			nr, err := q.Rerun(ref, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs = append(refs, nr.String())
+		}
+		return refs
+	}
+
+	// queues using the shared allocator in between have no effect
+	first := rerun(NewQueue(WithHostIDAllocator(NewCounterAllocator())))
+	rerun(NewQueue())
+	second := rerun(NewQueue(WithHostIDAllocator(NewCounterAllocator())))
+
+	// run 1 is already known so the IDs start from 2
+	want := []string{"runref_build-1_h1-2", "runref_build-1_h1-3"}
+	for i := range want {
+		if first[i] != want[i] || second[i] != want[i] {
+			t.Errorf("%d: wanted %s got %s then %s", i, want[i], first[i], second[i])
+		}
+	}
+}
+
+// starter is a RunStarter recording the runs it is asked to start
+type starter struct {
+	trig config.NodeRef
+	opts nt.Opts
+}
+
+func (s *starter) Notify(Event) {}
+
+func (s *starter) StartRun(ref RunRef, trig config.NodeRef, opts nt.Opts) (RunRef, error) {
+	s.trig, s.opts = trig, opts
+	ref.Run.ID = 42
+	return ref, nil
+}
+
+func TestRerunStarter(t *testing.T) {
+	q := NewQueue()
+	ref := testRef(1)
+	trig := config.NodeRef{Class: "trigger", ID: "push"}
+	q.Publish(Event{RunRef: ref, SourceNode: trig, Tag: "trigger.good", Good: true, Opts: nt.Opts{
+		"branch": "master",
+	}})
+
+	s := &starter{}
+	q.Register(s)
+	c := make(chanObs, 10)
+	q.Subscribe(c, "trigger.good")
+
+	nr, err := q.Rerun(ref, nt.Opts{"branch": "release"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nr.Run.ID != 42 {
+		t.Error("the starter should allocate the run", nr)
+	}
+	if s.trig != trig || s.opts["branch"] != "release" {
+		t.Error("starter given the wrong trigger", s.trig, s.opts)
+	}
+	select {
+	case e := <-c:
+		t.Error("the starter publishes the trigger, not the queue", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestReplayAsTrigger(t *testing.T) {
+	q := NewQueue()
+	ref := testRef(1)
+	trig := config.NodeRef{Class: "trigger", ID: "push"}
+	q.Publish(Event{RunRef: ref, SourceNode: trig, Tag: "trigger.good", Good: true, Opts: nt.Opts{
+		"branch":       "master",
+		"env":          []string{"A=1"},
+		CorrelationKey: "c1",
+		MetaKey:        nt.Opts{"flow_name": "Build"},
+	}})
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow, Good: true})
+
+	c := make(chanObs, 10)
+	q.Subscribe(c, InboundPrefix+".push")
+
+	if err := q.ReplayAsTrigger(ref, "push"); err != nil {
+		t.Fatal(err)
+	}
+	e := next(t, c)
+	if e.RunRef.Adopted() || e.RunRef.Run.ID != 0 || !e.RunRef.FlowRef.Equal(ref.FlowRef) {
+		t.Error("replay should be an unadopted trigger of the same flow", e.RunRef)
+	}
+	if e.SourceNode == trig || e.Good {
+		t.Error("replay should not look like the old trigger node event", e)
+	}
+	if e.Opts["branch"] != "master" {
+		t.Error("replay should keep the trigger payload", e.Opts)
+	}
+	for _, k := range []string{CorrelationKey, MetaKey} {
+		if _, ok := e.Opts[k]; ok {
+			t.Error("run scoped opt kept", k)
+		}
+	}
+
+	// the new trigger shares nothing with the old run
+	e.Opts["env"].([]string)[0] = "A=2"
+	if h := q.History(ref); h[0].Opts["env"].([]string)[0] != "A=1" {
+		t.Error("replay shares opts with the old run", h[0].Opts)
+	}
+
+	if err := q.ReplayAsTrigger(testRef(99), "push"); err == nil {
+		t.Error("replay of an unknown run should fail")
+	}
+}
+
+func TestRerunCompacted(t *testing.T) {
+	ref := testRef(1)
+	q := NewQueue(WithCompaction(ref.FlowRef, LastN(2)))
+	trig := config.NodeRef{Class: "trigger", ID: "push"}
+	q.Publish(Event{RunRef: ref, SourceNode: trig, Tag: "trigger.good", Good: true, Opts: nt.Opts{"branch": "master"}})
+	for _, tag := range []string{"task.build.good", "task.test.good", TagEndFlow} {
+		q.Publish(Event{RunRef: ref, Tag: tag, Good: true})
+	}
+	for _, e := range q.History(ref) {
+		if e.Tag == "trigger.good" {
+			t.Fatal("the trigger should have been evicted from the history")
+		}
+	}
+
+	c := make(chanObs, 10)
+	q.Subscribe(c, "trigger.good")
+	nr, err := q.Rerun(ref, nil)
+	if err != nil {
+		t.Fatal("a compacted run should still re-run", err)
+	}
+	if e := next(t, c); !e.RunRef.Equal(nr) || e.Opts["branch"] != "master" {
+		t.Error("re-run trigger wrong", e.RunRef, e.Opts)
+	}
+
+	// and once restored from a snapshot
+	b, err := q.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nq, err := RestoreQueue(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nq.ReplayAsTrigger(ref, "push"); err != nil {
+		t.Error("a restored compacted run should still replay", err)
+	}
+}
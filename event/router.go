@@ -0,0 +1,68 @@
+package event
+
+import (
+	"path"
+	"strings"
+)
+
+// Subscription describes the events an observer was registered for
+type Subscription struct {
+	// Tags the observer is interested in, no tags means all events
+	Tags []string
+}
+
+// Router decides if an event matches a subscription, so the strategy for matching
+// tags can be replaced without changing the queue.
+type Router interface {
+	Matches(e Event, s Subscription) bool
+}
+
+// Interested is an Observer that declares the tags it wants, it is subscribed to them
+// when registered without any tags, so it is only sent those events.
+type Interested interface {
+	Observer
+	// Interests returns the tags, or globs such as "task.*.good", the observer wants
+	Interests() []string
+}
+
+// TagRouter is the default Router matching events whose Tag is one of the subscribed
+// tags ignoring case, the same convention as node Listen tags. A subscribed tag with a *
+// is a glob, where * matches any run of characters.
+type TagRouter struct{}
+
+// Matches satisfies Router
+func (TagRouter) Matches(e Event, s Subscription) bool {
+	if len(s.Tags) == 0 {
+		return true
+	}
+	for _, t := range s.Tags {
+		if strings.EqualFold(t, e.Tag) {
+			return true
+		}
+		if strings.Contains(t, "*") {
+			if ok, _ := path.Match(strings.ToLower(t), strings.ToLower(e.Tag)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WithRouter sets the router used to match events to subscriptions
+func WithRouter(r Router) QueueOption {
+	return func(q *Queue) {
+		q.router = r
+	}
+}
+
+// WithTags subscribes the observer to only the given tags, as matched by the queues Router
+func WithTags(tags ...string) RegisterOption {
+	return func(r *registration) {
+		r.sub.Tags = append(r.sub.Tags, tags...)
+	}
+}
+
+// Subscribe registers an observer for only those events matching the tags
+func (q *Queue) Subscribe(o Observer, tags ...string) error {
+	return q.RegisterWith(o, WithTags(tags...))
+}
@@ -0,0 +1,106 @@
+package event
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// prefixRouter matches subscribed tags as prefixes
+type prefixRouter struct{}
+
+func (prefixRouter) Matches(e Event, s Subscription) bool {
+	for _, t := range s.Tags {
+		if strings.HasPrefix(e.Tag, t) {
+			return true
+		}
+	}
+	return len(s.Tags) == 0
+}
+
+func TestRouter(t *testing.T) {
+	tags := []string{"task.build.good", "task.build.bad", "task.test.good", "sys.state"}
+
+	fix := []struct {
+		router Router
+		sub    []string
+		want   []string
+	}{
+		{nil, nil, tags},
+		{nil, []string{"task.build.good", "sys.state"}, []string{"task.build.good", "sys.state"}},
+		{nil, []string{"task.build"}, nil},
+		{nil, []string{"TASK.*.good"}, []string{"task.build.good", "task.test.good"}},
+		{prefixRouter{}, []string{"task.build"}, []string{"task.build.good", "task.build.bad"}},
+		{prefixRouter{}, nil, tags},
+	}
+	for i, f := range fix {
+		var opts []QueueOption
+		if f.router != nil {
+			opts = append(opts, WithRouter(f.router))
+		}
+		q := NewQueue(opts...)
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		got := map[string]bool{}
+		q.Subscribe(&listener{what: func(e Event) {
+			mu.Lock()
+			got[e.Tag] = true
+			mu.Unlock()
+			wg.Done()
+		}}, f.sub...)
+
+		// a catch all to know when everything has been published
+		wg.Add(len(tags) + len(f.want))
+		q.Register(&listener{what: func(e Event) {
+			wg.Done()
+		}})
+		for _, tag := range tags {
+			q.Publish(Event{Tag: tag})
+		}
+		wg.Wait()
+
+		mu.Lock()
+		if len(got) != len(f.want) {
+			t.Errorf("%d - wrong number of routed events %v", i, got)
+		}
+		for _, w := range f.want {
+			if !got[w] {
+				t.Errorf("%d - %s should have been routed", i, w)
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+// interested only wants the build events
+type interested struct {
+	chanObs
+}
+
+func (interested) Interests() []string {
+	return []string{"task.build.*"}
+}
+
+func TestInterests(t *testing.T) {
+	q := NewQueue()
+	o := interested{make(chanObs, 10)}
+	q.Register(o)
+	all := make(chanObs, 10)
+	q.Register(all)
+
+	for _, tag := range []string{"task.test.good", "task.build.good", "sys.state", "task.build.bad"} {
+		q.Publish(Event{Tag: tag})
+		next(t, all)
+	}
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		got[next(t, o.chanObs).Tag] = true
+	}
+	if !got["task.build.good"] || !got["task.build.bad"] {
+		t.Error("interested observer not sent its tags", got)
+	}
+	if len(o.chanObs) != 0 {
+		t.Error("interested observer sent an event it did not declare")
+	}
+}
@@ -0,0 +1,124 @@
+package event
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/floeit/floe/config"
+)
+
+// Rule changes each published event it matches, before it is recorded or sent to any
+// observer. The publisher's event is never changed, the rule applies to a copy.
+type Rule struct {
+	Name  string
+	Match func(Event) bool // the events the rule applies to
+
+	Level  Level             // set as the event level if not LevelUnset
+	Labels map[string]string // added to the labels of the run, on this and its later events
+	Tag    string            // replaces the tag of the event if not empty
+}
+
+// WithRules applies rules, in order, to every event published, so later rules see the
+// changes made by earlier ones.
+func WithRules(rules ...Rule) QueueOption {
+	return func(q *Queue) {
+		q.rules = append(q.rules, rules...)
+	}
+}
+
+// ConfigRules returns the rules given in the config, matching events whose tag matches the
+// rule tag glob and whose opts have all the values in the rule opts.
+func ConfigRules(rules []config.Rule) ([]Rule, error) {
+	res := make([]Rule, 0, len(rules))
+	for i, cr := range rules {
+		name := cr.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i)
+		}
+		r := Rule{Name: name, Labels: cr.Labels, Tag: cr.SetTag}
+		if cr.Level != "" {
+			l, err := ParseLevel(cr.Level)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+			r.Level = l
+		}
+		glob := strings.ToLower(cr.Tag)
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("%s: bad tag '%s': %v", name, cr.Tag, err)
+		}
+		opts := cr.Opts
+		r.Match = func(e Event) bool {
+			if glob != "" {
+				if ok, _ := path.Match(glob, e.Tag); !ok {
+					return false
+				}
+			}
+			for k, want := range opts {
+				v, ok := e.OptPath(k)
+				// config values are compared as text so numbers match whatever their type
+				if !ok || fmt.Sprint(v) != fmt.Sprint(want) {
+					return false
+				}
+			}
+			return true
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// applyRules returns e as changed by any matching rules, copying it first so nothing the
+// publisher shares is changed. The labels a rule adds to the run are kept and added to
+// every later event of the run. applyRules must be called in the lock.
+func (q *Queue) applyRules(e Event) Event {
+	copied := false
+	k := e.RunRef.key()
+	adopted := e.RunRef.Adopted()
+	if labels := q.runLabels[k]; adopted && len(labels) > 0 {
+		e = e.copy()
+		copied = true
+		e.RunRef.Labels = addLabels(e.RunRef.Labels, labels)
+	}
+	for _, r := range q.rules {
+		if r.Match != nil && !r.Match(e) {
+			continue
+		}
+		if !copied {
+			e = e.copy()
+			copied = true
+		}
+		if r.Level != LevelUnset {
+			e.Level = r.Level
+		}
+		if len(r.Labels) > 0 {
+			e.RunRef.Labels = addLabels(e.RunRef.Labels, r.Labels)
+			if adopted {
+				if q.runLabels == nil {
+					q.runLabels = map[runKey]map[string]string{}
+				}
+				q.runLabels[k] = addLabels(q.runLabels[k], r.Labels)
+			}
+		}
+		if r.Tag != "" {
+			e.Tag = NormalizeTag(r.Tag)
+		}
+		q.logger.Debugf("<%s> - queue - rule %s applied to %s", e.RunRef, r.Name, e.Tag)
+	}
+	if e.Tag == TagEndFlow {
+		delete(q.runLabels, k)
+	}
+	return e
+}
+
+// addLabels adds the labels to to, making it if needed, and returns it
+func addLabels(to, labels map[string]string) map[string]string {
+	if to == nil {
+		to = make(map[string]string, len(labels))
+	}
+	for k, v := range labels {
+		to[k] = v
+	}
+	return to
+}
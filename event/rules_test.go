@@ -0,0 +1,96 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestRules(t *testing.T) {
+	rules, err := ConfigRules([]config.Rule{
+		{
+			Name:  "flaky lint",
+			Tag:   "task.*.error",
+			Opts:  map[string]interface{}{"check.name": "lint", "exit": 2},
+			Level: "warning",
+		},
+		{
+			Tag:    "trigger.good",
+			Labels: map[string]string{"team": "web"},
+		},
+		{
+			Tag:    "task.deploy.good",
+			SetTag: "Deploy.Prod.Success",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts := make(chanObs, 10)
+	q := NewQueue(WithRules(rules...), WithAlertObserver(alerts))
+	c := make(chanObs, 10)
+	q.Register(c)
+
+	ref := testRef(1)
+	lint := config.NodeRef{Class: "task", ID: "lint"}
+	opts := nt.Opts{"check": nt.Opts{"name": "lint"}, "exit": 2.0}
+	q.Publish(Event{RunRef: ref, SourceNode: lint, Tag: "task.lint.error", ExecID: 1, Outcome: "error", Opts: opts})
+	e := next(t, c)
+	if e.Level != LevelWarn || e.Severity() != LevelWarn {
+		t.Error("the error should be downgraded", e.Level)
+	}
+	select {
+	case a := <-alerts:
+		t.Error("a downgraded error should not alert", a.Tag)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// not matching the opts is still an error
+	q.Publish(Event{RunRef: ref, SourceNode: lint, Tag: "task.lint.error", ExecID: 2, Outcome: "error", Opts: nt.Opts{"exit": 1}})
+	if e := next(t, c); e.Level != LevelUnset || e.Severity() != LevelError {
+		t.Error("only matching errors should be downgraded", e.Level)
+	}
+	next(t, alerts)
+
+	// the label is added to a copy
+	trig := Event{RunRef: ref, Tag: "trigger.good", Good: true}
+	q.Publish(trig)
+	if e := next(t, c); e.RunRef.Labels["team"] != "web" {
+		t.Error("the label should be added", e.RunRef.Labels)
+	}
+	if trig.RunRef.Labels != nil {
+		t.Error("the rule changed the published event", trig.RunRef.Labels)
+	}
+
+	q.Publish(Event{RunRef: ref, Tag: "task.deploy.good", Good: true})
+	e = next(t, c)
+	if e.Tag != "deploy.prod.success" {
+		t.Error("the tag should be replaced and normalised", e.Tag)
+	}
+	if e.RunRef.Labels["team"] != "web" {
+		t.Error("later events of the run should keep the label", e.RunRef.Labels)
+	}
+	q.Publish(Event{RunRef: testRef(2), Tag: "task.build.good", Good: true})
+	if e := next(t, c); e.RunRef.Labels != nil {
+		t.Error("the label should only be added to its run", e.RunRef.Labels)
+	}
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow, Good: true})
+	q.Publish(Event{RunRef: ref, Tag: "task.late.good", Good: true})
+	got := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		e := next(t, c)
+		got[e.Tag] = e.RunRef.Labels["team"] == "web"
+	}
+	if !got[TagEndFlow] || !got[TagRunSummary] || got["task.late.good"] {
+		t.Error("the end and summary should have the label, but nothing after them", got)
+	}
+
+	if _, err := ConfigRules([]config.Rule{{Level: "loud"}}); err == nil {
+		t.Error("a bad level should be an error")
+	}
+	if _, err := ConfigRules([]config.Rule{{Tag: "task.[.good"}}); err == nil {
+		t.Error("a bad glob should be an error")
+	}
+}
@@ -0,0 +1,37 @@
+package event
+
+import nt "github.com/floeit/floe/config/nodetype"
+
+// accumulate merges the opts of e into the run opts of its run. Only events from nodes
+// are merged, system events are not. The run opts are dropped when the run ends.
+// accumulate must be called in the lock.
+func (q *Queue) accumulate(e Event) {
+	if !e.RunRef.Adopted() {
+		return
+	}
+	k := e.RunRef.key()
+	if e.Tag == TagEndFlow {
+		delete(q.runOpts, k)
+		return
+	}
+	if e.IsSystem() || len(e.Opts) == 0 {
+		return
+	}
+	if q.runOpts == nil {
+		q.runOpts = map[runKey]nt.Opts{}
+	}
+	q.runOpts[k] = nt.MergeOpts(q.runOpts[k], e.Opts)
+}
+
+// RunOpts returns the opts of all the node events in the active run merged in publish
+// order with nt.MergeOpts - so later events replace the values of earlier ones, apart
+// from env lists which are appended. Once the run has ended nil is returned.
+func (q *Queue) RunOpts(ref RunRef) nt.Opts {
+	q.RLock()
+	defer q.RUnlock()
+	o, ok := q.runOpts[ref.key()]
+	if !ok {
+		return nil
+	}
+	return nt.MergeOpts(o, nil)
+}
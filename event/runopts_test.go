@@ -0,0 +1,54 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestRunOpts(t *testing.T) {
+	q := NewQueue()
+	ref := testRef(1)
+	q.Publish(Event{RunRef: ref, Tag: "trigger.good", Opts: nt.Opts{
+		"branch": "master",
+		"env":    []interface{}{"A=1"},
+	}})
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good", Opts: nt.Opts{
+		"artifact": "build.tgz",
+		"env":      []interface{}{"B=2"},
+	}})
+	q.Publish(UpdateEvent(ref, config.NodeRef{Class: "task", ID: "build"}, StreamStdout, 1, "not merged"))
+	q.Publish(Event{RunRef: ref, Tag: "task.pack.good", Opts: nt.Opts{
+		"artifact": "pack.tgz",
+	}})
+	q.Publish(Event{RunRef: testRef(2), Tag: "task.other.good", Opts: nt.Opts{"other": 1}})
+
+	o := q.RunOpts(ref)
+	if o["branch"] != "master" {
+		t.Error("first event opts missing")
+	}
+	if o["artifact"] != "pack.tgz" {
+		t.Error("later events should win", o["artifact"])
+	}
+	if env, _ := o["env"].([]interface{}); len(env) != 2 {
+		t.Error("env should be appended", o["env"])
+	}
+	if _, ok := o["line"]; ok {
+		t.Error("system event opts should not be merged")
+	}
+	if _, ok := o["other"]; ok {
+		t.Error("other run opts merged")
+	}
+
+	// returned opts are a copy
+	o["branch"] = "changed"
+	if q.RunOpts(ref)["branch"] != "master" {
+		t.Error("run opts changed via the returned copy")
+	}
+
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow})
+	if q.RunOpts(ref) != nil {
+		t.Error("run opts should be dropped when the run ends")
+	}
+}
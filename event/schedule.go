@@ -0,0 +1,212 @@
+package event
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// schedule returns the next time it is due after t, the zero time if never
+type schedule interface {
+	next(t time.Time) time.Time
+}
+
+// Schedule publishes the event returned by makeEvent each time spec is due, timed by the
+// queue clock, until cancel is called, so timer triggers need no ticker of their own.
+// The spec is either "@every d", with d a duration such as 250ms, or six cron fields:
+//
+//	second minute hour day-of-month month day-of-week
+//
+// Each field is a *, a number, a range a-b, a step */n or a-b/n, or a list of these
+// separated by commas. Days of the week are 0 to 6 from Sunday.
+func (q *Queue) Schedule(spec string, makeEvent func() Event) (cancel func(), err error) {
+	s, err := parseSchedule(spec)
+	if err != nil {
+		return nil, err
+	}
+	clock := q.getClock()
+
+	var mu sync.Mutex
+	var timer Timer
+	stopped := false
+	var arm func(from time.Time)
+	// arm must be called with mu held
+	arm = func(from time.Time) {
+		due := s.next(from)
+		if due.IsZero() {
+			return
+		}
+		timer = clock.AfterFunc(due.Sub(clock.Now()), func() {
+			mu.Lock()
+			if stopped {
+				mu.Unlock()
+				return
+			}
+			arm(due)
+			mu.Unlock()
+			q.Publish(makeEvent())
+		})
+	}
+	mu.Lock()
+	arm(clock.Now())
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		if timer != nil {
+			timer.Stop()
+		}
+	}, nil
+}
+
+// every is a schedule due each period
+type every time.Duration
+
+func (d every) next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
+
+// cronSpec is a schedule due whenever all its fields match, each field is a bit set
+type cronSpec struct {
+	second, minute, hour, dom, month, dow uint64
+	// when both days are limited either matching is enough, as with cron
+	anyDom, anyDow bool
+}
+
+// cronFields are the bounds of each field of a cron spec in order
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"second", 0, 59},
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// parseSchedule parses a schedule spec as described by Queue.Schedule
+func parseSchedule(spec string) (schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, fmt.Errorf("bad schedule '%s': %v", spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("bad schedule '%s': period must be positive", spec)
+		}
+		return every(d), nil
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("bad schedule '%s': wanted %d fields got %d", spec, len(cronFields), len(fields))
+	}
+	sets := make([]uint64, len(fields))
+	for i, f := range fields {
+		b := cronFields[i]
+		set, err := parseCronField(f, b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("bad schedule '%s' %s: %v", spec, b.name, err)
+		}
+		sets[i] = set
+	}
+	return &cronSpec{
+		second: sets[0],
+		minute: sets[1],
+		hour:   sets[2],
+		dom:    sets[3],
+		month:  sets[4],
+		dow:    sets[5],
+		anyDom: fields[3] == "*",
+		anyDow: fields[5] == "*",
+	}, nil
+}
+
+// parseCronField returns the bit set of the values the field f matches in min to max
+func parseCronField(f string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(f, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step in '%s'", part)
+			}
+			step = n
+			part = part[:i]
+		}
+		lo, hi := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("bad range '%s'", part)
+			}
+			if hi, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("bad range '%s'", part)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("bad value '%s'", part)
+			}
+			lo, hi = n, n
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("'%s' is outside %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// dayMatches returns true if the day of t matches the day of month and day of week
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.anyDom || c.anyDow {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next finds the next matching second after t by moving on to the start of the next
+// month, day, hour or minute whenever that field does not match. Specs that can never
+// match, such as the 31st of February, give up after five years.
+func (c *cronSpec) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		y, mo, d := t.Date()
+		h, mi, s := t.Clock()
+		switch {
+		case !has(c.month, int(mo)):
+			t = time.Date(y, mo+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(y, mo, d+1, 0, 0, 0, 0, loc)
+		case !has(c.hour, h):
+			t = time.Date(y, mo, d, h+1, 0, 0, 0, loc)
+		case !has(c.minute, mi):
+			t = time.Date(y, mo, d, h, mi+1, 0, 0, loc)
+		case !has(c.second, s):
+			t = time.Date(y, mo, d, h, mi, s+1, 0, loc)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,73 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk))
+	got := make(chanObs, 100)
+	q.Subscribe(got, "inbound.timer")
+
+	cancel, err := q.Schedule("@every 250ms", func() Event {
+		return NewTriggerEvent(testRef(0).FlowRef, "timer", nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk.Advance(200 * time.Millisecond)
+	if len(got) != 0 {
+		t.Fatal("fired early")
+	}
+	clk.Advance(800 * time.Millisecond)
+	for i := 0; i < 4; i++ {
+		next(t, got)
+	}
+
+	cancel()
+	clk.Advance(time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if len(got) != 0 {
+		t.Error("fired after cancel", len(got))
+	}
+
+	if _, err := q.Schedule("@every 0s", nil); err == nil {
+		t.Error("expected error for a zero period")
+	}
+}
+
+func TestScheduleSpec(t *testing.T) {
+	// the fake clock starts at 2017-11-01 12:00:00 UTC, a Wednesday
+	from := NewFakeClock().Now()
+	fix := []struct {
+		spec string
+		want string
+	}{
+		{"*/15 * * * * *", "2017-11-01 12:00:15"},
+		{"0 30 * * * *", "2017-11-01 12:30:00"},
+		{"0 0 9 * * *", "2017-11-02 09:00:00"},
+		{"0 0 9 * * 1-5", "2017-11-02 09:00:00"},
+		{"0 0 0 * * 0", "2017-11-05 00:00:00"},
+		{"0 0 0 1 1 *", "2018-01-01 00:00:00"},
+		{"10,20 5 12 1 11 *", "2017-11-01 12:05:10"},
+		{"0 0 0 31 2 *", "0001-01-01 00:00:00"},
+	}
+	for _, f := range fix {
+		s, err := parseSchedule(f.spec)
+		if err != nil {
+			t.Error(f.spec, err)
+			continue
+		}
+		if got := s.next(from).Format("2006-01-02 15:04:05"); got != f.want {
+			t.Errorf("%s: wanted %s got %s", f.spec, f.want, got)
+		}
+	}
+
+	for _, bad := range []string{"", "* * * * *", "60 * * * * *", "* * * * 0 *", "*/0 * * * * *", "a * * * * *", "5-1 * * * * *", "@every soon"} {
+		if _, err := parseSchedule(bad); err == nil {
+			t.Error("expected an error for", bad)
+		}
+	}
+}
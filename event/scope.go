@@ -0,0 +1,56 @@
+package event
+
+import "github.com/floeit/floe/config"
+
+// scope passes the events of one node in a run on to a child queue
+type scope struct {
+	parent *Queue
+	child  *Queue
+}
+
+func (s *scope) Notify(e Event) {
+	s.child.publish(e)
+	if e.Tag == TagEndFlow {
+		s.parent.Unregister(s)
+	}
+}
+
+// ScopeToNode returns a child queue that only sees the events of node in the run ref, so
+// that a plugin managing the node can have its own observers in isolation.
+//
+// Events published on the parent for the run whose SourceNode is node are also published
+// on the child, keeping the IDs and times given by the parent. The end of the run is
+// also passed to the child, after which the child is detached from the parent.
+// Events published on the child are given the run ref and node as their RunRef and
+// SourceNode and published on the parent, from where they are passed back to the child.
+// Observers on the parent see all events as normal.
+func (q *Queue) ScopeToNode(ref RunRef, node config.NodeRef) *Queue {
+	child := NewQueue()
+	child.parent = q
+	child.scopeRef = ref
+	child.scopeNode = node
+	err := q.RegisterWith(&scope{parent: q, child: child},
+		withRunScope(),
+		WithMaxConcurrent(1), // keep the parents order
+		withNamedFilter("node scope", func(e Event) bool {
+			return e.RunRef.Equal(ref) && (e.SourceNode == node || e.Tag == TagEndFlow)
+		}))
+	if err != nil {
+		q.getLogger().Errorf("<%s> - node %s scope will not see any events: %v", ref, node, err)
+	}
+	return child
+}
+
+// reparent publishes e on the parent queue as an event of the scoped node
+func (q *Queue) reparent(e Event) {
+	e.RunRef = q.scopeRef
+	e.SourceNode = q.scopeNode
+	q.parent.Publish(e)
+}
+
+// withRunScope marks the registration as only for events of a run
+func withRunScope() RegisterOption {
+	return func(r *registration) {
+		r.runScoped = true
+	}
+}
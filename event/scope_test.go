@@ -0,0 +1,62 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestScopeToNode(t *testing.T) {
+	q := NewQueue()
+	all := make(chanObs, 20)
+	q.Register(all)
+
+	ref := testRef(1)
+	build := config.NodeRef{Class: "task", ID: "build"}
+	child := q.ScopeToNode(ref, build)
+	scoped := make(chanObs, 20)
+	child.RegisterWith(scoped, WithMaxConcurrent(1))
+
+	q.Publish(Event{RunRef: ref, SourceNode: config.NodeRef{Class: "trigger", ID: "push"}, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: testRef(2), SourceNode: build, Tag: "task.build.good"}) // another run
+	q.Publish(Event{RunRef: ref, SourceNode: build, Tag: "sys.node.start"})
+	// the plugin publishes on the child with no run or node
+	child.Publish(Event{Tag: "task.build.progress"})
+
+	e := next(t, scoped)
+	if e.Tag != "sys.node.start" {
+		t.Fatal("scoped queue should only see the node events", e.Tag)
+	}
+	pe := e
+	e = next(t, scoped)
+	if e.Tag != "task.build.progress" || !e.RunRef.Equal(ref) || e.SourceNode != build {
+		t.Error("child publish should be re-parented", e)
+	}
+	if e.ID <= pe.ID {
+		t.Error("scoped events should keep the parent IDs", e.ID, pe.ID)
+	}
+
+	// the parent saw everything, including the re-parented event
+	var tags []string
+	for i := 0; i < 4; i++ {
+		tags = append(tags, next(t, all).Tag)
+	}
+	found := false
+	for _, tag := range tags {
+		if tag == "task.build.progress" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("parent should see child events", tags)
+	}
+
+	// the end of the run detaches the child
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow})
+	if e := next(t, scoped); e.Tag != TagEndFlow {
+		t.Error("child should see the end of the run", e.Tag)
+	}
+	if e := next(t, scoped); e.Tag != TagRunSummary {
+		t.Error("child should summarise its run", e.Tag)
+	}
+}
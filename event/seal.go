@@ -0,0 +1,49 @@
+package event
+
+import (
+	"fmt"
+	"reflect"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// maxSealed is how many of the most recently published events are checked for mutation
+const maxSealed = 32
+
+// sealedOpts is the opts of a published event, as shared with its publisher, along with
+// a copy of them as they were when published
+type sealedOpts struct {
+	id   int64
+	tag  string
+	opts nt.Opts
+	snap nt.Opts
+}
+
+// seal records the opts of the published event e so later changes by its publisher can be
+// detected. It is only done in builds with the floedebug tag. seal must be called in the lock.
+func (q *Queue) seal(e Event) {
+	if !sealing || len(e.Opts) == 0 {
+		return
+	}
+	q.sealed = append(q.sealed, sealedOpts{id: e.ID, tag: e.Tag, opts: e.Opts, snap: deepOpts(e.Opts, nil)})
+	if len(q.sealed) > maxSealed {
+		q.sealed[0] = sealedOpts{}
+		q.sealed = q.sealed[1:]
+	}
+}
+
+// checkSealed panics if the publisher of a recent event has changed its opts since it was
+// published, which races with the copies made for observers. It is only done in builds
+// with the floedebug tag. checkSealed must be called in the lock.
+func (q *Queue) checkSealed() {
+	if !sealing {
+		return
+	}
+	for _, s := range q.sealed {
+		// compared as copied so values that can not be copied are not taken for changes
+		if !reflect.DeepEqual(deepOpts(s.opts, nil), s.snap) {
+			panic(fmt.Sprintf("event: opts of published event %d (%s) changed after Publish, publishers must not modify an event once published - was %v now %v",
+				s.id, s.tag, s.snap, s.opts))
+		}
+	}
+}
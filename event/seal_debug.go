@@ -0,0 +1,7 @@
+//go:build floedebug
+// +build floedebug
+
+package event
+
+// sealing checks published events are not changed by their publisher
+const sealing = true
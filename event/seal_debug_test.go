@@ -0,0 +1,27 @@
+//go:build floedebug
+// +build floedebug
+
+package event
+
+import (
+	"strings"
+	"testing"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestMutateAfterPublish(t *testing.T) {
+	q := NewQueue()
+	e := Event{Tag: "task.build.good", Opts: nt.Opts{"branch": "master"}}
+	q.Publish(e)
+	e.Opts["branch"] = "release"
+
+	defer func() {
+		r := recover()
+		msg, _ := r.(string)
+		if !strings.Contains(msg, "changed after Publish") {
+			t.Error("expected a panic for the changed opts", r)
+		}
+	}()
+	q.Publish(Event{Tag: "next"})
+}
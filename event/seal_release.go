@@ -0,0 +1,7 @@
+//go:build !floedebug
+// +build !floedebug
+
+package event
+
+// sealing is only done in builds with the floedebug tag
+const sealing = false
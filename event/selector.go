@@ -0,0 +1,196 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selector operators
+const (
+	opEquals    = "="
+	opNotEquals = "!="
+	opIn        = "in"
+	opNotIn     = "notin"
+)
+
+// requirement is a single term of a selector, such as env in (staging,prod)
+type requirement struct {
+	key    string
+	op     string
+	values []string
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	v, ok := labels[r.key]
+	in := false
+	for _, want := range r.values {
+		if ok && v == want {
+			in = true
+			break
+		}
+	}
+	switch r.op {
+	case opEquals, opIn:
+		return in
+	default: // a missing label is not equal to, and not in, anything
+		return !in
+	}
+}
+
+// Selector matches run labels, all its requirements must be satisfied.
+type Selector []requirement
+
+// Matches returns true if labels satisfy all of the requirements of s.
+// An empty selector matches everything.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSelector parses a comma separated list of label requirements, each of which is
+// one of:
+//
+//	key = value
+//	key != value
+//	key in (value, value...)
+//	key notin (value, value...)
+func ParseSelector(sel string) (Selector, error) {
+	p := &selParser{s: sel}
+	var s Selector
+	for {
+		p.space()
+		if p.end() {
+			if len(s) > 0 {
+				return nil, p.errorf("expected a requirement after ','")
+			}
+			return s, nil
+		}
+		r, err := p.requirement()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, r)
+		p.space()
+		if p.end() {
+			return s, nil
+		}
+		if !p.take(",") {
+			return nil, p.errorf("expected ','")
+		}
+	}
+}
+
+// selParser scans a selector string
+type selParser struct {
+	s   string
+	pos int
+}
+
+func (p *selParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("bad selector %q at %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *selParser) end() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *selParser) space() {
+	for !p.end() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// take consumes tok if it is next
+func (p *selParser) take(tok string) bool {
+	if strings.HasPrefix(p.s[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+// word consumes the next label key or value
+func (p *selParser) word() string {
+	start := p.pos
+	for !p.end() && isLabelChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func isLabelChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c == '/'
+}
+
+func (p *selParser) requirement() (requirement, error) {
+	r := requirement{key: p.word()}
+	if r.key == "" {
+		return r, p.errorf("expected a label key")
+	}
+	p.space()
+	switch {
+	case p.take(opNotEquals):
+		r.op = opNotEquals
+	case p.take(opEquals):
+		r.op = opEquals
+	default:
+		r.op = p.word()
+		if r.op != opIn && r.op != opNotIn {
+			return r, p.errorf("unknown operator %q", r.op)
+		}
+		vals, err := p.set()
+		if err != nil {
+			return r, err
+		}
+		r.values = vals
+		return r, nil
+	}
+	p.space()
+	v := p.word()
+	if v == "" {
+		return r, p.errorf("expected a value for %q", r.key)
+	}
+	r.values = []string{v}
+	return r, nil
+}
+
+// set consumes a bracketed list of values
+func (p *selParser) set() ([]string, error) {
+	p.space()
+	if !p.take("(") {
+		return nil, p.errorf("expected '('")
+	}
+	var vals []string
+	for {
+		p.space()
+		v := p.word()
+		if v == "" {
+			return nil, p.errorf("expected a value")
+		}
+		vals = append(vals, v)
+		p.space()
+		if p.take(")") {
+			return vals, nil
+		}
+		if !p.take(",") {
+			return nil, p.errorf("expected ',' or ')'")
+		}
+	}
+}
+
+// RegisterSelector registers an observer that is only sent the events of runs whose
+// labels match the selector sel, see ParseSelector.
+func (q *Queue) RegisterSelector(sel string, o Observer) error {
+	s, err := ParseSelector(sel)
+	if err != nil {
+		return err
+	}
+	return q.RegisterWith(o, withNamedFilter("label selector", func(e Event) bool {
+		return s.Matches(e.RunRef.Labels)
+	}))
+}
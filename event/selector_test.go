@@ -0,0 +1,99 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	staging := map[string]string{"env": "staging", "team": "core"}
+	prod := map[string]string{"env": "prod"}
+	none := map[string]string{}
+
+	fix := []struct {
+		sel  string
+		want []bool // staging, prod, none
+	}{
+		{sel: "", want: []bool{true, true, true}},
+		{sel: "env=staging", want: []bool{true, false, false}},
+		{sel: "env = prod", want: []bool{false, true, false}},
+		{sel: "env!=prod", want: []bool{true, false, true}},
+		{sel: "env in (staging,prod)", want: []bool{true, true, false}},
+		{sel: "env in ( prod )", want: []bool{false, true, false}},
+		{sel: "env notin (staging, dev)", want: []bool{false, true, true}},
+		{sel: "env in (staging,prod), team=core", want: []bool{true, false, false}},
+	}
+	for i, f := range fix {
+		s, err := ParseSelector(f.sel)
+		if err != nil {
+			t.Errorf("%d: %q failed to parse: %v", i, f.sel, err)
+			continue
+		}
+		for j, l := range []map[string]string{staging, prod, none} {
+			if got := s.Matches(l); got != f.want[j] {
+				t.Errorf("%d: %q on %v got %v", i, f.sel, l, got)
+			}
+		}
+	}
+}
+
+func TestSelectorMalformed(t *testing.T) {
+	bad := []string{
+		"env",
+		"=prod",
+		"env=",
+		"env==",
+		"env > 1",
+		"env in staging",
+		"env in (staging",
+		"env in ()",
+		"env in (a,)",
+		"env=prod,",
+		"env=prod team=core",
+		", env=prod",
+	}
+	for _, sel := range bad {
+		if _, err := ParseSelector(sel); err == nil {
+			t.Errorf("%q should not parse", sel)
+		}
+	}
+}
+
+func TestRegisterSelector(t *testing.T) {
+	q := NewQueue()
+	if err := q.RegisterSelector("env in (", &listener{}); err == nil {
+		t.Error("bad selector should error")
+	}
+
+	var mu sync.Mutex
+	var got []int64
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	err := q.RegisterSelector("env in (staging,prod)", &listener{what: func(e Event) {
+		mu.Lock()
+		got = append(got, e.RunRef.Run.ID)
+		mu.Unlock()
+		wg.Done()
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dev := testRef(1)
+	dev.Labels = map[string]string{"env": "dev"}
+	prod := testRef(2)
+	prod.Labels = map[string]string{"env": "prod"}
+	q.Publish(Event{RunRef: dev, Tag: "start"})
+	q.Publish(Event{RunRef: testRef(3), Tag: "start"})
+	q.Publish(Event{RunRef: prod, Tag: "start"})
+	wg.Wait()
+
+	// give any wrongly delivered events a chance to arrive
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0] != 2 {
+		t.Error("wrong runs selected", got)
+	}
+}
@@ -0,0 +1,72 @@
+//go:build go1.21
+// +build go1.21
+
+package event
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// SlogObserver writes each event it is sent as a record to a log/slog logger, so the
+// events can join an existing log pipeline
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+// NewSlogObserver returns an observer logging to logger. Each record has the time of the
+// event, the tag as its message, and the run_ref, tag, source_node, good and id of the
+// event as attributes, with the opts in an opts group. Records have the level of the
+// Severity of the event.
+func NewSlogObserver(logger *slog.Logger) Observer {
+	return &SlogObserver{logger: logger}
+}
+
+// Notify satisfies Observer
+func (s *SlogObserver) Notify(e Event) {
+	s.NotifyCtx(context.Background(), e)
+}
+
+// NotifyCtx satisfies ContextObserver passing ctx on to the slog handler
+func (s *SlogObserver) NotifyCtx(ctx context.Context, e Event) error {
+	h := s.logger.Handler()
+	level := slogLevel(e)
+	if !h.Enabled(ctx, level) {
+		return nil
+	}
+	r := slog.NewRecord(e.Time, level, e.Tag, 0)
+	r.AddAttrs(
+		slog.String("run_ref", e.RunRef.String()),
+		slog.String("tag", e.Tag),
+		slog.String("source_node", e.SourceNode.String()),
+		slog.Bool("good", e.Good),
+		slog.Int64("id", e.ID),
+	)
+	if len(e.Opts) > 0 {
+		keys := make([]string, 0, len(e.Opts))
+		for k := range e.Opts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		opts := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			opts = append(opts, slog.Any(k, e.Opts[k]))
+		}
+		r.AddAttrs(slog.Group("opts", opts...))
+	}
+	return h.Handle(ctx, r)
+}
+
+// slogLevel is the slog level of the Severity of e
+func slogLevel(e Event) slog.Level {
+	switch e.Severity() {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,86 @@
+//go:build go1.21
+// +build go1.21
+
+package event
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// slogCapture is a slog handler keeping every record
+type slogCapture struct {
+	sync.Mutex
+	records []slog.Record
+}
+
+func (c *slogCapture) Enabled(context.Context, slog.Level) bool { return true }
+func (c *slogCapture) WithAttrs([]slog.Attr) slog.Handler       { return c }
+func (c *slogCapture) WithGroup(string) slog.Handler            { return c }
+
+func (c *slogCapture) Handle(_ context.Context, r slog.Record) error {
+	c.Lock()
+	defer c.Unlock()
+	c.records = append(c.records, r)
+	return nil
+}
+
+func attrs(r slog.Record) map[string]slog.Value {
+	m := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value
+		return true
+	})
+	return m
+}
+
+func TestSlogObserver(t *testing.T) {
+	h := &slogCapture{}
+	o := NewSlogObserver(slog.New(h))
+	at := time.Date(2017, 11, 1, 12, 0, 0, 0, time.UTC)
+	o.Notify(Event{
+		RunRef:     testRef(3),
+		SourceNode: config.NodeRef{Class: "task", ID: "build"},
+		Tag:        "task.build.bad",
+		ExecID:     1,
+		Outcome:    config.SubTagBad,
+		ID:         9,
+		Time:       at,
+		Opts:       nt.Opts{"exit": 2, "line": "boom"},
+	})
+	o.Notify(Event{RunRef: testRef(3), Tag: "task.build.good", Good: true, ExecID: 2, Outcome: config.SubTagGood})
+
+	if len(h.records) != 2 {
+		t.Fatal("expected a record per event", len(h.records))
+	}
+	r := h.records[0]
+	if r.Level != slog.LevelError || r.Message != "task.build.bad" || !r.Time.Equal(at) {
+		t.Error("bad record", r.Level, r.Message, r.Time)
+	}
+	a := attrs(r)
+	if a["run_ref"].String() != testRef(3).String() || a["tag"].String() != "task.build.bad" ||
+		a["source_node"].String() != "task.build" || a["good"].Bool() || a["id"].Int64() != 9 {
+		t.Error("bad attributes", a)
+	}
+	opts := map[string]string{}
+	for _, g := range a["opts"].Group() {
+		opts[g.Key] = g.Value.String()
+	}
+	if len(opts) != 2 || opts["exit"] != "2" || opts["line"] != "boom" {
+		t.Error("bad opts group", opts)
+	}
+
+	r = h.records[1]
+	if r.Level != slog.LevelInfo || !attrs(r)["good"].Bool() {
+		t.Error("good result should be info", r.Level)
+	}
+	if _, ok := attrs(r)["opts"]; ok {
+		t.Error("no opts should mean no opts group")
+	}
+}
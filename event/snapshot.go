@@ -0,0 +1,219 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// snapshotVersion is the layout version of the queue snapshot
+const snapshotVersion = 1
+
+// queueSnapshot is the serialisable state of a Queue
+type queueSnapshot struct {
+	Version     int
+	IDCounter   int64
+	ExecCounter int64
+	ChainHead   string
+	Tail        []Event
+	Runs        []runSnapshot
+	Causal      []causalSnapshot
+}
+
+type runSnapshot struct {
+	Ref       RunRef
+	Events    []Event
+	Evicted   int64
+	Ended     bool
+	ChainHead string
+	Trigger   *Event
+	Total     int
+	Nodes     []config.NodeRef
+	First     time.Time
+	Last      time.Time
+
+	// the state of a run that has not ended
+	Start  int64 // the ID the run started at, 0 if not active
+	Opts   nt.Opts
+	Labels map[string]string // added by rules
+	Ends   []endSnapshot
+}
+
+// endSnapshot is the end tag of a node execution, see endsOnce
+type endSnapshot struct {
+	Node config.NodeRef
+	Exec int64
+	Tag  string
+}
+
+type causalSnapshot struct {
+	Ref   RunRef
+	Clock int64
+}
+
+// Snapshot serialises the state of the queue - the event counters, the tail, the run
+// histories, causal clocks, the active runs with their run opts, rule labels and the node
+// executions that have ended - so that a new process can carry on with RestoreQueue.
+//
+// Only state is included, not configuration or delivery. Observers, their buffered
+// events and any events scheduled with PublishAfter are lost, rate limit buckets and
+// tenant quotas start full again, loop breaker counts start again, and stale run
+// detection restarts with the next event for each run. Opts values come back as their
+// json types, so for example ints become float64.
+func (q *Queue) Snapshot() ([]byte, error) {
+	q.RLock()
+	defer q.RUnlock()
+	s := queueSnapshot{
+		Version:     snapshotVersion,
+		IDCounter:   q.idCounter,
+		ExecCounter: q.execCounter,
+		ChainHead:   q.chainHead,
+	}
+	if q.tail != nil {
+		s.Tail = q.tail.last(len(q.tail.events))
+	}
+	// active runs then the ended runs in the order they ended so that the oldest are still trimmed first
+	for k, h := range q.history {
+		if !h.ended {
+			s.Runs = append(s.Runs, q.snapshotRun(k, h))
+		}
+	}
+	for _, k := range q.done {
+		if h, ok := q.history[k]; ok {
+			s.Runs = append(s.Runs, q.snapshotRun(k, h))
+		}
+	}
+	for k, c := range q.causal {
+		s.Causal = append(s.Causal, causalSnapshot{
+			Ref:   RunRef{FlowRef: k.flow, Run: k.run},
+			Clock: c,
+		})
+	}
+	return json.Marshal(s)
+}
+
+// snapshotRun must be called in the lock
+func (q *Queue) snapshotRun(k runKey, h *runHistory) runSnapshot {
+	rs := runSnapshot{
+		Ref:       h.ref,
+		Events:    h.events,
+		Evicted:   h.evicted,
+		Ended:     h.ended,
+		ChainHead: h.chainHead,
+		Trigger:   h.trigger,
+		Total:     h.total,
+		First:     h.first,
+		Last:      h.last,
+	}
+	for n := range h.nodes {
+		rs.Nodes = append(rs.Nodes, n)
+	}
+	if h.ended {
+		return rs
+	}
+	if a, ok := q.active[k]; ok {
+		rs.Start = a.start
+	}
+	rs.Opts = q.runOpts[k]
+	rs.Labels = q.runLabels[k]
+	for ek, tag := range q.ended[k] {
+		rs.Ends = append(rs.Ends, endSnapshot{Node: ek.node, Exec: ek.exec, Tag: tag})
+	}
+	return rs
+}
+
+// RestoreQueue returns a new queue configured with opts, with the state from a Snapshot.
+// Observers must be registered again on the new queue.
+func RestoreQueue(b []byte, opts ...QueueOption) (*Queue, error) {
+	s := queueSnapshot{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported queue snapshot version %d", s.Version)
+	}
+	q := NewQueue(opts...)
+	q.idCounter = s.IDCounter
+	q.execCounter = s.ExecCounter
+	q.chainHead = s.ChainHead
+	for _, e := range s.Tail {
+		q.tail.add(e)
+	}
+	q.history = map[runKey]*runHistory{}
+	for _, rs := range s.Runs {
+		h := &runHistory{
+			ref:       rs.Ref,
+			events:    rs.Events,
+			evicted:   rs.Evicted,
+			ended:     rs.Ended,
+			chainHead: rs.ChainHead,
+			trigger:   rs.Trigger,
+			total:     rs.Total,
+			nodes:     map[config.NodeRef]bool{},
+			first:     rs.First,
+			last:      rs.Last,
+		}
+		for _, n := range rs.Nodes {
+			h.nodes[n] = true
+		}
+		// snapshots from before the trigger was kept may still have it in the events
+		for i := 0; h.trigger == nil && i < len(h.events); i++ {
+			if isTrigger(h.events[i]) {
+				t := h.events[i].copy()
+				h.trigger = &t
+			}
+		}
+		k := rs.Ref.key()
+		q.history[k] = h
+		if h.ended {
+			q.done = append(q.done, k)
+			continue
+		}
+		q.restoreRun(k, rs)
+	}
+	q.trimDone()
+	q.causal = map[runKey]int64{}
+	for _, c := range s.Causal {
+		q.causal[c.Ref.key()] = c.Clock
+	}
+	return q, nil
+}
+
+// restoreRun restores the state of the run that has not ended
+func (q *Queue) restoreRun(k runKey, rs runSnapshot) {
+	if rs.Start > 0 {
+		if q.active == nil {
+			q.active = map[runKey]activeRun{}
+		}
+		q.active[k] = activeRun{ref: rs.Ref, start: rs.Start}
+	}
+	if q.runFlows == nil {
+		q.runFlows = map[HostedIDRef]config.FlowRef{}
+	}
+	q.runFlows[rs.Ref.Run] = rs.Ref.FlowRef
+	if len(rs.Opts) > 0 {
+		if q.runOpts == nil {
+			q.runOpts = map[runKey]nt.Opts{}
+		}
+		q.runOpts[k] = rs.Opts
+	}
+	if len(rs.Labels) > 0 {
+		if q.runLabels == nil {
+			q.runLabels = map[runKey]map[string]string{}
+		}
+		q.runLabels[k] = rs.Labels
+	}
+	if len(rs.Ends) > 0 {
+		if q.ended == nil {
+			q.ended = map[runKey]map[execKey]string{}
+		}
+		execs := map[execKey]string{}
+		for _, e := range rs.Ends {
+			execs[execKey{node: e.Node, exec: e.Exec}] = e.Tag
+		}
+		q.ended[k] = execs
+	}
+}
@@ -0,0 +1,95 @@
+package event
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	q := NewQueue()
+	active, ended := testRef(1), testRef(2)
+	q.Publish(Event{RunRef: ended, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: ended, Tag: TagEndFlow})
+	q.Publish(Event{RunRef: active, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: active, Tag: "task.build.good"})
+	q.NewExecID()
+
+	b, err := q.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nq, err := RestoreQueue(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h := nq.History(ended); len(h) != 3 {
+		t.Error("ended run history not restored", len(h))
+	}
+	if len(nq.Tail(100)) != len(q.Tail(100)) {
+		t.Error("tail not restored")
+	}
+	if id := nq.NewExecID(); id != 2 {
+		t.Error("exec counter not restored", id)
+	}
+
+	// the flow carries on in the new queue
+	r := &recorder{}
+	nq.Register(r)
+	e := r.publish(nq, Event{RunRef: active, Tag: "task.test.good"})
+	if e.ID != 6 {
+		t.Error("event IDs should carry on from the snapshot", e.ID)
+	}
+	if e.Causal != 3 {
+		t.Error("causal clock should carry on from the snapshot", e.Causal)
+	}
+	h := nq.History(active)
+	if len(h) != 3 || h[0].Tag != "trigger.good" || h[2].ID != 6 {
+		t.Error("active run history wrong", h)
+	}
+
+	if _, err := RestoreQueue([]byte(`{"Version":99}`)); err == nil {
+		t.Error("unknown snapshot version should fail")
+	}
+}
+
+func TestSnapshotRestoreActiveRuns(t *testing.T) {
+	q := NewQueue(WithStrict())
+	older, newer := testRef(1), testRef(2)
+	q.Publish(Event{RunRef: older, Tag: "trigger.good", Opts: nt.Opts{"branch": "master"}})
+	q.Publish(Event{RunRef: newer, Tag: "trigger.good"})
+	build := Event{RunRef: newer, SourceNode: config.NodeRef{Class: "task", ID: "build"},
+		Tag: "task.build.good", Outcome: "good", ExecID: 1}
+	q.Publish(build)
+	// the older run keeps going with none of its history retained
+	if err := q.ExportRun(older, &bytes.Buffer{}, JSONCodec{}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := q.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nq, err := RestoreQueue(b, WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs := nq.ActiveRuns()
+	if len(runs) != 2 || !runs[0].Equal(older) || !runs[1].Equal(newer) {
+		t.Error("active runs should be restored in the order they started", runs)
+	}
+	if o := nq.RunOpts(older); o["branch"] != "master" {
+		t.Error("run opts not restored", o)
+	}
+	c := make(chanObs, 10)
+	nq.RegisterWith(c, WithMaxConcurrent(1))
+	nq.Publish(build)
+	nq.Publish(Event{RunRef: newer, Tag: "task.test.good"})
+	if e := next(t, c); e.Tag != "task.test.good" {
+		t.Error("second end of a restored node execution should be dropped", e.Tag)
+	}
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// SSEObserver writes events to a http response as Server-Sent Events, each is a json
+// data frame with the event ID as the frame id so that clients can resume from it.
+// Register it with WithMaxConcurrent(1) to write the events in order. Done is closed
+// when the client has gone, the handler should then Unregister and Close the observer.
+type SSEObserver struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	done   chan struct{}
+	closed bool
+	logger Logger // the logger of the queue it is registered on
+}
+
+// NewSSEObserver sets the SSE headers on w and returns the observer writing to it
+func NewSSEObserver(w http.ResponseWriter) *SSEObserver {
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	return &SSEObserver{
+		w:      w,
+		done:   make(chan struct{}),
+		logger: defaultLogger,
+	}
+}
+
+// Done is closed once writing to the client has failed, or the observer is closed
+func (s *SSEObserver) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close stops any more events being written, it must be called before the handler
+// that owns the response returns.
+func (s *SSEObserver) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.close()
+}
+
+// useLogger satisfies queueLogged, so the observer logs to the queue it is registered on
+func (s *SSEObserver) useLogger(l Logger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logger = l
+}
+
+// close must be called in the lock
+func (s *SSEObserver) close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+}
+
+// Notify writes e as a single SSE frame and flushes it to the client
+func (s *SSEObserver) Notify(e Event) {
+	b, err := json.Marshal(e)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.logger.Errorf("could not encode event for sse: %v", err)
+		return
+	}
+	if s.closed {
+		return
+	}
+	if _, err := fmt.Fprintf(s.w, "id: %d\ndata: %s\n\n", e.ID, b); err != nil {
+		s.logger.Debugf("sse client gone: %v", err)
+		s.close()
+		return
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
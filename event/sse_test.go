@@ -0,0 +1,87 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEObserver(t *testing.T) {
+	q := NewQueue()
+	rec := httptest.NewRecorder()
+	o := NewSSEObserver(rec)
+	q.RegisterWith(o, WithMaxConcurrent(1))
+	q.Publish(Event{Tag: "a"})
+	q.Publish(Event{Tag: "b"})
+
+	// wait for the sse observer to have written both
+	deadline := time.Now().Add(time.Second)
+	for strings.Count(body(o, rec), "\n\n") < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !q.Unregister(o) {
+		t.Error("observer should have been registered")
+	}
+	o.Close()
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Error("wrong content type", ct)
+	}
+	if !rec.Flushed {
+		t.Error("frames should be flushed")
+	}
+	frames := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n\n"), "\n\n")
+	if len(frames) != 2 {
+		t.Fatalf("wrong frames %q", rec.Body.String())
+	}
+	for i, f := range frames {
+		lines := strings.Split(f, "\n")
+		if len(lines) != 2 || lines[0] != "id: "+string(rune('1'+i)) || !strings.HasPrefix(lines[1], "data: ") {
+			t.Errorf("bad frame %q", f)
+			continue
+		}
+		e := Event{}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &e); err != nil {
+			t.Error("data not json", err)
+		}
+		if e.ID != int64(i+1) {
+			t.Error("wrong event in frame", e.ID)
+		}
+	}
+
+	// nothing is written once closed
+	o.Notify(Event{ID: 3, Tag: "c"})
+	if strings.Contains(rec.Body.String(), "id: 3") {
+		t.Error("closed observer should not write")
+	}
+}
+
+// body reads the recorded body under the observer lock
+func body(o *SSEObserver, rec *httptest.ResponseRecorder) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return rec.Body.String()
+}
+
+// goneWriter fails every write as if the client disconnected
+type goneWriter struct {
+	http.ResponseWriter
+}
+
+func (goneWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestSSEObserverDisconnect(t *testing.T) {
+	o := NewSSEObserver(goneWriter{httptest.NewRecorder()})
+	o.Notify(Event{ID: 1, Tag: "a"})
+	select {
+	case <-o.Done():
+	default:
+		t.Error("done should be closed when the client has gone")
+	}
+}
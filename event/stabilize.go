@@ -0,0 +1,120 @@
+package event
+
+import (
+	"sync"
+	"time"
+
+	"github.com/floeit/floe/config"
+)
+
+// Stabilizer is an Observer that coalesces flapping end events. The good, bad or error
+// event of a node is held for window, if another end event from the same node in the same
+// run arrives in that time it replaces the held one, so only the settled state is passed
+// on to inner. All other events are passed on immediately, an end of flow event first
+// sends any events still held for its run.
+type Stabilizer struct {
+	sync.Mutex
+	inner  Observer
+	window time.Duration
+	clock  Clock
+	detail map[string]bool // flow ids that want every end event
+
+	held map[runKey]map[config.NodeRef]*heldEnd
+}
+
+// heldEnd is the latest end event of a node waiting for the window to pass
+type heldEnd struct {
+	e     Event
+	timer Timer
+}
+
+// NewStabilizer returns a Stabilizer passing settled events to inner, timed by clock,
+// which if nil is the real time.
+func NewStabilizer(inner Observer, window time.Duration, clock Clock) *Stabilizer {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &Stabilizer{
+		inner:  inner,
+		window: window,
+		clock:  clock,
+		detail: map[string]bool{},
+		held:   map[runKey]map[config.NodeRef]*heldEnd{},
+	}
+}
+
+// Detail marks the flows whose end events are all passed on without being coalesced
+func (s *Stabilizer) Detail(flowIDs ...string) *Stabilizer {
+	s.Lock()
+	defer s.Unlock()
+	for _, id := range flowIDs {
+		s.detail[id] = true
+	}
+	return s
+}
+
+// Notify holds end events until they settle and passes all others on
+func (s *Stabilizer) Notify(e Event) {
+	s.Lock()
+	defer s.Unlock()
+	k := e.RunRef.key()
+	if e.Tag == TagEndFlow {
+		s.flushRun(k)
+	}
+	if !isTerminal(e) || s.detail[e.RunRef.FlowRef.ID] {
+		s.inner.Notify(e)
+		return
+	}
+	nodes := s.held[k]
+	if nodes == nil {
+		nodes = map[config.NodeRef]*heldEnd{}
+		s.held[k] = nodes
+	}
+	if h, ok := nodes[e.SourceNode]; ok {
+		h.timer.Stop()
+	}
+	h := &heldEnd{e: e}
+	nodes[e.SourceNode] = h
+	h.timer = s.clock.AfterFunc(s.window, func() {
+		s.Lock()
+		defer s.Unlock()
+		// a later event may have replaced this one
+		if nodes[e.SourceNode] != h {
+			return
+		}
+		s.release(k, e.SourceNode)
+	})
+}
+
+// Held returns the number of end events waiting to settle
+func (s *Stabilizer) Held() int {
+	s.Lock()
+	defer s.Unlock()
+	n := 0
+	for _, nodes := range s.held {
+		n += len(nodes)
+	}
+	return n
+}
+
+// flushRun sends every event held for the run, it must be called in the lock.
+func (s *Stabilizer) flushRun(k runKey) {
+	for n, h := range s.held[k] {
+		h.timer.Stop()
+		s.release(k, n)
+	}
+}
+
+// release sends the held event of node n in run k, it must be called in the lock.
+func (s *Stabilizer) release(k runKey, n config.NodeRef) {
+	nodes := s.held[k]
+	h, ok := nodes[n]
+	if !ok {
+		return
+	}
+	delete(nodes, n)
+	if len(nodes) == 0 {
+		delete(s.held, k)
+	}
+	s.inner.Notify(h.e)
+}
@@ -0,0 +1,62 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+)
+
+type tagLog []string
+
+func (l *tagLog) Notify(e Event) {
+	*l = append(*l, e.Tag)
+}
+
+func TestStabilizer(t *testing.T) {
+	clk := NewFakeClock()
+	got := &tagLog{}
+	s := NewStabilizer(got, time.Second, clk)
+
+	ref := testRef(1)
+	node := config.NodeRef{Class: "task", ID: "build"}
+	end := func(tag string) Event {
+		return Event{RunRef: ref, SourceNode: node, ExecID: 1, Tag: tag, Outcome: tag[strings.LastIndex(tag, ".")+1:]}
+	}
+
+	// a flapping node settles on its final state
+	s.Notify(Event{RunRef: ref, SourceNode: node, Tag: "task.build.update"})
+	s.Notify(end("task.build.good"))
+	clk.Advance(500 * time.Millisecond)
+	s.Notify(end("task.build.error"))
+	clk.Advance(500 * time.Millisecond)
+	s.Notify(end("task.build.good"))
+	if len(*got) != 1 || s.Held() != 1 {
+		t.Fatal("end events should be held while flapping", *got)
+	}
+	clk.Advance(time.Second)
+	if len(*got) != 2 || (*got)[1] != "task.build.good" || s.Held() != 0 {
+		t.Fatal("only the settled state should be sent", *got)
+	}
+
+	// the end of the flow sends held events first
+	s.Notify(end("task.build.bad"))
+	s.Notify(Event{RunRef: ref, Tag: TagEndFlow})
+	if len(*got) != 4 || (*got)[2] != "task.build.bad" || (*got)[3] != TagEndFlow {
+		t.Fatal("end of flow should flush the held event", *got)
+	}
+	clk.Advance(time.Second)
+	if len(*got) != 4 {
+		t.Fatal("flushed event sent twice", *got)
+	}
+
+	// flows wanting detail see every end event
+	*got = nil
+	s.Detail(ref.FlowRef.ID)
+	s.Notify(end("task.build.good"))
+	s.Notify(end("task.build.error"))
+	if len(*got) != 2 || s.Held() != 0 {
+		t.Fatal("detailed flow should not be coalesced", *got)
+	}
+}
@@ -0,0 +1,75 @@
+package event
+
+import (
+	"time"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// TagRunStale is published for a run that has not published any events for the
+// stale timeout, which probably means the host executing it has gone.
+const TagRunStale = "sys.run.stale"
+
+// staleWatch is the idle timer for a run
+type staleWatch struct {
+	timer Timer
+	gen   int // to ignore a timer that fired as it was being reset
+}
+
+// WithStaleTimeout publishes a TagRunStale event for any adopted run that does not
+// publish an event for d. Zero disables stale detection.
+func WithStaleTimeout(d time.Duration) QueueOption {
+	return func(q *Queue) {
+		q.staleAfter = d
+	}
+}
+
+// watch resets the idle timer for the run of e.
+// watch must be called in the lock.
+func (q *Queue) watch(e Event) {
+	// the summary follows the end of the run so must not start a new watch
+	if q.staleAfter <= 0 || !e.RunRef.Adopted() || e.Tag == TagRunStale || e.Tag == TagRunSummary {
+		return
+	}
+	k := e.RunRef.key()
+	w, ok := q.stale[k]
+	if ok {
+		w.timer.Stop()
+	} else {
+		if q.stale == nil {
+			q.stale = map[runKey]*staleWatch{}
+		}
+		w = &staleWatch{}
+		q.stale[k] = w
+	}
+	if e.Tag == TagEndFlow {
+		delete(q.stale, k)
+		return
+	}
+	w.gen++
+	gen := w.gen
+	ref := e.RunRef
+	last := e.Time
+	w.timer = q.clock.AfterFunc(q.staleAfter, func() {
+		q.Lock()
+		cur, ok := q.stale[k]
+		if !ok || cur.gen != gen {
+			q.Unlock()
+			return
+		}
+		delete(q.stale, k)
+		idle := q.staleAfter
+		logger := q.logger
+		q.Unlock()
+
+		logger.Errorf("<%s> - run stale, no events for %s", ref, idle)
+		q.Publish(Event{
+			RunRef: ref,
+			Tag:    TagRunStale,
+			Opts: nt.Opts{
+				"idle":       idle.Seconds(),
+				"last_event": last,
+			},
+		})
+	})
+}
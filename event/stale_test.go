@@ -0,0 +1,46 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStaleRun(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk), WithStaleTimeout(time.Minute))
+	c := make(chanObs, 10)
+	q.Subscribe(c, TagRunStale)
+
+	stale, ended := testRef(1), testRef(2)
+	q.Publish(Event{RunRef: stale, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: ended, Tag: "trigger.good"})
+	q.Publish(Event{Tag: "inbound.push"}) // not adopted so never stale
+
+	// events keep the run alive
+	clk.Advance(50 * time.Second)
+	q.Publish(Event{RunRef: stale, Tag: "task.build.good"})
+	q.Publish(Event{RunRef: ended, Tag: TagEndFlow})
+	clk.Advance(50 * time.Second)
+	select {
+	case e := <-c:
+		t.Fatal("run should not be stale yet", e.RunRef)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clk.Advance(10 * time.Second)
+	e := next(t, c)
+	if !e.RunRef.Equal(stale) {
+		t.Error("wrong run went stale", e.RunRef)
+	}
+	if e.Opts["idle"] != 60.0 {
+		t.Error("idle time wrong", e.Opts["idle"])
+	}
+
+	// only the one notice and never for the ended run
+	clk.Advance(10 * time.Minute)
+	select {
+	case e := <-c:
+		t.Fatal("unexpected stale event", e.RunRef)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
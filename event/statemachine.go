@@ -0,0 +1,98 @@
+package event
+
+import "sync"
+
+// Transition moves a run from state From to state To when an event matching Match is
+// published for the run, calling Action if it is not nil.
+type Transition struct {
+	From   string
+	Match  func(Event) bool
+	To     string
+	Action func(Event)
+}
+
+// StateMachine tracks the state of each adopted run, driven by the events published for
+// the run. Every run starts in the initial state.
+type StateMachine struct {
+	sync.Mutex
+	initial     string
+	transitions []Transition
+	states      map[runKey]string
+}
+
+// NewStateMachine returns a StateMachine where runs start in the initial state
+func NewStateMachine(initial string) *StateMachine {
+	return &StateMachine{
+		initial: initial,
+		states:  map[runKey]string{},
+	}
+}
+
+// MatchTag returns a Transition Match function matching events with the tag
+func MatchTag(tag string) func(Event) bool {
+	return func(e Event) bool {
+		return e.Tag == tag
+	}
+}
+
+// On adds the transition from state from to state to for events matching match.
+// Transitions are tried in the order they were added and the first to match is used.
+func (s *StateMachine) On(from string, match func(Event) bool, to string, action func(Event)) {
+	s.Lock()
+	defer s.Unlock()
+	s.transitions = append(s.transitions, Transition{
+		From:   from,
+		Match:  match,
+		To:     to,
+		Action: action,
+	})
+}
+
+// Drive registers the state machine on q, to be sent events serially in publish order
+func (s *StateMachine) Drive(q *Queue) error {
+	return q.RegisterWith(s, WithMaxConcurrent(1))
+}
+
+// State returns the current state of the run
+func (s *StateMachine) State(ref RunRef) string {
+	s.Lock()
+	defer s.Unlock()
+	st, ok := s.states[ref.key()]
+	if !ok {
+		return s.initial
+	}
+	return st
+}
+
+// Forget drops the state of the run, which will be reported in the initial state again
+func (s *StateMachine) Forget(ref RunRef) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.states, ref.key())
+}
+
+// Notify applies the first matching transition for the run of e
+func (s *StateMachine) Notify(e Event) {
+	if !e.RunRef.Adopted() {
+		return
+	}
+	s.Lock()
+	k := e.RunRef.key()
+	st, ok := s.states[k]
+	if !ok {
+		st = s.initial
+	}
+	var action func(Event)
+	for _, t := range s.transitions {
+		if t.From == st && t.Match(e) {
+			s.states[k] = t.To
+			action = t.Action
+			break
+		}
+	}
+	s.Unlock()
+
+	if action != nil {
+		action(e)
+	}
+}
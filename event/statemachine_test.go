@@ -0,0 +1,43 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStateMachine(t *testing.T) {
+	q := NewQueue()
+	done := make(chan RunRef, 2)
+	sm := NewStateMachine("idle")
+	sm.On("idle", MatchTag("trigger.good"), "running", nil)
+	sm.On("running", MatchTag(TagEndFlow), "done", func(e Event) {
+		done <- e.RunRef
+	})
+	sm.Drive(q)
+
+	a, b := testRef(1), testRef(2)
+	q.Publish(Event{RunRef: a, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: b, Tag: TagEndFlow}) // no transition from idle
+	q.Publish(Event{RunRef: a, Tag: "task.build.good"})
+	q.Publish(Event{RunRef: a, Tag: TagEndFlow})
+
+	select {
+	case ref := <-done:
+		if !ref.Equal(a) {
+			t.Error("wrong run done", ref)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the done action")
+	}
+
+	if s := sm.State(a); s != "done" {
+		t.Error("run a in wrong state", s)
+	}
+	if s := sm.State(b); s != "idle" {
+		t.Error("run b in wrong state", s)
+	}
+	sm.Forget(a)
+	if s := sm.State(a); s != "idle" {
+		t.Error("forgotten run should be back to the initial state", s)
+	}
+}
@@ -0,0 +1,96 @@
+package event
+
+import "github.com/floeit/floe/config"
+
+// WithStrict makes the queue drop events that are inconsistent with the earlier events of
+// their run, rather than just logging them. An event is inconsistent if its FlowRef does
+// not match the FlowRef of the run it claims to be part of, if it is a second end
+// event for the same node execution, or if its tag has invalid characters.
+func WithStrict() QueueOption {
+	return func(q *Queue) {
+		q.strict = true
+	}
+}
+
+// validTag returns false if e should be rejected because of its tag.
+// validTag must be called in the lock.
+func (q *Queue) validTag(e Event) bool {
+	if ValidTag(e.Tag) {
+		return true
+	}
+	q.logger.Errorf("<%s> - event tag %q has invalid characters", e.RunRef, e.Tag)
+	return !q.strict
+}
+
+// consistent returns false if e should be rejected because it is inconsistent with its
+// run. consistent must be called in the lock.
+func (q *Queue) consistent(e Event) bool {
+	if !e.RunRef.Adopted() {
+		return true
+	}
+	run := e.RunRef.Run
+	flow, ok := q.runFlows[run]
+	if !ok {
+		// the end and the summary after it must not start tracking the run again
+		if e.Tag == TagEndFlow || e.Tag == TagRunSummary {
+			return true
+		}
+		if q.runFlows == nil {
+			q.runFlows = map[HostedIDRef]config.FlowRef{}
+		}
+		q.runFlows[run] = e.RunRef.FlowRef
+		return true
+	}
+	if !flow.Equal(e.RunRef.FlowRef) {
+		q.logger.Errorf("<%s> - event %s inconsistent, run %s belongs to flow %s", e.RunRef, e.Tag, run, flow)
+		return !q.strict
+	}
+	if e.Tag == TagEndFlow {
+		delete(q.runFlows, run)
+	}
+	return true
+}
+
+// execKey identifies a single execution of a node
+type execKey struct {
+	node config.NodeRef
+	exec int64
+}
+
+// isTerminal returns true if e is the end event of a node execution, which is marked by
+// its Outcome as its tag may be anything the outcome tag template of the node gives
+func isTerminal(e Event) bool {
+	return e.Outcome != "" && e.ExecID != 0 && !e.IsSystem()
+}
+
+// endsOnce returns false if e is a second end event for a node execution, a node
+// execution ends once, either good, bad or in error. endsOnce must be called in the lock.
+func (q *Queue) endsOnce(e Event) bool {
+	if !e.RunRef.Adopted() {
+		return true
+	}
+	k := e.RunRef.key()
+	if e.Tag == TagEndFlow {
+		delete(q.ended, k)
+		return true
+	}
+	if !isTerminal(e) {
+		return true
+	}
+	if q.ended == nil {
+		q.ended = map[runKey]map[execKey]string{}
+	}
+	execs, ok := q.ended[k]
+	if !ok {
+		execs = map[execKey]string{}
+		q.ended[k] = execs
+	}
+	ek := execKey{node: e.SourceNode, exec: e.ExecID}
+	if prev, ok := execs[ek]; ok {
+		q.logger.Errorf("<%s> - event %s for exec %d of node %s which already ended with %s",
+			e.RunRef, e.Tag, e.ExecID, e.SourceNode, prev)
+		return !q.strict
+	}
+	execs[ek] = e.Tag
+	return true
+}
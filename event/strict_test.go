@@ -0,0 +1,101 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestStrictFlowRef(t *testing.T) {
+	for _, strict := range []bool{true, false} {
+		var opts []QueueOption
+		if strict {
+			opts = append(opts, WithStrict())
+		}
+		q := NewQueue(opts...)
+		r := &recorder{}
+		q.Register(r)
+
+		ref := testRef(1)
+		bad := ref
+		bad.FlowRef = config.FlowRef{ID: "deploy", Ver: 1}
+
+		r.publish(q, Event{RunRef: ref, Tag: "trigger.good"})
+		if strict {
+			q.Publish(Event{RunRef: bad, Tag: "task.build.good"})
+		} else {
+			r.publish(q, Event{RunRef: bad, Tag: "task.build.good"})
+		}
+		r.publish(q, Event{RunRef: ref, Tag: "task.test.good"})
+
+		r.Lock()
+		n := len(r.events)
+		r.Unlock()
+		if strict && n != 2 {
+			t.Error("strict queue should reject the inconsistent event", n)
+		}
+		if !strict && n != 3 {
+			t.Error("non strict queue should only log the inconsistent event", n)
+		}
+
+		// the run ID can be reused once the run is over
+		r.wg.Add(1) // the summary
+		r.publish(q, Event{RunRef: ref, Tag: TagEndFlow})
+		r.publish(q, Event{RunRef: bad, Tag: "trigger.good"})
+	}
+}
+
+func TestNodeEndsOnce(t *testing.T) {
+	q := NewQueue(WithStrict())
+	r := &recorder{}
+	q.Register(r)
+	ref := testRef(1)
+	node := config.NodeRef{Class: "task", ID: "build"}
+
+	good := Event{RunRef: ref, SourceNode: node, ExecID: 1}
+	good.SetGood()
+	r.publish(q, good)
+	// something then reports an error for the same execution
+	q.Publish(Event{RunRef: ref, SourceNode: node, ExecID: 1, Tag: "task.build.error", Outcome: config.SubTagError})
+	// a retry is a new execution so can end
+	r.publish(q, Event{RunRef: ref, SourceNode: node, ExecID: 2, Tag: "task.build.bad", Outcome: config.SubTagBad})
+	// and non terminal events are fine
+	r.publish(q, UpdateEvent(ref, node, StreamStdout, 1, "more"))
+
+	r.Lock()
+	defer r.Unlock()
+	if len(r.events) != 3 {
+		t.Fatal("second end of the execution should be rejected", len(r.events))
+	}
+	for _, e := range r.events {
+		if e.Tag == "task.build.error" {
+			t.Error("rejected event delivered")
+		}
+	}
+}
+
+func TestNodeEndsOnceTemplated(t *testing.T) {
+	q := NewQueue(WithStrict())
+	r := &recorder{}
+	q.Register(r)
+	ref := testRef(1)
+	node := config.NodeRef{Class: "task", ID: "deploy"}
+	tmpl := "{{.outcome}}.deploy.prod"
+
+	good := Event{RunRef: ref, SourceNode: node, ExecID: 1}
+	good.SetGood(tmpl)
+	r.publish(q, good)
+	// the templated tag does not end in the outcome, but is still the end of the execution
+	failed := Event{RunRef: ref, SourceNode: node, ExecID: 1}
+	failed.SetError(tmpl)
+	q.Publish(failed)
+	if failed.Tag != "error.deploy.prod" || failed.Severity() != LevelError {
+		t.Error("templated error event wrong", failed.Tag, failed.Severity())
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if len(r.events) != 1 || r.events[0].Tag != "good.deploy.prod" {
+		t.Error("second end of the templated execution should be rejected", r.events)
+	}
+}
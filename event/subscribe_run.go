@@ -0,0 +1,108 @@
+package event
+
+import (
+	"errors"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+var errZeroRun = errors.New("can not subscribe to a zero run ref")
+
+// TagRunSnapshot is sent to a run subscriber first when some of the events it asked to
+// resume from are no longer in the history, the events that follow are all those retained.
+const TagRunSnapshot = "sys.run.snapshot"
+
+// SubscribeRun registers o for all events in the run ref. Any events in the run history
+// with an ID greater than fromID are sent first, so a reconnecting client can pass the
+// last ID it received to resume the stream. A fromID of 0 replays the whole history.
+// If events after fromID have been evicted from the history a TagRunSnapshot event
+// precedes the retained events. The registration is removed once the run has ended,
+// after o is sent the end event and the run summary, so a run that has already ended is
+// only replayed.
+func (q *Queue) SubscribeRun(ref RunRef, fromID int64, o Observer, opts ...RegisterOption) error {
+	if ref.IsZero() {
+		return errZeroRun
+	}
+	r := &registration{
+		o:         o,
+		ready:     make(chan struct{}),
+		runScoped: true,
+		run:       &ref,
+	}
+	withNamedFilter("run", func(e Event) bool {
+		return e.RunRef.Equal(ref)
+	})(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	q.Lock()
+	var replay []Event
+	if h, ok := q.history[ref.key()]; ok {
+		if h.evicted > fromID {
+			replay = append(replay, Event{
+				RunRef: h.ref,
+				Tag:    TagRunSnapshot,
+				Opts: nt.Opts{
+					"from":    fromID,
+					"evicted": h.evicted,
+				},
+			})
+		}
+		for _, e := range h.events {
+			if e.ID > fromID {
+				replay = append(replay, e.copy())
+			}
+		}
+	}
+	// a run that has already ended is only replayed
+	if h, ok := q.history[ref.key()]; !ok || !h.ended {
+		if err := q.add(r); err != nil {
+			q.Unlock()
+			return err
+		}
+	}
+	q.Unlock()
+
+	// send the replay before any live events
+	go func() {
+		for _, e := range replay {
+			if r.accepts(e) {
+				o.Notify(e)
+			}
+		}
+		close(r.ready)
+	}()
+	return nil
+}
+
+// endRunSubscriptions removes the SubscribeRun registrations of the run of e if e is the
+// last event of the run, the end event when there will be no summary, else the summary.
+// endRunSubscriptions must be called in the lock.
+func (q *Queue) endRunSubscriptions(e Event, summarised bool) {
+	if !(e.Tag == TagEndFlow && !summarised) && e.Tag != TagRunSummary {
+		return
+	}
+	if !e.RunRef.Adopted() {
+		return
+	}
+	// a new slice as publishers may be ranging over the current one
+	var obs []*registration
+	for _, r := range q.observers {
+		if r.run != nil && r.run.Equal(e.RunRef) {
+			continue
+		}
+		obs = append(obs, r)
+	}
+	q.observers = obs
+}
+
+// WithExecHost pins the observer to the events published by the executor host, dropping
+// those from any other host, such as an executor a run has migrated away from that is
+// still publishing. Pass the ExecHost of the RunRef given to SubscribeRun to follow the run
+// only on its current executor. Events with no ExecHost are still delivered.
+func WithExecHost(host string) RegisterOption {
+	return withNamedFilter("exec host", func(e Event) bool {
+		return e.RunRef.ExecHost == "" || e.RunRef.ExecHost == host
+	})
+}
@@ -0,0 +1,140 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+// chanObs sends every event to a channel
+type chanObs chan Event
+
+func (c chanObs) Notify(e Event) {
+	c <- e
+}
+
+func next(t *testing.T, c chanObs) Event {
+	select {
+	case e := <-c:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestSubscribeRunResume(t *testing.T) {
+	q := NewQueue()
+	ref, other := testRef(1), testRef(2)
+	for i := 0; i < 5; i++ {
+		q.Publish(Event{RunRef: ref, Tag: "sys.node.update"})
+		q.Publish(Event{RunRef: other, Tag: "sys.node.update"})
+	}
+	// the client had received up to the 3rd event of the run (ID 5)
+	c := make(chanObs, 10)
+	q.SubscribeRun(ref, 5, c)
+	q.Publish(Event{RunRef: ref, Tag: "task.build.good"})
+	q.Publish(Event{RunRef: other, Tag: "task.build.good"})
+
+	for _, id := range []int64{7, 9, 11} {
+		e := next(t, c)
+		if e.ID != id {
+			t.Errorf("expected event %d got %d", id, e.ID)
+		}
+		if !e.RunRef.Equal(ref) {
+			t.Error("got an event from the wrong run", e.RunRef)
+		}
+	}
+	select {
+	case e := <-c:
+		t.Error("unexpected event", e.ID)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestSubscribeRunEvicted(t *testing.T) {
+	q := NewQueue(WithRunHistory(2, 10))
+	ref := testRef(1)
+	for i := 0; i < 5; i++ {
+		q.Publish(Event{RunRef: ref, Tag: "sys.node.update"})
+	}
+	c := make(chanObs, 10)
+	q.SubscribeRun(ref, 1, c)
+
+	e := next(t, c)
+	if e.Tag != TagRunSnapshot {
+		t.Fatal("should have had the snapshot marker first", e.Tag)
+	}
+	if e.Opts["evicted"] != int64(3) {
+		t.Error("bad evicted marker", e.Opts)
+	}
+	for _, id := range []int64{4, 5} {
+		if e := next(t, c); e.ID != id {
+			t.Errorf("expected retained event %d got %d", id, e.ID)
+		}
+	}
+}
+
+func TestSubscribeRunExecHost(t *testing.T) {
+	q := NewQueue()
+	old := testRef(1)
+	moved := old
+	moved.ExecHost = "h2"
+
+	c := make(chanObs, 10)
+	q.SubscribeRun(moved, 0, c, WithExecHost(moved.ExecHost), WithMaxConcurrent(1))
+	q.Publish(Event{RunRef: old, Tag: "task.stale.good"})
+	q.Publish(Event{RunRef: moved, Tag: "task.build.good"})
+	q.Publish(Event{RunRef: old, Tag: "task.stale.bad"})
+	q.Publish(Event{RunRef: moved, Tag: "task.test.good"})
+
+	for _, tag := range []string{"task.build.good", "task.test.good"} {
+		e := next(t, c)
+		if e.Tag != tag || e.RunRef.ExecHost != "h2" {
+			t.Errorf("got %s from %s wanted %s", e.Tag, e.RunRef.ExecHost, tag)
+		}
+	}
+	select {
+	case e := <-c:
+		t.Error("event from the old executor delivered", e.Tag)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestSubscribeRunRemovedAtEnd(t *testing.T) {
+	q := NewQueue()
+	ref := testRef(1)
+	c := make(chanObs, 10)
+	if err := q.SubscribeRun(ref, 0, c); err != nil {
+		t.Fatal(err)
+	}
+	q.Publish(Event{RunRef: ref, Tag: "trigger.good"})
+	q.Publish(Event{RunRef: ref, Tag: TagEndFlow})
+
+	got := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		got[next(t, c).Tag] = true
+	}
+	if !got[TagEndFlow] || !got[TagRunSummary] {
+		t.Error("the subscriber should be sent the end and summary", got)
+	}
+	q.RLock()
+	n := len(q.observers)
+	q.RUnlock()
+	if n != 0 {
+		t.Error("the run subscription should be removed once the run has ended", n)
+	}
+
+	// an ended run is only replayed
+	if err := q.SubscribeRun(ref, 0, c); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		next(t, c)
+	}
+	q.RLock()
+	n = len(q.observers)
+	q.RUnlock()
+	if n != 0 {
+		t.Error("subscribing to an ended run should not register", n)
+	}
+}
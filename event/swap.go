@@ -0,0 +1,35 @@
+package event
+
+// WithPersistent marks the observer as a system observer, such as the hub, that
+// SwapObservers leaves registered
+func WithPersistent() RegisterOption {
+	return func(r *registration) {
+		r.persistent = true
+	}
+}
+
+// SwapObservers replaces the registered observers with observers, each registered with
+// opts, in one step so every event is either sent to the old set of observers or the new
+// set, never a mix. It is for replacing the observers derived from config on reload.
+// Persistent observers, groups and observers of a single run are kept. If any of the
+// new observers can not be registered none are and the old observers are kept.
+func (q *Queue) SwapObservers(observers []Observer, opts ...RegisterOption) error {
+	q.Lock()
+	defer q.Unlock()
+	old := q.observers
+	var kept []*registration
+	for _, r := range old {
+		_, isGroup := r.o.(*group)
+		if r.persistent || r.runScoped || isGroup {
+			kept = append(kept, r)
+		}
+	}
+	q.observers = kept
+	for _, o := range observers {
+		if err := q.add(newRegistration(o, opts)); err != nil {
+			q.observers = old
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package event
+
+import (
+	"sync"
+	"testing"
+)
+
+// idSet records the ids of the events it is sent
+type idSet struct {
+	mu  *sync.Mutex
+	wg  *sync.WaitGroup
+	ids map[int64]bool
+}
+
+func (s *idSet) Notify(e Event) {
+	s.mu.Lock()
+	s.ids[e.ID] = true
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+func TestSwapObservers(t *testing.T) {
+	const events = 200
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	newSet := func() *idSet {
+		return &idSet{mu: &mu, wg: &wg, ids: map[int64]bool{}}
+	}
+
+	q := NewQueue()
+	sys := newSet()
+	q.RegisterWith(sys, WithPersistent())
+	old := []Observer{newSet(), newSet()}
+	for _, o := range old {
+		q.Register(o)
+	}
+	swapped := []Observer{newSet(), newSet()}
+
+	// every event is sent to the system observer and to one pair
+	wg.Add(events * 3)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < events; i++ {
+			q.Publish(Event{Tag: "tag"})
+		}
+		close(done)
+	}()
+	if err := q.SwapObservers(swapped); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	wg.Wait()
+
+	mu.Lock()
+	if len(sys.ids) != events {
+		t.Error("persistent observer should be kept", len(sys.ids))
+	}
+	for id := int64(1); id <= events; id++ {
+		o0, o1 := old[0].(*idSet).ids[id], old[1].(*idSet).ids[id]
+		n0, n1 := swapped[0].(*idSet).ids[id], swapped[1].(*idSet).ids[id]
+		if (o0 && o1 && !n0 && !n1) || (!o0 && !o1 && n0 && n1) {
+			continue
+		}
+		t.Errorf("event %d sent to a mix of old and new observers", id)
+	}
+	mu.Unlock()
+
+	// a failed swap keeps the current observers
+	if err := q.SwapObservers([]Observer{swapped[0], swapped[0]}); err != ErrAlreadyRegistered {
+		t.Error("expected duplicate registration error", err)
+	}
+	wg.Add(3)
+	q.Publish(Event{Tag: "tag"})
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if !swapped[0].(*idSet).ids[events+1] || !swapped[1].(*idSet).ids[events+1] {
+		t.Error("failed swap should keep the observers")
+	}
+}
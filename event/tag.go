@@ -0,0 +1,22 @@
+package event
+
+import "strings"
+
+// NormalizeTag returns the canonical lower case form of tag, tags are built from node
+// classes and IDs that may have been typed in any case.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(tag)
+}
+
+// ValidTag returns true if tag only has letters, digits, dots, dashes and underscores, so
+// any tag built from the IDs of config nodes is valid
+func ValidTag(tag string) bool {
+	for _, c := range tag {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
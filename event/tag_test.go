@@ -0,0 +1,75 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestValidTag(t *testing.T) {
+	for _, tag := range []string{"task.build.good", "inbound.git-push", "sys.end.all", "Task.Build2.good", "task.build_all.good"} {
+		if !ValidTag(tag) {
+			t.Errorf("%q should be valid", tag)
+		}
+	}
+	for _, tag := range []string{"task build.good", "task.build.good!", "task/build", "tâche.good"} {
+		if ValidTag(tag) {
+			t.Errorf("%q should be invalid", tag)
+		}
+	}
+}
+
+func TestSetTagsNormalized(t *testing.T) {
+	e := Event{SourceNode: config.NodeRef{Class: "Task", ID: "Build"}}
+	e.SetGood()
+	if e.Tag != "task.build.good" || !e.Good {
+		t.Error("bad good tag", e.Tag)
+	}
+	e.SetError()
+	if e.Tag != "task.build.error" || e.Good {
+		t.Error("bad error tag", e.Tag)
+	}
+}
+
+func TestSetTagsOutcomeTemplate(t *testing.T) {
+	e := Event{SourceNode: config.NodeRef{Class: "task", ID: "deploy"}}
+	tmpl := `{{.id}}.prod.{{if eq .outcome "good"}}Success{{else}}{{.outcome}}{{end}}`
+	e.SetGood(tmpl)
+	if e.Tag != "deploy.prod.success" || !e.Good {
+		t.Error("bad templated good tag", e.Tag)
+	}
+	e.SetError(tmpl)
+	if e.Tag != "deploy.prod.error" || e.Good {
+		t.Error("bad templated error tag", e.Tag)
+	}
+	// a broken template falls back to the default
+	e.SetGood("{{.nope")
+	if e.Tag != "task.deploy.good" {
+		t.Error("bad fallback tag", e.Tag)
+	}
+}
+
+func TestCaseInsensitiveRouting(t *testing.T) {
+	q := NewQueue()
+	c := make(chanObs, 10)
+	q.Subscribe(c, "Task.Build.good")
+	q.Publish(Event{Tag: "task.BUILD.good"})
+	if e := next(t, c); e.Tag != "task.build.good" {
+		t.Error("published tags should be lower cased", e.Tag)
+	}
+}
+
+func TestInvalidTagRejected(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		var opts []QueueOption
+		if strict {
+			opts = append(opts, WithStrict())
+		}
+		q := NewQueue(opts...)
+		q.Publish(Event{Tag: "task.my build.good"})
+		got := len(q.Tail(10)) == 1
+		if got == strict {
+			t.Errorf("strict %v: invalid tag published %v", strict, got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package event
+
+const defaultTailSize = 256 // how many of the most recent events to retain for debugging
+
+// ring is a fixed size circular buffer of the most recent events
+type ring struct {
+	events []Event
+	next   int  // index the next event will be written to
+	full   bool // true once the buffer has wrapped
+}
+
+func newRing(size int) *ring {
+	return &ring{
+		events: make([]Event, size),
+	}
+}
+
+func (r *ring) add(e Event) {
+	if len(r.events) == 0 {
+		return
+	}
+	r.events[r.next] = e
+	r.next++
+	if r.next == len(r.events) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// last returns up to the n most recent events, oldest first
+func (r *ring) last(n int) []Event {
+	l := r.next
+	if r.full {
+		l = len(r.events)
+	}
+	if n > l {
+		n = l
+	}
+	if n <= 0 {
+		return nil
+	}
+	res := make([]Event, n)
+	start := r.next - n
+	if start < 0 {
+		start += len(r.events)
+	}
+	for i := 0; i < n; i++ {
+		res[i] = r.events[(start+i)%len(r.events)].copy()
+	}
+	return res
+}
@@ -0,0 +1,38 @@
+package event
+
+import "sync"
+
+// order hands out tickets as events are stamped so they are posted to the observers in
+// the same order, even when published concurrently
+type order struct {
+	sync.Mutex
+	cond   *sync.Cond
+	issued int64 // the last ticket taken
+	posted int64 // the last ticket whose event has been posted
+}
+
+// take returns the next ticket, it must be called in the queue lock
+func (o *order) take() int64 {
+	o.issued++
+	return o.issued
+}
+
+// wait blocks until the events of all the tickets before t have been posted
+func (o *order) wait(t int64) {
+	o.Lock()
+	defer o.Unlock()
+	if o.cond == nil {
+		o.cond = sync.NewCond(&o.Mutex)
+	}
+	for o.posted < t-1 {
+		o.cond.Wait()
+	}
+}
+
+// done marks the event of the ticket waited on as posted
+func (o *order) done() {
+	o.Lock()
+	defer o.Unlock()
+	o.posted++
+	o.cond.Broadcast()
+}
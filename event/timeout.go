@@ -0,0 +1,24 @@
+package event
+
+import (
+	"time"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// TagRunTimeout is published for a run that has not ended within its timeout, the hub
+// then fails the run
+const TagRunTimeout = "sys.run.timeout"
+
+// RunTimeout publishes a TagRunTimeout event for the run ref if it has not ended within
+// d, it should be called as the run starts. Like any delayed event it is canceled when the
+// run ends, or by canceling the returned handle.
+func (q *Queue) RunTimeout(ref RunRef, d time.Duration) *Delayed {
+	return q.PublishAfter(d, Event{
+		RunRef: ref,
+		Tag:    TagRunTimeout,
+		Opts: nt.Opts{
+			"timeout": d.String(),
+		},
+	})
+}
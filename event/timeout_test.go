@@ -0,0 +1,35 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunTimeout(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk))
+	got := make(chanObs, 10)
+	q.Subscribe(got, TagRunTimeout)
+
+	stuck, done := testRef(1), testRef(2)
+	for _, ref := range []RunRef{stuck, done} {
+		q.Publish(Event{RunRef: ref, Tag: "trigger.good", Good: true})
+		q.RunTimeout(ref, time.Minute)
+	}
+	clk.Advance(30 * time.Second)
+	q.Publish(Event{RunRef: done, Tag: TagEndFlow, Good: true})
+
+	clk.Advance(29 * time.Second)
+	if len(got) != 0 {
+		t.Fatal("timed out early")
+	}
+	clk.Advance(2 * time.Second)
+	e := next(t, got)
+	if !e.RunRef.Equal(stuck) || e.Opts["timeout"] != "1m0s" {
+		t.Error("bad timeout event", e)
+	}
+	clk.Advance(time.Hour)
+	if len(got) != 0 {
+		t.Error("completed run should not time out")
+	}
+}
@@ -0,0 +1,39 @@
+package event
+
+import "sync"
+
+// TransitionObserver forwards to its wrapped observer only those events where the Good
+// status of the run differs from the previously seen event for that run. The first
+// event seen for a run is always forwarded.
+type TransitionObserver struct {
+	mu    sync.Mutex
+	inner Observer
+	last  map[runKey]bool
+}
+
+// NewTransitionObserver wraps o so it only receives status transitions
+func NewTransitionObserver(o Observer) *TransitionObserver {
+	return &TransitionObserver{
+		inner: o,
+		last:  map[runKey]bool{},
+	}
+}
+
+// Notify satisfies Observer
+func (t *TransitionObserver) Notify(e Event) {
+	k := e.RunRef.key()
+	t.mu.Lock()
+	good, seen := t.last[k]
+	if e.Tag == TagEndFlow {
+		// nothing more will happen in this run
+		delete(t.last, k)
+	} else {
+		t.last[k] = e.Good
+	}
+	t.mu.Unlock()
+
+	if seen && good == e.Good {
+		return
+	}
+	t.inner.Notify(e)
+}
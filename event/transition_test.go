@@ -0,0 +1,47 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestTransitionObserver(t *testing.T) {
+	var got []Event
+	o := NewTransitionObserver(&listener{what: func(e Event) {
+		got = append(got, e)
+	}})
+
+	r1 := RunRef{FlowRef: config.FlowRef{ID: "f", Ver: 1}, Run: HostedIDRef{HostID: "h1", ID: 1}}
+	r2 := RunRef{FlowRef: config.FlowRef{ID: "f", Ver: 1}, Run: HostedIDRef{HostID: "h1", ID: 2}}
+
+	seq := []struct {
+		ref  RunRef
+		good bool
+		tag  string
+	}{
+		{r1, true, "trigger.good"},   // first for r1 - forwarded
+		{r1, true, "task.a.good"},    // same
+		{r2, false, "task.b.bad"},    // first for r2 - forwarded
+		{r1, true, "task.b.good"},    // same
+		{r1, false, "task.c.bad"},    // change - forwarded
+		{r1, false, "task.d.bad"},    // same
+		{r2, false, "task.e.bad"},    // same
+		{r1, true, "task.e.good"},    // change - forwarded
+		{r1, true, TagEndFlow},       // same
+		{r1, true, "task.late.good"}, // run forgotten at the end so treated as first
+	}
+	for i, s := range seq {
+		o.Notify(Event{RunRef: s.ref, Good: s.good, Tag: s.tag, ID: int64(i + 1)})
+	}
+
+	expected := []int64{1, 3, 5, 8, 10}
+	if len(got) != len(expected) {
+		t.Fatal("wrong number of transitions forwarded", len(got))
+	}
+	for i, id := range expected {
+		if got[i].ID != id {
+			t.Errorf("%d - expected event %d got %d", i, id, got[i].ID)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package event
+
+import (
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// InboundPrefix prefixes the tag of events pushed in to fire triggers, the rest of the
+// tag is the type of trigger to fire
+const InboundPrefix = "inbound"
+
+// NewTriggerEvent returns an unadopted event that fires the triggers of triggerType whose
+// options match the payload. If flow is given only the triggers of that flow are fired.
+// All sources of trigger events, such as webhooks, should use it so their events are the same.
+func NewTriggerEvent(flow config.FlowRef, triggerType string, payload nt.Opts) Event {
+	if payload == nil {
+		payload = nt.Opts{}
+	}
+	return Event{
+		RunRef: RunRef{
+			FlowRef: flow,
+		},
+		Tag:  InboundPrefix + "." + triggerType,
+		Opts: payload,
+	}
+}
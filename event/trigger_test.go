@@ -0,0 +1,30 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+func TestNewTriggerEvent(t *testing.T) {
+	flow := config.FlowRef{ID: "build", Ver: 1}
+	e := NewTriggerEvent(flow, "git-push", nt.Opts{"url": "git@github.com:floeit/floe.git"})
+	if e.RunRef.Adopted() {
+		t.Error("trigger events must not be adopted")
+	}
+	if !e.RunRef.FlowRef.Equal(flow) {
+		t.Error("wrong flow", e.RunRef.FlowRef)
+	}
+	if e.Tag != "inbound.git-push" {
+		t.Error("wrong tag", e.Tag)
+	}
+	if e.Opts["url"] != "git@github.com:floeit/floe.git" {
+		t.Error("payload missing", e.Opts)
+	}
+
+	e = NewTriggerEvent(config.FlowRef{}, "data", nil)
+	if e.Opts == nil {
+		t.Error("trigger events must always have opts to match against")
+	}
+}
@@ -0,0 +1,37 @@
+package event
+
+import (
+	"github.com/floeit/floe/config"
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// TagNodeUpdate is the tag of events carrying a line of output from an executing node
+const TagNodeUpdate = "sys.node.update"
+
+// TagNodeStart is the tag of the event published as a node starts executing
+const TagNodeStart = "sys.node.start"
+
+// output streams an update line can come from
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// UpdateEvent returns an update event for one line of output from node in the run ref.
+// Set ExecID on the returned event to tie it to a node execution.
+// stream is the output stream the line was written to and n is the line number.
+// The line is also set in the opts "update" key, for clients that only expect the line.
+func UpdateEvent(ref RunRef, node config.NodeRef, stream string, n int, line string) Event {
+	return Event{
+		RunRef:     ref,
+		SourceNode: node,
+		Tag:        TagNodeUpdate,
+		Opts: nt.Opts{
+			"stream": stream,
+			"line":   line,
+			"n":      n,
+			"update": line,
+		},
+		Good: true,
+	}
+}
@@ -0,0 +1,32 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestUpdateEvent(t *testing.T) {
+	ref := RunRef{
+		FlowRef: config.FlowRef{ID: "flow", Ver: 1},
+		Run:     HostedIDRef{HostID: "h1", ID: 3},
+	}
+	node := config.NodeRef{Class: "task", ID: "build"}
+	e := UpdateEvent(ref, node, StreamStderr, 7, "make: *** [all] Error 1")
+
+	if e.Tag != TagNodeUpdate || !e.IsSystem() {
+		t.Error("bad update tag", e.Tag)
+	}
+	if !e.RunRef.Equal(ref) || e.SourceNode != node {
+		t.Error("bad refs", e.RunRef, e.SourceNode)
+	}
+	if e.Opts["stream"] != StreamStderr {
+		t.Error("bad stream", e.Opts["stream"])
+	}
+	if e.Opts["n"] != 7 {
+		t.Error("bad line number", e.Opts["n"])
+	}
+	if e.Opts["line"] != "make: *** [all] Error 1" || e.Opts["update"] != e.Opts["line"] {
+		t.Error("bad line", e.Opts["line"], e.Opts["update"])
+	}
+}
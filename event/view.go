@@ -0,0 +1,115 @@
+package event
+
+import (
+	"time"
+
+	"github.com/floeit/floe/config"
+)
+
+// The statuses of runs and nodes in a RunView
+const (
+	StatusRunning = "running"
+	StatusGood    = "good"
+	StatusBad     = "bad"
+	StatusError   = "error" // the node could not be executed
+)
+
+// RunView is the state of a run as folded from its events by BuildRunView
+type RunView struct {
+	Ref     RunRef
+	Status  string
+	Started time.Time
+	Ended   time.Time // zero while running
+	Events  int
+	Nodes   []NodeView // in the order they were first seen
+}
+
+// NodeView is the state of a node in a RunView, for a node executed more than once it
+// is that of the latest attempt apart from the output lines which are of all of them
+type NodeView struct {
+	Node     config.NodeRef
+	Status   string
+	Started  time.Time
+	Ended    time.Time // zero while running
+	Lines    int       // lines of output
+	Attempts int       // how many times the node has executed
+	Tag      string    // the tag the node last ended with
+}
+
+// Duration returns how long the node took, or has taken by now if it is running
+func (n NodeView) Duration(now time.Time) time.Duration {
+	if n.Ended.IsZero() {
+		return now.Sub(n.Started)
+	}
+	return n.Ended.Sub(n.Started)
+}
+
+// BuildRunView folds the events of a run, such as those from History in ID order, into
+// the status and timings of the run and each of its nodes, so that the web UI and API
+// show the same view.
+func BuildRunView(events []Event) RunView {
+	v := RunView{Status: StatusRunning}
+	idx := map[config.NodeRef]int{}
+	node := func(ref config.NodeRef) *NodeView {
+		i, ok := idx[ref]
+		if !ok {
+			i = len(v.Nodes)
+			idx[ref] = i
+			v.Nodes = append(v.Nodes, NodeView{Node: ref})
+		}
+		return &v.Nodes[i]
+	}
+	for _, e := range events {
+		if e.Tag == TagRunSummary {
+			continue
+		}
+		v.Events++
+		if v.Ref.IsZero() {
+			v.Ref = e.RunRef
+		}
+		if v.Started.IsZero() || e.Time.Before(v.Started) {
+			v.Started = e.Time
+		}
+		switch {
+		case e.Tag == TagEndFlow:
+			v.Ended = e.Time
+			v.Status = StatusBad
+			if e.Good {
+				v.Status = StatusGood
+			}
+			continue
+		case e.SourceNode.ID == "":
+			continue
+		}
+		n := node(e.SourceNode)
+		switch {
+		case e.Tag == TagNodeStart:
+			n.Status = StatusRunning
+			n.Started, n.Ended = e.Time, time.Time{}
+			n.Attempts++
+			if e.Attempt > n.Attempts {
+				n.Attempts = e.Attempt
+			}
+		case e.Tag == TagNodeUpdate:
+			n.Lines++
+		case e.IsSystem():
+			// other system events such as waiting for data do not end the node
+		default:
+			// the event the node ended with, or fired with if it does not execute
+			if n.Started.IsZero() {
+				n.Started = e.Time
+			}
+			n.Ended = e.Time
+			n.Tag = e.Tag
+			switch {
+			case e.Good:
+				n.Status = StatusGood
+			case e.Outcome == config.SubTagError:
+				n.Status = StatusError
+			default:
+				n.Status = StatusBad
+			}
+		}
+	}
+	return v
+}
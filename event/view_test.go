@@ -0,0 +1,71 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestBuildRunView(t *testing.T) {
+	ref := testRef(1)
+	start := time.Date(2017, 11, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	trig := config.NodeRef{Class: "trigger", ID: "push"}
+	build := config.NodeRef{Class: "task", ID: "build"}
+	test := config.NodeRef{Class: "task", ID: "test"}
+	deploy := config.NodeRef{Class: "task", ID: "deploy"}
+
+	events := []Event{
+		{SourceNode: trig, Tag: "trigger.good", Good: true, Time: at(0)},
+		{SourceNode: build, Tag: TagNodeStart, Time: at(1), Attempt: 1},
+		{SourceNode: build, Tag: TagNodeUpdate, Time: at(2)},
+		{SourceNode: build, Tag: TagNodeUpdate, Time: at(3)},
+		{SourceNode: build, Tag: "task.build.good", Good: true, Time: at(5), Attempt: 1},
+		{SourceNode: test, Tag: TagNodeStart, Time: at(6), Attempt: 1},
+		{SourceNode: test, Tag: TagNodeUpdate, Time: at(7)},
+		{SourceNode: test, Tag: "task.test.bad", Time: at(8), Attempt: 1},
+		{SourceNode: test, Tag: TagNodeStart, Time: at(9), Attempt: 2},
+		{SourceNode: test, Tag: TagNodeUpdate, Time: at(10)},
+		{SourceNode: test, Tag: "task.test.good", Good: true, Time: at(12), Attempt: 2},
+		{SourceNode: deploy, Tag: TagNodeStart, Time: at(13), Attempt: 1},
+		{SourceNode: deploy, Tag: "task.deploy.error", Time: at(14), Attempt: 1, Outcome: config.SubTagError},
+		{Tag: TagEndFlow, Time: at(15)},
+		{Tag: TagRunSummary, Time: at(15)},
+	}
+	for i := range events {
+		events[i].RunRef = ref
+		events[i].ID = int64(i + 1)
+	}
+
+	want := RunView{
+		Ref:     ref,
+		Status:  StatusBad,
+		Started: at(0),
+		Ended:   at(15),
+		Events:  14,
+		Nodes: []NodeView{
+			{Node: trig, Status: StatusGood, Started: at(0), Ended: at(0), Tag: "trigger.good"},
+			{Node: build, Status: StatusGood, Started: at(1), Ended: at(5), Lines: 2, Attempts: 1, Tag: "task.build.good"},
+			{Node: test, Status: StatusGood, Started: at(9), Ended: at(12), Lines: 2, Attempts: 2, Tag: "task.test.good"},
+			{Node: deploy, Status: StatusError, Started: at(13), Ended: at(14), Attempts: 1, Tag: "task.deploy.error"},
+		},
+	}
+	v := BuildRunView(events)
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("bad view\nwant %+v\ngot  %+v", want, v)
+	}
+	if d := v.Nodes[2].Duration(at(20)); d != 3*time.Second {
+		t.Error("bad duration of the last attempt", d)
+	}
+
+	// part way through the run is still running
+	v = BuildRunView(events[:7])
+	if v.Status != StatusRunning || !v.Ended.IsZero() || v.Nodes[2].Status != StatusRunning {
+		t.Error("run and node should be running", v)
+	}
+	if d := v.Nodes[2].Duration(at(10)); d != 4*time.Second {
+		t.Error("bad duration of the running node", d)
+	}
+}
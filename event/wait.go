@@ -0,0 +1,56 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/floeit/floe/config"
+)
+
+// goodCounter counts the distinct nodes of a run that have published good events
+type goodCounter struct {
+	sync.Mutex
+	n     int
+	nodes map[config.NodeRef]bool
+	done  chan struct{}
+}
+
+func (g *goodCounter) Notify(e Event) {
+	if !e.Good || e.SourceNode.ID == "" {
+		return
+	}
+	g.Lock()
+	defer g.Unlock()
+	if g.nodes[e.SourceNode] {
+		return
+	}
+	g.nodes[e.SourceNode] = true
+	if len(g.nodes) == g.n {
+		close(g.done)
+	}
+}
+
+// WaitForGoodCount blocks until good events from n distinct source nodes of the run have
+// been published, counting those already in the history of the run, or until ctx is done
+// when it returns the error of ctx.
+func (q *Queue) WaitForGoodCount(ctx context.Context, ref RunRef, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	g := &goodCounter{
+		n:     n,
+		nodes: map[config.NodeRef]bool{},
+		done:  make(chan struct{}),
+	}
+	// replaying the whole history then the live events counts each event once
+	if err := q.SubscribeRun(ref, 0, g, WithMaxConcurrent(1)); err != nil {
+		return err
+	}
+	defer q.Unregister(g)
+	select {
+	case <-g.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
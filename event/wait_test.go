@@ -0,0 +1,62 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/floeit/floe/config"
+)
+
+func TestWaitForGoodCount(t *testing.T) {
+	q := NewQueue()
+	ref := testRef(1)
+	node := func(id string) config.NodeRef {
+		return config.NodeRef{Class: "task", ID: id}
+	}
+	// already seen, a repeat of the same node and a bad event do not add to the count
+	q.Publish(Event{RunRef: ref, SourceNode: node("test1"), Tag: "task.test1.good", Good: true})
+	q.Publish(Event{RunRef: ref, SourceNode: node("test1"), Tag: "task.test1.good", Good: true})
+	q.Publish(Event{RunRef: ref, SourceNode: node("test2"), Tag: "task.test2.bad"})
+
+	done := make(chan error)
+	go func() {
+		done <- q.WaitForGoodCount(context.Background(), ref, 2)
+	}()
+	select {
+	case err := <-done:
+		t.Fatal("wait should not complete with one good node", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	q.Publish(Event{RunRef: testRef(2), SourceNode: node("test2"), Tag: "task.test2.good", Good: true})
+	q.Publish(Event{RunRef: ref, SourceNode: node("test2"), Tag: "task.test2.good", Good: true})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait did not complete")
+	}
+
+	// all from the history
+	if err := q.WaitForGoodCount(context.Background(), ref, 2); err != nil {
+		t.Error(err)
+	}
+	if len(q.observers) != 0 {
+		t.Error("waits should unregister", len(q.observers))
+	}
+}
+
+func TestWaitForGoodCountTimeout(t *testing.T) {
+	q := NewQueue()
+	ref := testRef(1)
+	q.Publish(Event{RunRef: ref, SourceNode: config.NodeRef{Class: "task", ID: "a"}, Tag: "task.a.good", Good: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.WaitForGoodCount(ctx, ref, 2); err != context.DeadlineExceeded {
+		t.Error("expected the deadline to be exceeded", err)
+	}
+}
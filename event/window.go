@@ -0,0 +1,57 @@
+package event
+
+import (
+	"fmt"
+	"time"
+
+	nt "github.com/floeit/floe/config/nodetype"
+)
+
+// TagObserverExpired is published when an observer registered with RegisterFor is
+// unregistered at the end of its window
+const TagObserverExpired = "sys.observer.expired"
+
+// RegisterFor registers o, as RegisterWith does, for the duration d only, timed by the
+// queue clock, after which it is unregistered and a TagObserverExpired event published.
+// It is for temporary observers, such as those used to diagnose a problem, that would
+// otherwise be left registered. Unregistering o before then cancels the expiry.
+func (q *Queue) RegisterFor(o Observer, d time.Duration, opts ...RegisterOption) error {
+	r := newRegistration(o, opts)
+	q.Lock()
+	if err := q.add(r); err != nil {
+		q.Unlock()
+		return err
+	}
+	q.Unlock()
+	q.getClock().AfterFunc(d, func() {
+		if !q.removeRegistration(r) {
+			return
+		}
+		q.Publish(Event{
+			Tag: TagObserverExpired,
+			Opts: nt.Opts{
+				"observer": fmt.Sprintf("%T", o),
+				"window":   d.String(),
+			},
+		})
+	})
+	return nil
+}
+
+// removeRegistration removes r from the observers, returning false if it had already gone
+func (q *Queue) removeRegistration(r *registration) bool {
+	q.Lock()
+	defer q.Unlock()
+	// a new slice as publishers may be ranging over the current one
+	obs := make([]*registration, 0, len(q.observers))
+	found := false
+	for _, or := range q.observers {
+		if or == r {
+			found = true
+			continue
+		}
+		obs = append(obs, or)
+	}
+	q.observers = obs
+	return found
+}
@@ -0,0 +1,45 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegisterFor(t *testing.T) {
+	clk := NewFakeClock()
+	q := NewQueue(WithClock(clk))
+	expired := make(chanObs, 10)
+	q.Subscribe(expired, TagObserverExpired)
+
+	c := make(chanObs, 10)
+	if err := q.RegisterFor(c, time.Minute, WithTags("task.a.good")); err != nil {
+		t.Fatal(err)
+	}
+	q.Publish(Event{Tag: "task.a.good"})
+	next(t, c)
+
+	clk.Advance(59 * time.Second)
+	q.Publish(Event{Tag: "task.a.good"})
+	next(t, c)
+
+	clk.Advance(time.Second)
+	e := next(t, expired)
+	if e.Opts["observer"] != "event.chanObs" || e.Opts["window"] != "1m0s" {
+		t.Error("bad expiry event", e.Opts)
+	}
+	q.Publish(Event{Tag: "task.a.good"})
+	select {
+	case <-c:
+		t.Error("observer sent events after its window")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// unregistering first cancels the expiry
+	d := make(chanObs, 10)
+	q.RegisterFor(d, time.Minute)
+	q.Unregister(d)
+	clk.Advance(time.Minute)
+	if len(expired) != 0 {
+		t.Error("unregistered observer should not expire")
+	}
+}
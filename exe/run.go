@@ -36,8 +36,21 @@ func RunOutput(log logger, env []string, wd, cmd string, args ...string) ([]stri
 	return output, status
 }
 
-// Run executes the command in a bash process
+// Run executes the command in a bash process, sending both its stdout and stderr to out
 func Run(log logger, out chan string, env []string, wd, cmd string, args ...string) int {
+	return RunStreams(log, out, out, env, wd, cmd, args...)
+}
+
+// RunStreams executes the command in a bash process, sending the lines it writes to stdout
+// to out and those it writes to stderr to errOut, which can be the same channel. Both are
+// closed once the command is done.
+func RunStreams(log logger, out, errOut chan string, env []string, wd, cmd string, args ...string) int {
+	closeAll := func() {
+		close(out)
+		if errOut != out {
+			close(errOut)
+		}
+	}
 
 	log.Info("Exec Cmd:", cmd, "Args:", args)
 
@@ -45,6 +58,7 @@ func Run(log logger, out chan string, env []string, wd, cmd string, args ...stri
 		// make sure working directory is in place
 		if err := os.MkdirAll(wd, 0700); err != nil {
 			log.Error(err)
+			closeAll()
 			return 1
 		}
 	}
@@ -62,23 +76,20 @@ func Run(log logger, out chan string, env []string, wd, cmd string, args ...stri
 	out <- cmd + " " + strings.Join(args, " ")
 	out <- ""
 
-	// safely aggregate both to a single reader
+	// safely aggregate both to a single reader, unless they are wanted apart
 	pr, pw := io.Pipe()
 	eCmd.Stdout = pw
 	eCmd.Stderr = pw
-
-	// start scanning from the common pipe
 	scanDone := make(chan bool)
-	go func() {
-		scanner := bufio.NewScanner(pr)
-		for scanner.Scan() {
-			out <- scanner.Text()
-		}
-		if e := scanner.Err(); e != nil {
-			out <- "scanning output failed with: " + e.Error()
-		}
-		scanDone <- true
-	}()
+	go scan(pr, out, scanDone)
+
+	epw := pw
+	if errOut != out {
+		var epr *io.PipeReader
+		epr, epw = io.Pipe()
+		eCmd.Stderr = epw
+		go scan(epr, errOut, scanDone)
+	}
 
 	log.Debug("Exec starting")
 	err := eCmd.Start()
@@ -86,22 +97,26 @@ func Run(log logger, out chan string, env []string, wd, cmd string, args ...stri
 		log.Error("start failed", err)
 		out <- err.Error()
 		out <- ""
-		close(out)
+		closeAll()
 		return 1
 	}
 
 	log.Debug("Exec waiting")
 	err = eCmd.Wait()
 
-	// close the writer pipe
+	// close the writer pipes
 	e := pw.Close()
 	if e != nil {
 		panic("not sure how this particular close could error" + err.Error())
 	}
+	if epw != pw {
+		epw.Close()
+		<-scanDone
+	}
 
 	// wait to be sure scanner is fully complete
 	<-scanDone
-	close(out)
+	closeAll()
 
 	log.Debug("exec cmd complete")
 
@@ -121,3 +136,15 @@ func Run(log logger, out chan string, env []string, wd, cmd string, args ...stri
 	log.Info("Executing command succeeded")
 	return 0
 }
+
+// scan sends each line read from r to out, signalling done when r is closed
+func scan(r io.Reader, out chan string, done chan bool) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		out <- scanner.Text()
+	}
+	if e := scanner.Err(); e != nil {
+		out <- "scanning output failed with: " + e.Error()
+	}
+	done <- true
+}
@@ -39,6 +39,29 @@ func TestRun(t *testing.T) {
 	}
 }
 
+func TestRunStreams(t *testing.T) {
+	t.Parallel()
+
+	out, errOut := make(chan string, 100), make(chan string, 100)
+	status := RunStreams(&tLog{t: t}, out, errOut, nil, "", "bash", "-c", `echo "to out"; echo "to err" >&2`)
+	if status != 0 {
+		t.Error("echo failed", status)
+	}
+	var outs, errs []string
+	for l := range out {
+		outs = append(outs, l)
+	}
+	for l := range errOut {
+		errs = append(errs, l)
+	}
+	if len(outs) != 3 || outs[2] != "to out" {
+		t.Error("bad stdout", outs)
+	}
+	if len(errs) != 1 || errs[0] != "to err" {
+		t.Error("bad stderr", errs)
+	}
+}
+
 func TestRunOutput(t *testing.T) {
 	t.Parallel()
 
@@ -1,6 +1,7 @@
 package hub
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -65,6 +66,11 @@ func (h *Hub) ExecutePending(pend Pend) (bool, error) {
 		Good:       true,           // all trigger events that start a run must be good
 	})
 
+	// fail the run if it is still going once the flow timeout has passed
+	if flow.Timeout > 0 {
+		h.queue.RunTimeout(pend.Ref, time.Duration(flow.Timeout)*time.Second)
+	}
+
 	return true, nil
 }
 
@@ -96,7 +102,14 @@ func (h *Hub) activate(pend *Pend, hostID string) error {
 // dispatchToActive takes event e that is already destined for this host
 // and routes it to the specific active flow as detailed in e
 func (h *Hub) dispatchToActive(e event.Event) {
-	// We dont care about these system events
+	if e.Tag == event.TagRunTimeout {
+		if _, r := h.runs.findActiveRun(e.RunRef.Run); r != nil {
+			log.Debugf("<%s> - dispatch - run timed out (ending flow as bad)", e.RunRef)
+			h.endRun(r, e.SourceNode, e.Opts, false)
+		}
+		return
+	}
+	// We dont care about these other system events
 	if e.IsSystem() {
 		return
 	}
@@ -157,6 +170,12 @@ func (h *Hub) dispatchToActive(e event.Event) {
 		return
 	}
 
+	// a good event starting several nodes splits the flow into branches, so give it a
+	// correlation ID for the branches to carry to the merge they rejoin at
+	if e.Good && len(matched) > 1 {
+		e.SetCorrelation(fmt.Sprintf("%s-ev%d", e.RunRef.Run, e.ID))
+	}
+
 	// Fire all matching nodes
 	for _, n := range matched {
 		log.Debugf("<%s> - dispatch - '%s' matched %s", e.RunRef, e.Tag, n.Ref)
@@ -216,7 +235,7 @@ func mergeEnvOpts(opts nt.Opts, env []string) {
 // exeNode defines the interface for a executable node
 type exeNode interface {
 	refNode
-	Execute(*nt.Workspace, nt.Opts, chan string) (int, nt.Opts, error)
+	Execute(*nt.Workspace, nt.Opts, chan nt.Output) (int, nt.Opts, error)
 	Status(status int) (string, bool)
 }
 
@@ -225,24 +244,27 @@ type exeNode interface {
 func (h *Hub) executeNode(run *Run, node exeNode, e event.Event, ws *nt.Workspace) {
 	runRef := run.Ref
 	nodeID := node.NodeRef().ID
-	log.Debugf("<%s> - exec node - event tag: %s, node: %s", runRef, e.Tag, nodeID)
+	execID := h.queue.NewExecID()
+	attempt := run.nextAttempt(nodeID)
+	log.Debugf("<%s> - exec node - event tag: %s, node: %s, exec: %d, attempt: %d", runRef, e.Tag, nodeID, execID, attempt)
 
 	// capture and emit all the node updates
-	updates := make(chan string)
+	updates := make(chan nt.Output)
 	go func() {
+		n := 0
 		for update := range updates {
-			h.queue.Publish(event.Event{
-				RunRef:     runRef,
-				SourceNode: node.NodeRef(),
-				Tag:        tagNodeUpdate,
-				Opts: nt.Opts{
-					"update": update,
-				},
-				Good: true,
-			})
+			n++
+			stream := event.StreamStdout
+			if update.Stream != "" {
+				stream = update.Stream
+			}
+			ue := event.UpdateEvent(runRef, node.NodeRef(), stream, n, update.Line)
+			ue.ExecID = execID
+			ue.Attempt = attempt
+			h.queue.Publish(ue)
 
 			// explicitly update any exec nodes with the ongoing execute
-			h.runs.updateExecNode(run, nodeID, zt, zt, false, update)
+			h.runs.updateExecNode(run, nodeID, zt, zt, false, update.Line)
 		}
 	}()
 
@@ -251,6 +273,8 @@ func (h *Hub) executeNode(run *Run, node exeNode, e event.Event, ws *nt.Workspac
 		RunRef:     runRef,
 		SourceNode: node.NodeRef(),
 		Tag:        tagNodeStart,
+		ExecID:     execID,
+		Attempt:    attempt,
 	})
 
 	// set the start time for the node
@@ -262,13 +286,18 @@ func (h *Hub) executeNode(run *Run, node exeNode, e event.Event, ws *nt.Workspac
 	if err != nil {
 		log.Errorf("<%s> - exec node (%s) - execute produced error: %v", runRef, node.NodeRef(), err)
 		// publish the fact an internal node error happened
-		h.publishIfActive(event.Event{
+		ee := event.Event{
 			RunRef:     runRef,
 			SourceNode: node.NodeRef(),
-			Tag:        node.GetTag("error"),
+			Tag:        node.GetTag(config.SubTagError),
 			Opts:       outOpts,
 			Good:       false,
-		})
+			Outcome:    config.SubTagError,
+			ExecID:     execID,
+			Attempt:    attempt,
+		}
+		correlate(e, &ee)
+		h.publishIfActive(ee)
 		h.runs.updateExecNode(run, nodeID, zt, time.Now(), false, err.Error())
 		return
 	}
@@ -278,12 +307,16 @@ func (h *Hub) executeNode(run *Run, node exeNode, e event.Event, ws *nt.Workspac
 		RunRef:     runRef,
 		SourceNode: node.NodeRef(),
 		Opts:       outOpts,
+		ExecID:     execID,
+		Attempt:    attempt,
 	}
 
 	// construct the event tag
 	tagbit, good := node.Status(status)
 	ne.Tag = node.GetTag(tagbit)
 	ne.Good = good
+	ne.Outcome = tagbit
+	correlate(e, &ne)
 
 	h.runs.updateExecNode(run, nodeID, zt, time.Now(), good, "")
 
@@ -291,6 +324,14 @@ func (h *Hub) executeNode(run *Run, node exeNode, e event.Event, ws *nt.Workspac
 	h.publishIfActive(ne)
 }
 
+// correlate carries any correlation ID of the event in that a node executed for on to
+// the event out that it ended with, so all events on a branch of the flow share it.
+func correlate(in event.Event, out *event.Event) {
+	if id, ok := in.Correlation(); ok {
+		out.SetCorrelation(id)
+	}
+}
+
 // setFormData sets the opts form data on the active run on this host. If the form is incomplete it
 // emits a system event which no other node should be listening for so will effectively
 // pause the run, until later when any inbound data triggers the event for this data node.
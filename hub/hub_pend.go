@@ -88,13 +88,36 @@ func (h *Hub) distributeAllPending() error {
 	return nil
 }
 
+// inboundType returns the trigger type of an inbound event tag
+func inboundType(tag string) (string, bool) {
+	if !strings.HasPrefix(tag, inboundPrefix+".") {
+		return "", false
+	}
+	return tag[len(inboundPrefix)+1:], true
+}
+
+// MatchTriggers returns the trigger nodes of flow that the inbound event e would fire,
+// using the same matching as when e is published, so triggers can be tested before the
+// flow config is saved. Only the first of them would start a run.
+func MatchTriggers(e event.Event, flow config.Flow) []config.NodeRef {
+	triggerType, ok := inboundType(e.Tag)
+	if !ok {
+		return nil
+	}
+	// an event for a specific flow matches no other
+	if ref := e.RunRef.FlowRef; ref.NonZero() && (ref.ID != flow.ID || ref.Ver != flow.Ver) {
+		return nil
+	}
+	return flow.MatchTriggers(triggerType, e.Opts)
+}
+
 // pendFlowFromTrigger uses the subscription fired event e to put any flows on the pending queue
 // for any matching triggers.
 func (h *Hub) pendFlowFromTrigger(e event.Event) error {
-	if !strings.HasPrefix(e.Tag, inboundPrefix) {
+	triggerType, ok := inboundType(e.Tag)
+	if !ok {
 		return fmt.Errorf("event %s dispatched to triggers does not have inbound tag prefix", e.Tag)
 	}
-	triggerType := e.Tag[len(inboundPrefix)+1:]
 
 	log.Debugf("attempt to trigger type:<%s> (specified flow: %v)", triggerType, e.RunRef.FlowRef)
 
@@ -121,7 +144,7 @@ func (h *Hub) pendFlowFromTrigger(e event.Event) error {
 		opts := nt.MergeOpts(ff.Matched.Opts, e.Opts)
 
 		// add the flow to the pending list making note of the node and opts that triggered it
-		ref, err := h.addToPending(ff.Flow, h.hostID, ff.Matched.Ref, opts)
+		ref, err := h.addToPending(ff.Flow, h.hostID, triggerType, e.RunRef.Parent, ff.Matched.Ref, opts)
 		if err != nil {
 			return err
 		}
@@ -131,8 +154,8 @@ func (h *Hub) pendFlowFromTrigger(e event.Event) error {
 }
 
 // addToPending adds a flow to the list of pending runs and publishes appropriate system state change event.
-func (h *Hub) addToPending(flow *config.Flow, hostID string, trig config.NodeRef, opts nt.Opts) (event.RunRef, error) {
-	ref, err := h.runs.addToPending(flow, hostID, trig, opts)
+func (h *Hub) addToPending(flow *config.Flow, hostID, triggerType string, parent *event.RunRef, trig config.NodeRef, opts nt.Opts) (event.RunRef, error) {
+	ref, err := h.runs.addToPending(flow, hostID, triggerType, parent, trig, opts)
 	if err != nil {
 		return ref, err
 	}
@@ -149,6 +172,24 @@ func (h *Hub) addToPending(flow *config.Flow, hostID string, trig config.NodeRef
 	return ref, nil
 }
 
+// StartRun adds a run of the flow of ref to the pending list, to be triggered from the node
+// trig with opts, satisfying event.RunStarter so re-runs are allocated and executed here.
+func (h *Hub) StartRun(ref event.RunRef, trig config.NodeRef, opts nt.Opts) (event.RunRef, error) {
+	flow := h.config.Flow(ref.FlowRef)
+	if flow == nil {
+		return event.RunRef{}, fmt.Errorf("<%s> - no flow config to start a run of", ref)
+	}
+	if err := flow.Load(h.cachePath); err != nil {
+		return event.RunRef{}, err
+	}
+	nr, err := h.addToPending(flow, h.hostID, ref.TriggerType(), ref.Parent, trig, opts)
+	if err != nil {
+		return nr, err
+	}
+	log.Debugf("<%s> - re-run of <%s> added to pending", nr, ref)
+	return nr, nil
+}
+
 // removePend removes the pend from the pending list issuing system state change event.
 // Any error returned will be in the persisting of the pending list.
 func (h *Hub) removePend(pend Pend) error {
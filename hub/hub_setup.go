@@ -20,14 +20,14 @@ import (
 
 // some special system event tags, generated by the system internals rather than the configurable nodes
 const (
-	tagEndFlow     = "sys.end.all"       // a run has ended
-	tagNodeUpdate  = "sys.node.update"   // an executing node has had an update to its output
-	tagNodeStart   = "sys.node.start"    // an executing node has started its job
+	tagEndFlow     = event.TagEndFlow    // a run has ended
+	tagNodeUpdate  = event.TagNodeUpdate // an executing node has had an update to its output
+	tagNodeStart   = event.TagNodeStart  // an executing node has started its job
 	tagStateChange = "sys.state"         // a run has transitioned state
 	tagWaitingData = "sys.data.required" // a node in the run needs data input
 	tagGoodTrigger = "trigger.good"      // always issued when a trigger
 
-	inboundPrefix = "inbound" // the tags from any data push events
+	inboundPrefix = event.InboundPrefix // the tags from any data push events
 )
 
 var zt = time.Time{} // zero time
@@ -108,7 +108,9 @@ func New(host, tags, adminTok string, c *config.Config, storage store.Store, q *
 	// set up any timed triggers
 	h.launchTimedTriggers(storage)
 	// hub subscribes to its own queue
-	h.queue.Register(h)
+	if err := h.queue.RegisterWith(h, event.WithPersistent(), event.WithPrivileged()); err != nil {
+		log.Fatal("hub can not observe the queue", err)
+	}
 	// start checking the pending queue
 	go h.serviceLists()
 
@@ -13,7 +13,7 @@ import (
 )
 
 type task struct {
-	exec func(ws *nt.Workspace, updates chan string)
+	exec func(ws *nt.Workspace, updates chan nt.Output)
 }
 
 func (t *task) NodeRef() config.NodeRef {
@@ -28,7 +28,7 @@ func (t *task) GetTag(string) string {
 	return "tag"
 }
 
-func (t *task) Execute(ws *nt.Workspace, opts nt.Opts, updates chan string) (int, nt.Opts, error) {
+func (t *task) Execute(ws *nt.Workspace, opts nt.Opts, updates chan nt.Output) (int, nt.Opts, error) {
 	if t.exec != nil {
 		t.exec(ws, updates)
 	}
@@ -38,7 +38,7 @@ func (t *task) Execute(ws *nt.Workspace, opts nt.Opts, updates chan string) (int
 func TestExecuteNode(t *testing.T) {
 	s := store.NewMemStore()
 	h := Hub{
-		queue: &event.Queue{},
+		queue: event.NewQueue(),
 		runs:  newRunStore(s),
 	}
 	h.config.Common.WorkspaceRoot = "/foo/bar"
@@ -52,7 +52,7 @@ func TestExecuteNode(t *testing.T) {
 		},
 	}
 	didExec := false
-	exec := func(ws *nt.Workspace, updates chan string) {
+	exec := func(ws *nt.Workspace, updates chan nt.Output) {
 		didExec = true
 		if ws.BasePath != "/foo/bar/spaces/testflow/ws/h1-5" {
 			t.Errorf("base path is wrong <%s>", ws.BasePath)
@@ -142,7 +142,7 @@ func TestHubEvents(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	q := &event.Queue{}
+	q := event.NewQueue()
 
 	// make a new hub
 	New("h1", "master", "admintok", c, s, q)
@@ -220,6 +220,7 @@ func TestHubEvents(t *testing.T) {
 			counts[e.Tag] = counts[e.Tag] + 1
 			if e.Tag == "sys.end.all" {
 				done <- struct{}{}
+				return
 			}
 		}
 	}()
@@ -307,7 +308,7 @@ func TestHubData(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	q := &event.Queue{}
+	q := event.NewQueue()
 	// so we can wait for events to occur after the trigger
 	to := &testObs{
 		ch: make(chan event.Event, 2),
@@ -399,3 +400,469 @@ func TestMergeEnvOpts(t *testing.T) {
 		t.Error("expand failed", env[0])
 	}
 }
+
+var inTwoTriggers = []byte(`
+    flows:
+        - id: build-project
+          ver: 1
+          triggers:
+            - name: form
+              type: data
+              opts:
+                url: blah.blah
+            - name: form again
+              type: data
+              opts:
+                url: blah.blah
+          tasks:
+            - name: complete
+              listen: trigger.good
+              type: end
+    `)
+
+func TestSingleFireTriggers(t *testing.T) {
+	t.Parallel()
+
+	c, err := config.ParseYAML(inTwoTriggers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Hub{
+		hostID: "h1",
+		config: *c,
+		queue:  event.NewQueue(),
+		runs:   newRunStore(store.NewMemStore()),
+	}
+
+	// the event matches both trigger nodes
+	err = h.pendFlowFromTrigger(event.Event{
+		Tag:  "inbound.data",
+		Opts: nt.Opts{"url": "blah.blah"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pends := h.runs.allPends()
+	if len(pends) != 1 {
+		t.Fatal("only one run should be pending", len(pends))
+	}
+	if pends[0].TriggeredNode.ID != "form" {
+		t.Error("the first trigger should have been chosen", pends[0].TriggeredNode.ID)
+	}
+}
+
+func TestChildRunAdopted(t *testing.T) {
+	c, err := config.ParseYAML(inTwoTriggers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Hub{
+		hostID: "h1",
+		config: *c,
+		queue:  event.NewQueue(),
+		runs:   newRunStore(store.NewMemStore()),
+	}
+	parent := event.RunRef{
+		FlowRef: config.FlowRef{ID: "parent", Ver: 1},
+		Run:     event.HostedIDRef{HostID: "h1", ID: 40},
+	}
+	child := parent.Child(config.FlowRef{ID: c.Flows[0].ID, Ver: c.Flows[0].Ver})
+	err = h.pendFlowFromTrigger(event.Event{
+		RunRef: child,
+		Tag:    "inbound.data",
+		Opts:   nt.Opts{"url": "blah.blah"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pends := h.runs.allPends()
+	if len(pends) != 1 {
+		t.Fatal("child run should be pending", len(pends))
+	}
+	ref := pends[0].Ref
+	if !ref.Adopted() || ref.Run.Equal(parent.Run) || !ref.IsChildOf(parent) {
+		t.Error("child should be adopted as its own run keeping its parent", ref)
+	}
+}
+
+func TestMatchTriggers(t *testing.T) {
+	t.Parallel()
+
+	c, err := config.ParseYAML(inTwoTriggers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flow := c.Flows[0]
+
+	fix := []struct {
+		e    event.Event
+		want []string
+	}{
+		{ // matches both triggers
+			e:    event.Event{Tag: "inbound.data", Opts: nt.Opts{"url": "blah.blah"}},
+			want: []string{"form", "form-again"},
+		},
+		{ // no trigger of the type
+			e: event.Event{Tag: "inbound.timer", Opts: nt.Opts{"url": "blah.blah"}},
+		},
+		{ // not an inbound event
+			e: event.Event{Tag: "data", Opts: nt.Opts{"url": "blah.blah"}},
+		},
+		{ // for a specific flow
+			e: event.Event{
+				RunRef: event.RunRef{FlowRef: config.FlowRef{ID: "build-project", Ver: 1}},
+				Tag:    "inbound.data",
+				Opts:   nt.Opts{"url": "blah.blah"},
+			},
+			want: []string{"form", "form-again"},
+		},
+		{ // as built for webhooks
+			e:    event.NewTriggerEvent(config.FlowRef{}, "data", nt.Opts{"url": "blah.blah"}),
+			want: []string{"form", "form-again"},
+		},
+		{ // for another flow
+			e: event.Event{
+				RunRef: event.RunRef{FlowRef: config.FlowRef{ID: "build-project", Ver: 2}},
+				Tag:    "inbound.data",
+				Opts:   nt.Opts{"url": "blah.blah"},
+			},
+		},
+	}
+	for i, f := range fix {
+		got := MatchTriggers(f.e, *flow)
+		if len(got) != len(f.want) {
+			t.Errorf("%d: wanted %d matches got %v", i, len(f.want), got)
+			continue
+		}
+		for j, ref := range got {
+			if ref.ID != f.want[j] || ref.Class != "trigger" {
+				t.Errorf("%d: wrong match %d %v", i, j, ref)
+			}
+		}
+	}
+
+	// a single matching trigger
+	flow.Triggers = flow.Triggers[1:]
+	got := MatchTriggers(event.Event{Tag: "inbound.data", Opts: nt.Opts{"url": "blah.blah"}}, *flow)
+	if len(got) != 1 || got[0].ID != "form-again" {
+		t.Error("wrong single match", got)
+	}
+}
+
+type merger struct {
+	task
+}
+
+func (m *merger) NodeRef() config.NodeRef {
+	return config.NodeRef{Class: "merge", ID: "join"}
+}
+
+func (m *merger) GetTag(subTag string) string {
+	return "merge.join." + subTag
+}
+
+func (m *merger) TypeOfNode() string {
+	return "all"
+}
+
+func (m *merger) Waits() int {
+	return 2
+}
+
+func TestCorrelatedBranches(t *testing.T) {
+	h := Hub{
+		queue: event.NewQueue(),
+		runs:  newRunStore(store.NewMemStore()),
+	}
+	run := newRun(&Pend{
+		Ref: event.RunRef{
+			FlowRef: config.FlowRef{ID: "testflow", Ver: 1},
+			Run:     event.HostedIDRef{HostID: "h1", ID: 7},
+		},
+	})
+	h.runs.active = append(h.runs.active, run)
+
+	ends := make(chan event.Event, 10)
+	h.queue.Register(&hubObs{ch: ends, tag: "tag"})
+	merged := make(chan event.Event, 10)
+	h.queue.Register(&hubObs{ch: merged, tag: "merge.join.good"})
+
+	// the split event starts both branches
+	split := event.Event{RunRef: run.Ref, Tag: "task.split.good", Good: true}
+	split.SetCorrelation("split-1")
+	for i := 0; i < 2; i++ {
+		h.executeNode(run, &task{}, split, &nt.Workspace{})
+		e := waitEvtTimeout(t, ends, "branch end")
+		if id, _ := e.Correlation(); id != "split-1" {
+			t.Fatal("branch event lost the correlation", e.Opts)
+		}
+		e.Tag = []string{"task.test1.good", "task.test2.good"}[i]
+		h.mergeEvent(run, &merger{}, *e)
+	}
+
+	e := waitEvtTimeout(t, merged, "merge")
+	if id, _ := e.Correlation(); id != "split-1" {
+		t.Error("merge should carry the correlation of its branches", e.Opts)
+	}
+}
+
+var inSplit = []byte(`
+    common:
+        workspace-root: "%tmp/floe"
+
+    flows:
+        - id: split-project
+          ver: 1
+          triggers:
+            - name: push
+              type: data
+              opts:
+                url: blah.blah
+          tasks:
+            - name: test1
+              listen: trigger.good
+              type: exec
+              opts:
+                cmd: "echo test1"
+
+            - name: test2
+              listen: trigger.good
+              type: exec
+              opts:
+                cmd: "echo test2"
+
+            - name: merge-tests
+              class: merge
+              type: all
+              wait: [task.test1.good, task.test2.good]
+
+            - name: complete
+              listen: merge.merge-tests.good
+              type: end
+    `)
+
+func TestHubCorrelatesSplit(t *testing.T) {
+	t.Parallel()
+
+	c, err := config.ParseYAML(inSplit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := event.NewQueue()
+	var chans []chan event.Event
+	for _, tag := range []string{"task.test1.good", "task.test2.good", "merge.merge-tests.good"} {
+		ch := make(chan event.Event, 10)
+		q.Register(&hubObs{ch: ch, tag: tag})
+		chans = append(chans, ch)
+	}
+
+	New("h4", "master", "admintok", c, store.NewMemStore(), q)
+
+	q.Publish(event.Event{
+		Tag:  "inbound.data",
+		Opts: nt.Opts{"url": "blah.blah"},
+	})
+	ids := map[string]bool{}
+	for _, ch := range chans {
+		e := waitEvtTimeout(t, ch, "correlated event")
+		id, ok := e.Correlation()
+		if !ok {
+			t.Fatal("branch event should be correlated", e.Tag, e.Opts)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 1 {
+		t.Error("branches and their merge should share one correlation", ids)
+	}
+}
+
+// hubObs sends events with the tag to ch
+type hubObs struct {
+	ch  chan event.Event
+	tag string
+}
+
+func (o *hubObs) Notify(e event.Event) {
+	if e.Tag == o.tag {
+		o.ch <- e
+	}
+}
+
+func TestTriggerTypeCarried(t *testing.T) {
+	h := Hub{
+		queue: event.NewQueue(),
+		runs:  newRunStore(store.NewMemStore()),
+	}
+	flow := &config.Flow{ID: "testflow", Ver: 1}
+	ref, err := h.addToPending(flow, "h1", "timer", nil, config.NodeRef{Class: "trigger", ID: "nightly"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.TriggerType() != "timer" {
+		t.Fatal("adopted run should record its trigger type", ref)
+	}
+	run := newRun(&Pend{Ref: ref})
+	h.runs.active = append(h.runs.active, run)
+
+	ends := make(chan event.Event, 10)
+	h.queue.Register(&hubObs{ch: ends, tag: "tag"})
+	h.executeNode(run, &task{}, event.Event{RunRef: ref, Tag: "task.build.good", Good: true}, &nt.Workspace{})
+	e := waitEvtTimeout(t, ends, "mid run event")
+	if e.TriggerType() != "timer" {
+		t.Error("mid run event lost the trigger type", e.RunRef)
+	}
+}
+
+func TestRunTimeoutEndsRun(t *testing.T) {
+	h := Hub{
+		queue: event.NewQueue(),
+		runs:  newRunStore(store.NewMemStore()),
+	}
+	run := newRun(&Pend{
+		Ref: event.RunRef{
+			FlowRef: config.FlowRef{ID: "testflow", Ver: 1},
+			Run:     event.HostedIDRef{HostID: "h1", ID: 7},
+		},
+	})
+	h.runs.active = append(h.runs.active, run)
+	ends := make(chan event.Event, 10)
+	h.queue.Register(&hubObs{ch: ends, tag: tagEndFlow})
+
+	h.dispatchToActive(event.Event{RunRef: run.Ref, Tag: event.TagRunTimeout})
+	e := waitEvtTimeout(t, ends, "run end")
+	if e.Good {
+		t.Error("timed out run should end badly")
+	}
+	if _, r := h.runs.findActiveRun(run.Ref.Run); r != nil {
+		t.Error("timed out run should not be active")
+	}
+}
+
+func TestAttemptNumbers(t *testing.T) {
+	h := Hub{
+		queue: event.NewQueue(),
+		runs:  newRunStore(store.NewMemStore()),
+	}
+	run := newRun(&Pend{
+		Ref: event.RunRef{
+			FlowRef: config.FlowRef{ID: "testflow", Ver: 1},
+			Run:     event.HostedIDRef{HostID: "h1", ID: 7},
+		},
+	})
+	h.runs.active = append(h.runs.active, run)
+
+	starts := make(chan event.Event, 10)
+	h.queue.Register(&hubObs{ch: starts, tag: tagNodeStart})
+	ends := make(chan event.Event, 10)
+	h.queue.Register(&hubObs{ch: ends, tag: "tag"})
+
+	// the same node executed again in the run is the next attempt
+	in := event.Event{RunRef: run.Ref, Tag: "task.build.good", Good: true}
+	for attempt := 1; attempt <= 3; attempt++ {
+		h.executeNode(run, &task{}, in, &nt.Workspace{})
+		s := waitEvtTimeout(t, starts, "node start")
+		e := waitEvtTimeout(t, ends, "node end")
+		if s.Attempt != attempt || e.Attempt != attempt {
+			t.Errorf("wanted attempt %d got start: %d end: %d", attempt, s.Attempt, e.Attempt)
+		}
+		if s.ExecID != e.ExecID {
+			t.Error("events of an attempt should share the exec id", s.ExecID, e.ExecID)
+		}
+	}
+}
+
+var inRerun = []byte(`
+    common:
+        workspace-root: "%tmp/floe"
+
+    flows:
+        - id: rerun-project
+          ver: 1
+          triggers:
+            - name: push
+              type: data
+              opts:
+                url: blah.blah
+          tasks:
+            - name: build
+              listen: trigger.good
+              type: exec
+              opts:
+                cmd: "echo built"
+
+            - name: complete
+              listen: task.build.good
+              type: end
+    `)
+
+func TestRerunExecutes(t *testing.T) {
+	t.Parallel()
+
+	c, err := config.ParseYAML(inRerun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := event.NewQueue()
+	builds := make(chan event.Event, 10)
+	q.Register(&hubObs{ch: builds, tag: "task.build.good"})
+	ends := make(chan event.Event, 10)
+	q.Register(&hubObs{ch: ends, tag: tagEndFlow})
+
+	New("h3", "master", "admintok", c, store.NewMemStore(), q)
+
+	q.Publish(event.Event{
+		Tag:  "inbound.data",
+		Opts: nt.Opts{"url": "blah.blah"},
+	})
+	first := waitEvtTimeout(t, ends, "first run end").RunRef
+
+	// the hub allocates the re-run after its own runs, and executes it
+	nr, err := q.Rerun(first, nt.Opts{"branch": "release"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nr.Run.Equal(first.Run) || nr.Run.HostID != "h3" {
+		t.Error("re-run should be a new run of this host", nr)
+	}
+	if b := waitEvtTimeout(t, builds, "first run build"); !b.RunRef.Run.Equal(first.Run) {
+		t.Error("first build should be of the first run", b.RunRef)
+	}
+	if b := waitEvtTimeout(t, builds, "re-run build"); !b.RunRef.Run.Equal(nr.Run) {
+		t.Error("re-run did not execute its build", b.RunRef)
+	}
+	e := waitEvtTimeout(t, ends, "re-run end")
+	if !e.RunRef.Run.Equal(nr.Run) || !e.Good {
+		t.Error("re-run should have executed to a good end", e.RunRef, e.Good)
+	}
+}
+
+func TestUpdateStreams(t *testing.T) {
+	h := Hub{
+		queue: event.NewQueue(),
+		runs:  newRunStore(store.NewMemStore()),
+	}
+	run := newRun(&Pend{
+		Ref: event.RunRef{
+			FlowRef: config.FlowRef{ID: "testflow", Ver: 1},
+			Run:     event.HostedIDRef{HostID: "h1", ID: 7},
+		},
+	})
+	h.runs.active = append(h.runs.active, run)
+	updates := make(chan event.Event, 10)
+	h.queue.Register(&hubObs{ch: updates, tag: event.TagNodeUpdate})
+
+	h.executeNode(run, &task{exec: func(ws *nt.Workspace, out chan nt.Output) {
+		out <- nt.Output{Line: "compiling"}
+		out <- nt.Output{Stream: nt.StreamStderr, Line: "warning: unused"}
+	}}, event.Event{RunRef: run.Ref}, &nt.Workspace{})
+
+	got := map[string]string{}
+	for i := 0; i < 2; i++ {
+		e := waitEvtTimeout(t, updates, "update")
+		got[e.Opts["line"].(string)] = e.Opts["stream"].(string)
+	}
+	if got["compiling"] != event.StreamStdout || got["warning: unused"] != event.StreamStderr {
+		t.Error("updates labelled with the wrong streams", got)
+	}
+}
@@ -49,11 +49,12 @@ type data struct {
 }
 
 type exec struct {
-	Started time.Time
-	Stopped time.Time
-	Good    bool     // only valid when Status="finished"
-	Opts    nt.Opts  // opts from the exec event
-	Logs    []string // any output of the node
+	Started  time.Time
+	Stopped  time.Time
+	Good     bool     // only valid when Status="finished"
+	Opts     nt.Opts  // opts from the exec event
+	Logs     []string // any output of the node
+	Attempts int      // how many times the node has been executed in the run
 }
 
 // Run is a specific invocation of a flow
@@ -107,7 +108,12 @@ func (r *Run) updateMergeNode(nodeID, tag, typ string, waits int, opts nt.Opts)
 
 	r.MergeNodes[nodeID] = m
 
-	return m.Waits, fired, nt.MergeOpts(m.Opts, nil) // merge copies the opts to avoid mutations
+	// the waits are published so must not be changed by later merges
+	waitsDone := make(map[string]bool, len(m.Waits))
+	for k, v := range m.Waits {
+		waitsDone[k] = v
+	}
+	return waitsDone, fired, nt.MergeOpts(m.Opts, nil) // merge copies the opts to avoid mutations
 }
 
 // updateExecNode adds the output line to the log lines for the nod in this run
@@ -133,6 +139,16 @@ func (r *Run) updateExecNode(nodeID string, start, end time.Time, good bool, lin
 	r.ExecNodes[nodeID] = m
 }
 
+// nextAttempt counts another execution of the node, returning which attempt it is
+func (r *Run) nextAttempt(nodeID string) int {
+	r.Lock()
+	defer r.Unlock()
+	m := r.ExecNodes[nodeID]
+	m.Attempts++
+	r.ExecNodes[nodeID] = m
+	return m.Attempts
+}
+
 // updateDataNode adds the opts form description
 func (r *Run) updateDataNode(nodeID string, opts nt.Opts, enabled bool) {
 	r.Lock()
@@ -311,8 +327,10 @@ func (r *RunStore) end(run *Run, good bool) bool {
 	return true
 }
 
-// addToPending adds the active configs to pending list, and returns the run id
-func (r *RunStore) addToPending(flow *config.Flow, hostID string, trig config.NodeRef, opts nt.Opts) (event.RunRef, error) {
+// addToPending adds the active configs to pending list, and returns the run id. The run
+// ref records the type of trigger, and any parent run of a sub-flow, so they are carried
+// on all the events of the run.
+func (r *RunStore) addToPending(flow *config.Flow, hostID, triggerType string, parent *event.RunRef, trig config.NodeRef, opts nt.Opts) (event.RunRef, error) {
 	r.Lock()
 	defer r.Unlock()
 	r.pending.Counter++
@@ -324,6 +342,8 @@ func (r *RunStore) addToPending(flow *config.Flow, hostID string, trig config.No
 		Ref: event.RunRef{
 			FlowRef: config.FlowRef{ID: flow.ID, Ver: flow.Ver},
 			Run:     run,
+			Trigger: triggerType,
+			Parent:  parent,
 		},
 		Flow:          flow,
 		TriggeredNode: trig,
@@ -70,17 +70,12 @@ func (t *timers) register(flow config.FlowRef, nodeID string, opts nt.Opts, trig
 
 func sendTriggerEvent(q *event.Queue, flowRef config.FlowRef, nodeID, typ string, opts nt.Opts) {
 	log.Debugf("<%s> - from %s trigger <%s> added to pending", flowRef, typ, nodeID)
-	q.Publish(event.Event{
-		RunRef: event.RunRef{
-			FlowRef: flowRef,
-		},
-		Tag: "inbound." + typ,
-		SourceNode: config.NodeRef{
-			Class: "trigger",
-			ID:    nodeID,
-		},
-		Opts: opts,
-	})
+	e := event.NewTriggerEvent(flowRef, typ, opts)
+	e.SourceNode = config.NodeRef{
+		Class: "trigger",
+		ID:    nodeID,
+	}
+	q.Publish(e)
 }
 
 func startFlowTrigger(q *event.Queue, tim *timer) {
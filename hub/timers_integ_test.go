@@ -28,7 +28,7 @@ func TestRepoPoller(t *testing.T) {
 			Ver: 1,
 		},
 	}
-	q := &event.Queue{}
+	q := event.NewQueue()
 	c, err := config.ParseYAML(trigFlow)
 	if err != nil {
 		t.Fatal(err)
@@ -20,7 +20,7 @@ func (o obs) Notify(e event.Event) {
 func TestTimers(t *testing.T) {
 	t.Parallel()
 
-	q := &event.Queue{}
+	q := event.NewQueue()
 
 	// make an observer that signals a chanel
 	got := make(chan bool, 1)
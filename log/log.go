@@ -154,14 +154,30 @@ func Fatal(args ...interface{}) {
 	os.Exit(255)
 }
 
+// Log is the log package as a value, for code that is given a logger
 type Log struct{}
 
+// Info logs vals at the info level
 func (l Log) Info(vals ...interface{}) {
 	Info(vals...)
 }
+
+// Debug logs vals at the debug level
 func (l Log) Debug(vals ...interface{}) {
 	Debug(vals...)
 }
+
+// Error logs vals at the error level
 func (l Log) Error(vals ...interface{}) {
 	Error(vals...)
 }
+
+// Debugf logs the formatted args at the debug level
+func (l Log) Debugf(format string, args ...interface{}) {
+	Debugf(format, args...)
+}
+
+// Errorf logs the formatted args at the error level
+func (l Log) Errorf(format string, args ...interface{}) {
+	Errorf(format, args...)
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const defaultTail = 50 // how many events the debug endpoint returns by default
+
+// the /debug/events endpoint returns the most recent events from this hosts queue
+func hndDebugEvents(rw http.ResponseWriter, r *http.Request, ctx *context) (int, string, renderable) {
+	n := defaultTail
+	if s := r.URL.Query().Get("n"); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 0 {
+			return rBad, "n must be a positive integer", nil
+		}
+		n = i
+	}
+	return rOK, "OK", ctx.hub.Queue().Tail(n)
+}
@@ -40,11 +40,9 @@ func (d Data) PostHandler(queue *event.Queue) httprouter.Handle {
 			return
 		}
 
-		rr := event.RunRef{
-			FlowRef: o.Ref,
-		}
-
-		sourceNode := config.NodeRef{
+		// "inbound" is checked before launching a pending, and data will become the type
+		e := event.NewTriggerEvent(o.Ref, "data", o.Form.Values)
+		e.SourceNode = config.NodeRef{
 			Class: "trigger",
 			ID:    o.Form.ID,
 		}
@@ -57,19 +55,14 @@ func (d Data) PostHandler(queue *event.Queue) httprouter.Handle {
 				if err != nil {
 					log.Error("could not parse run id", err)
 				} else {
-					rr.Run.HostID = ps[0]
-					rr.Run.ID = id
+					e.RunRef.Run.HostID = ps[0]
+					e.RunRef.Run.ID = id
 				}
 			}
 		}
 
 		// add a data event - including a specific targeted Run if given
-		queue.Publish(event.Event{
-			RunRef:     rr,
-			Tag:        "inbound.data", // "inbound" is checked before launching a pending, and data will become the type
-			SourceNode: sourceNode,
-			Opts:       o.Form.Values,
-		})
+		queue.Publish(e)
 
 		jsonResp(w, http.StatusOK, "OK", nil)
 	}
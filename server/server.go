@@ -56,6 +56,9 @@ func LaunchWeb(conf Conf, rp string, hub *hub.Hub, q *event.Queue, addrChan chan
 	r.GET(rp+"/p2p/flows/:id/runs/:rid", h.mw(hndP2PRun, true)) // detailed run info from this host for this flow id and run id
 	r.GET(rp+"/p2p/config", h.mw(confHandler, true))            // return host config and what it knows about other hosts
 
+	// --- debug ---
+	r.GET(rp+"/debug/events", h.mw(hndDebugEvents, true)) // the most recent events published on this host
+
 	// --- static files for the spa ---
 	if webDev { // local development mode
 		serveFiles(r, "/static/*filepath", http.Dir("webapp"))
@@ -71,7 +74,9 @@ func LaunchWeb(conf Conf, rp string, hub *hub.Hub, q *event.Queue, addrChan chan
 
 	// ws endpoint
 	wsh := newWsHub()
-	q.Register(wsh)
+	if err := q.RegisterWith(wsh, event.WithPersistent()); err != nil {
+		log.Fatal("websocket hub can not observe the queue", err)
+	}
 	r.GET("/ws", wsh.getWsHandler(&h))
 
 	// --- CORS ---
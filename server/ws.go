@@ -16,12 +16,22 @@ import (
 
 type wsHub struct {
 	sync.RWMutex
-	cons map[*websocket.Conn]bool
+	cons map[*websocket.Conn]*wsConn
+}
+
+// wsConn is a connected client, its lock is held across encoding and writing each event
+// so concurrent Notify calls send the frames in the order the dictionary was built
+type wsConn struct {
+	sync.Mutex
+	w io.Writer
+	// enc is the dictionary encoder of clients that asked for it with encoding=dict,
+	// or nil for those sent plain json events
+	enc *event.DictEncoder
 }
 
 func newWsHub() *wsHub {
 	return &wsHub{
-		cons: map[*websocket.Conn]bool{},
+		cons: map[*websocket.Conn]*wsConn{},
 	}
 }
 
@@ -35,15 +45,31 @@ func (w *wsHub) Notify(e event.Event) {
 		return
 	}
 
-	for ws := range w.cons {
-		m, err := ws.Write(b)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if m != len(b) {
-			log.Errorf("ws write did not send full event (%d out of %d):", m, len(b))
+	for _, c := range w.cons {
+		c.send(e, b)
+	}
+}
+
+// send writes the event e, or its plain json b, to the client
+func (c *wsConn) send(e event.Event, b []byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	msg := b
+	if c.enc != nil {
+		var err error
+		if msg, err = c.enc.Encode(e); err != nil {
+			log.Error("dictionary encoding event failed:", err)
+			return
 		}
 	}
+	m, err := c.w.Write(msg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if m != len(msg) {
+		log.Errorf("ws write did not send full event (%d out of %d):", m, len(msg))
+	}
 }
 
 func (w *wsHub) add(ws *websocket.Conn) {
@@ -52,7 +78,11 @@ func (w *wsHub) add(ws *websocket.Conn) {
 
 	log.Debug("ws - adding new client")
 
-	w.cons[ws] = true
+	var enc *event.DictEncoder
+	if r := ws.Request(); r != nil && r.URL.Query().Get("encoding") == "dict" {
+		enc = event.NewDictEncoder()
+	}
+	w.cons[ws] = &wsConn{w: ws, enc: enc}
 }
 
 func (w *wsHub) remove(ws *websocket.Conn) {
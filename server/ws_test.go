@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/floeit/floe/event"
+)
+
+// frameWriter records each write as one frame, as a websocket does
+type frameWriter struct {
+	mu     sync.Mutex
+	frames [][]byte
+}
+
+func (f *frameWriter) Write(b []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frames = append(f.frames, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func TestWsConcurrentDictFrames(t *testing.T) {
+	t.Parallel()
+
+	fw := &frameWriter{}
+	w := newWsHub()
+	w.cons[&websocket.Conn{}] = &wsConn{w: fw, enc: event.NewDictEncoder()}
+
+	const n = 200
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w.Notify(event.Event{Tag: fmt.Sprintf("tag-%d", i)})
+		}(i)
+	}
+	wg.Wait()
+
+	if len(fw.frames) != n {
+		t.Fatalf("got %d frames, wanted %d", len(fw.frames), n)
+	}
+
+	// decode the frames in the order received, as the client does
+	var dict []string
+	seen := map[string]bool{}
+	for i, b := range fw.frames {
+		f := struct {
+			D []string `json:"d"`
+			T int      `json:"t"`
+		}{}
+		if err := json.Unmarshal(b, &f); err != nil {
+			t.Fatal(err)
+		}
+		dict = append(dict, f.D...)
+		if f.T < 1 || f.T > len(dict) {
+			t.Fatalf("frame %d refers to tag %d before it was in the dictionary of %d", i, f.T, len(dict))
+		}
+		tag := dict[f.T-1]
+		if seen[tag] {
+			t.Errorf("frame %d decoded a repeated tag %s", i, tag)
+		}
+		seen[tag] = true
+	}
+}